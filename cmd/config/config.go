@@ -0,0 +1,146 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/shaunmolloy/bugbox/cmd/setup"
+	"github.com/shaunmolloy/bugbox/internal/storage/config"
+)
+
+const usage = `Usage: bugbox config <command> [args]
+
+Commands:
+  get <key>              Print the value of a key
+  set <key> <value...>   Set a key
+  unset <key>            Clear a key
+  list [--json]          Print every key
+  validate [--json]      Check the config, exiting non-zero if invalid
+  path                   Print the config file path
+`
+
+// Run handles `bugbox config <command>`
+func Run(args []string) error {
+	return run(args, os.Stdout, os.Stderr)
+}
+
+func run(args []string, stdout io.Writer, stderr io.Writer) error {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return fmt.Errorf("missing command")
+	}
+
+	command, args := args[0], args[1:]
+
+	flags := flag.NewFlagSet("config "+command, flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	asJSON := flags.Bool("json", false, "print as JSON")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	args = flags.Args()
+
+	err := runCommand(command, args, *asJSON, stdout, stderr)
+	var invalid invalidError
+	if err != nil && !errors.As(err, &invalid) {
+		setup.ReportErrors(stderr, config.ValidationError{Field: "config", Message: err.Error()}, *asJSON)
+	}
+	return err
+}
+
+// invalidError is returned by validate, whose problems are already printed
+type invalidError struct {
+	err error
+}
+
+func (e invalidError) Error() string { return e.err.Error() }
+func (e invalidError) Unwrap() error { return e.err }
+
+func runCommand(command string, args []string, asJSON bool, stdout io.Writer, stderr io.Writer) error {
+	switch command {
+	case "get":
+		if len(args) != 1 {
+			return fmt.Errorf("usage: bugbox config get <key>")
+		}
+		conf, err := setup.LoadConfig()
+		if err != nil {
+			return err
+		}
+		value, err := conf.Get(args[0])
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(stdout, value)
+
+	case "set", "unset":
+		if len(args) < 1 || (command == "set" && len(args) < 2) {
+			return fmt.Errorf("usage: bugbox config %s <key>", command)
+		}
+		conf, err := setup.LoadConfig()
+		if err != nil {
+			return err
+		}
+		if command == "set" {
+			err = conf.Set(args[0], args[1:]...)
+		} else {
+			err = conf.Unset(args[0])
+		}
+		if err != nil {
+			return err
+		}
+		if err := config.SaveConfig(conf); err != nil {
+			return err
+		}
+		// Settings bugbox works around are warned about rather than refused
+		for _, e := range conf.Check() {
+			fmt.Fprintf(stderr, "warning: %s\n", e.Error())
+		}
+
+	case "list":
+		conf, err := setup.LoadConfig()
+		if err != nil {
+			return err
+		}
+		values := make(map[string]string)
+		for _, key := range config.Keys() {
+			values[key], _ = conf.Get(key)
+		}
+		if asJSON {
+			return json.NewEncoder(stdout).Encode(values)
+		}
+		for _, key := range config.Keys() {
+			fmt.Fprintf(stdout, "%s=%s\n", key, values[key])
+		}
+
+	case "validate":
+		err := config.Validate()
+		// Also report settings that don't stop bugbox running
+		if conf, loadErr := config.LoadConfig(); loadErr == nil {
+			if problems := conf.Check(); len(problems) > 0 {
+				var errs config.ValidationErrors
+				errors.As(err, &errs)
+				err = append(errs, problems...)
+			}
+		}
+		if err != nil {
+			setup.ReportErrors(stdout, err, asJSON)
+			return invalidError{err}
+		}
+		if asJSON {
+			fmt.Fprintln(stdout, `{"errors":[]}`)
+		}
+
+	case "path":
+		fmt.Fprintln(stdout, config.ConfigPath)
+
+	default:
+		fmt.Fprint(stderr, usage)
+		return fmt.Errorf("unknown command %q", command)
+	}
+
+	return nil
+}
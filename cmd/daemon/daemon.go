@@ -0,0 +1,121 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/shaunmolloy/bugbox/internal/daemon"
+	"github.com/shaunmolloy/bugbox/internal/events"
+	"github.com/shaunmolloy/bugbox/internal/fulltext"
+	"github.com/shaunmolloy/bugbox/internal/hooks"
+	"github.com/shaunmolloy/bugbox/internal/issues"
+	"github.com/shaunmolloy/bugbox/internal/issues/github"
+	"github.com/shaunmolloy/bugbox/internal/logging"
+	"github.com/shaunmolloy/bugbox/internal/notifier"
+	"github.com/shaunmolloy/bugbox/internal/scheduler"
+	"github.com/shaunmolloy/bugbox/internal/storage/config"
+	"github.com/shaunmolloy/bugbox/internal/types"
+)
+
+const usage = `Usage: bugbox daemon [command]
+
+Commands:
+  (none)    Fetch issues in the background, serving the socket API
+  status    Print the running daemon's sync status as JSON
+  refresh   Ask the running daemon to fetch now
+`
+
+// Run handles `bugbox daemon [command]`, serving on socket until ctx is cancelled
+func Run(ctx context.Context, args []string, socket string) error {
+	if len(args) == 0 {
+		return serve(ctx, socket, nil)
+	}
+
+	switch args[0] {
+	case "status":
+		client, err := daemon.Dial(socket)
+		if err != nil {
+			return fmt.Errorf("daemon is not running: %w", err)
+		}
+		snapshot, err := client.Status()
+		if err != nil {
+			return err
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(snapshot)
+
+	case "refresh":
+		client, err := daemon.Dial(socket)
+		if err != nil {
+			return fmt.Errorf("daemon is not running: %w", err)
+		}
+		return client.Refresh()
+
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+}
+
+// serve runs the scheduler headless, owning the issue store. With a
+// webhooks server, deliveries are applied as they arrive too.
+func serve(ctx context.Context, socket string, webhooks *webhooksServer) error {
+	if err := config.Validate(); err != nil {
+		return fmt.Errorf("config is incomplete, run `bugbox setup` first: %w", err)
+	}
+
+	// Stop everything if the webhooks server fails, e.g. its port is in use
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	client := &http.Client{}
+	sched := scheduler.New(client)
+	server, err := daemon.Listen(socket, daemon.NewLocal(ctx, sched.Refresh))
+	if err != nil {
+		return err
+	}
+
+	hooks.New().Start(ctx, events.Default)
+	notifier.New(&http.Client{Timeout: 30 * time.Second}).Start(ctx, events.Default)
+	StartIndexer(ctx, client)
+	sched.Start(ctx)
+	logging.Info(fmt.Sprintf("Daemon listening on %s", socket))
+	fmt.Printf("Listening on %s\n", socket)
+
+	errs := make(chan error, 1)
+	if webhooks != nil {
+		go func() {
+			err := webhooks.serve(ctx, sched.Delivered)
+			if err != nil {
+				cancel()
+			}
+			errs <- err
+		}()
+	}
+
+	err = server.Serve(ctx)
+	sched.Wait()
+	if webhooks != nil {
+		err = errors.Join(err, <-errs)
+	}
+	logging.Info("Daemon stopped")
+	return err
+}
+
+// StartIndexer keeps the search index up to date as issues are fetched
+func StartIndexer(ctx context.Context, client issues.HttpClient) {
+	indexer, err := fulltext.NewIndexer(fulltext.IndexPath, func(ctx context.Context, issue types.Issue) ([]string, error) {
+		return github.FetchComments(ctx, issue, client)
+	})
+	if err != nil {
+		logging.Error(fmt.Sprintf("Search index unavailable: %v", err))
+		return
+	}
+	indexer.Start(ctx, events.Default)
+}
@@ -0,0 +1,75 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/shaunmolloy/bugbox/internal/credentials"
+	"github.com/shaunmolloy/bugbox/internal/issues/github"
+	"github.com/shaunmolloy/bugbox/internal/logging"
+	"github.com/shaunmolloy/bugbox/internal/storage/config"
+)
+
+// RunWebhooks handles `bugbox serve-webhooks`, running the daemon while
+// applying GitHub webhook deliveries as they arrive
+func RunWebhooks(ctx context.Context, args []string, socket string) error {
+	conf, _ := config.LoadConfig()
+
+	flags := flag.NewFlagSet("serve-webhooks", flag.ContinueOnError)
+	addr := flags.String("addr", conf.Webhooks.ListenAddr(), "address to listen on for deliveries")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if conf.Webhooks.Secret.Source == "" {
+		return fmt.Errorf("no webhook secret, set webhooks.secret.source and webhooks.secret.value with `bugbox config set`")
+	}
+	store, err := credentials.New(conf.Webhooks.Secret)
+	if err != nil {
+		return err
+	}
+	secret, err := store.Get()
+	if err != nil {
+		return fmt.Errorf("reading webhook secret: %w", err)
+	}
+
+	return serve(ctx, socket, &webhooksServer{addr: *addr, secret: secret})
+}
+
+// webhooksServer receives GitHub webhook deliveries over HTTP
+type webhooksServer struct {
+	addr   string
+	secret string
+}
+
+// serve listens until ctx is cancelled, calling delivered for each org with a delivery
+func (w *webhooksServer) serve(ctx context.Context, delivered func(org string, repo string)) error {
+	listener, err := net.Listen("tcp", w.addr)
+	if err != nil {
+		return err
+	}
+
+	server := &http.Server{
+		Handler:           &github.WebhookHandler{Secret: w.secret, OnDelivery: delivered},
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	logging.Info(fmt.Sprintf("Receiving webhooks on %s", listener.Addr()))
+	fmt.Printf("Receiving webhooks on http://%s\n", listener.Addr())
+
+	if err := server.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package list
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/shaunmolloy/bugbox/internal/daemon"
+	"github.com/shaunmolloy/bugbox/internal/fulltext"
+	"github.com/shaunmolloy/bugbox/internal/fuzzy"
+	"github.com/shaunmolloy/bugbox/internal/output"
+	"github.com/shaunmolloy/bugbox/internal/query"
+	"github.com/shaunmolloy/bugbox/internal/sorting"
+	"github.com/shaunmolloy/bugbox/internal/storage/config"
+	"github.com/shaunmolloy/bugbox/internal/types"
+	"github.com/shaunmolloy/bugbox/internal/utils"
+)
+
+// Run handles `bugbox list [flags] [query]`, printing issues from source,
+// a running daemon or the issue store
+func Run(args []string, source daemon.Backend) error {
+	var filter config.IssueFilter
+	var age time.Duration
+	order := sorting.Default
+
+	flags := flag.NewFlagSet("list", flag.ContinueOnError)
+	flags.Func("org", "only issues in org, repeatable or comma-separated", func(value string) error {
+		filter.Orgs = append(filter.Orgs, config.SplitList([]string{value})...)
+		return nil
+	})
+	flags.Func("repo", "only issues in repo or org/repo, repeatable or comma-separated", func(value string) error {
+		filter.Repos = append(filter.Repos, config.SplitList([]string{value})...)
+		return nil
+	})
+	flags.Func("label", "only issues with any of these labels, repeatable or comma-separated", func(value string) error {
+		filter.Labels = append(filter.Labels, config.SplitList([]string{value})...)
+		return nil
+	})
+	flags.Func("age", "only issues created within this long, e.g. 12h, 7d or 2w", func(value string) (err error) {
+		age, err = utils.ParseAge(value)
+		return err
+	})
+	flags.Func("sort", "sort by "+fieldList()+", optionally -asc or -desc, e.g. updated or title-desc", func(value string) (err error) {
+		order, err = sorting.Parse(value)
+		return err
+	})
+	unread := flags.Bool("unread", false, "only unread issues")
+	search := flags.String("query", "", "only issues matching a query, e.g. 'label:bug is:unread', also read from arguments")
+	saved := flags.String("search", "", "only issues matching the saved search with this name")
+	fuzzySearch := flags.Bool("fuzzy", false, "read the query as a fuzzy search, ranking issues by relevance")
+	format := flags.String("format", output.Table, "output format: "+strings.Join(output.Formats, ", "))
+	tmpl := flags.String("template", "", "Go template run for each issue, e.g. '{{.URL}}'")
+	limit := flags.Int("limit", 0, "print at most this many issues")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	issuesMap, err := source.Issues()
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("loading issues: %w", err)
+	}
+
+	// Arguments are part of the query, e.g. `bugbox list org:acme is:unread`
+	// A saved search narrows any query given too
+	if *saved != "" {
+		conf, _ := config.LoadConfig()
+		savedSearch, ok := conf.Search(*saved)
+		if !ok {
+			return fmt.Errorf("no saved search named %q", *saved)
+		}
+		if strings.TrimSpace(savedSearch.Query) != "" {
+			*search = "(" + savedSearch.Query + ") " + *search
+		}
+	}
+
+	text := strings.Join(append([]string{*search}, flags.Args()...), " ")
+	var q query.Query
+	if !*fuzzySearch {
+		q, err = query.Parse(text)
+		if err != nil {
+			return fmt.Errorf("invalid query: %w", err)
+		}
+
+		// Words and phrases also search bodies and comments
+		if len(q.Text()) > 0 {
+			docs, err := fulltext.Load(fulltext.IndexPath)
+			if err != nil {
+				return fmt.Errorf("loading search index: %w", err)
+			}
+			q = q.WithDocuments(docs)
+		}
+	}
+
+	now := time.Now()
+
+	var issues []types.Issue
+	for _, issue := range config.FlattenIssues(issuesMap) {
+		switch {
+		case !filter.Match(issue),
+			*unread && issue.Read,
+			age > 0 && now.Sub(issue.CreatedAt) > age,
+			!q.Match(issue, now):
+			continue
+		}
+		issues = append(issues, issue)
+	}
+
+	sorting.Sort(issues, order)
+
+	// Best match first, in sort order for ties
+	if *fuzzySearch {
+		results := fuzzy.NewIndex(issues).Search(text)
+		issues = make([]types.Issue, len(results))
+		for i, result := range results {
+			issues[i] = result.Issue
+		}
+	}
+
+	if *limit > 0 && len(issues) > *limit {
+		issues = issues[:*limit]
+	}
+
+	return output.Write(os.Stdout, *format, *tmpl, issues)
+}
+
+// fieldList lists the sort fields for help text
+func fieldList() string {
+	fields := make([]string, len(sorting.Fields))
+	for i, field := range sorting.Fields {
+		fields[i] = string(field)
+	}
+	return strings.Join(fields, ", ")
+}
@@ -0,0 +1,90 @@
+package login
+
+import (
+	"cmp"
+	"flag"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/shaunmolloy/bugbox/cmd/setup"
+	"github.com/shaunmolloy/bugbox/internal/auth"
+	"github.com/shaunmolloy/bugbox/internal/credentials"
+	"github.com/shaunmolloy/bugbox/internal/issues/github"
+	"github.com/shaunmolloy/bugbox/internal/logging"
+	"github.com/shaunmolloy/bugbox/internal/storage/config"
+)
+
+// Run handles `bugbox login`, authorising bugbox with the OAuth device flow
+func Run(args []string) error {
+	conf, err := setup.LoadConfig()
+	if err != nil {
+		return err
+	}
+
+	var scopes string
+	flags := flag.NewFlagSet("login", flag.ContinueOnError)
+	flags.StringVar(&conf.OAuth.ClientID, "client-id", cmp.Or(conf.OAuth.ClientID, auth.DefaultClientID), "OAuth app client ID")
+	flags.StringVar(&conf.OAuth.BaseURL, "base-url", conf.OAuth.BaseURL, "GitHub URL for the device flow endpoints")
+	flags.StringVar(&scopes, "scopes", "repo,read:org", "comma-separated scopes to request")
+	tokenSource := flags.String("token-source", "", "where to store the token: keyring or file")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if conf.OAuth.ClientID == "" {
+		// Only release builds have a default OAuth app
+		return fmt.Errorf("no OAuth client ID, pass --client-id or set oauth.client_id")
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	flow := &auth.DeviceFlow{
+		ClientID: conf.OAuth.ClientID,
+		Scopes:   config.SplitList([]string{scopes}),
+		BaseURL:  conf.OAuth.BaseURL,
+		Client:   client,
+	}
+
+	code, err := flow.RequestCode()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("First copy your one-time code: %s\n", code.UserCode)
+	fmt.Printf("Then open %s in your browser and enter it\n\n", code.VerificationURI)
+	fmt.Println("Waiting for authorisation...")
+
+	token, err := flow.PollToken(code)
+	if err != nil {
+		return err
+	}
+
+	// Device flow tokens need a store bugbox can write refreshed tokens to
+	ref := config.TokenRef{Source: *tokenSource}
+	if ref.Source == "" {
+		ref = conf.Token
+	}
+	if ref.Source != credentials.SourceKeyring && ref.Source != credentials.SourceFile {
+		ref = config.TokenRef{Source: setup.DefaultTokenSource()}
+	}
+
+	info, err := github.VerifyToken(token.AccessToken, client)
+	if err != nil {
+		return err
+	}
+
+	previous := conf.Token
+	if err := setup.StoreToken(&conf, ref, token.String()); err != nil {
+		return err
+	}
+	if err := config.SaveConfig(conf); err != nil {
+		return err
+	}
+	setup.RemoveOldToken(previous, conf.Token)
+
+	logging.Info(fmt.Sprintf("Logged in as %s", info.User.Login))
+	fmt.Printf("\nLogged in as %s, token stored in %s\n", info.User.Login, conf.Token.Source)
+	if len(conf.Orgs) == 0 {
+		fmt.Println("Run `bugbox setup` to choose which orgs to track")
+	}
+	return nil
+}
@@ -1,30 +1,147 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
+	configcmd "github.com/shaunmolloy/bugbox/cmd/config"
+	daemoncmd "github.com/shaunmolloy/bugbox/cmd/daemon"
+	"github.com/shaunmolloy/bugbox/cmd/list"
+	"github.com/shaunmolloy/bugbox/cmd/login"
 	"github.com/shaunmolloy/bugbox/cmd/setup"
+	"github.com/shaunmolloy/bugbox/internal/daemon"
+	"github.com/shaunmolloy/bugbox/internal/events"
+	"github.com/shaunmolloy/bugbox/internal/fulltext"
+	"github.com/shaunmolloy/bugbox/internal/hooks"
 	"github.com/shaunmolloy/bugbox/internal/logging"
+	"github.com/shaunmolloy/bugbox/internal/notifier"
 	"github.com/shaunmolloy/bugbox/internal/scheduler"
+	"github.com/shaunmolloy/bugbox/internal/storage/config"
+	"github.com/shaunmolloy/bugbox/internal/storage/paths"
+	"github.com/shaunmolloy/bugbox/internal/storage/state"
 	"github.com/shaunmolloy/bugbox/internal/tui"
 )
 
 func main() {
+	configFile := flag.String("config", "", "path to config.json")
+	flag.Parse()
+
+	p, err := paths.Resolve(*configFile)
+	if err != nil {
+		log.Printf("Error resolving paths: %v\n", err)
+		os.Exit(1)
+	}
+
+	logging.LogPath = p.LogFile()
+	if err := paths.Move(p.LegacyLogFile(), logging.LogPath); err != nil {
+		log.Printf("Error moving log file: %v\n", err)
+	}
 	if err := logging.SetupLogger(); err != nil {
 		log.Printf("Error setting up logger: %v\n", err)
 		os.Exit(1)
 	}
 	logging.Info("BugBox started")
 
-	if err := setup.Setup(); err != nil {
-		logging.Error(fmt.Sprintf("Setup failed: %v\n", err))
-		os.Exit(1)
+	fulltext.IndexPath = p.IndexFile()
+	state.StatePath = p.StateFile()
+
+	if err := config.Init(p); err != nil {
+		logging.Error(fmt.Sprintf("Error initialising config: %v\n", err))
+	}
+
+	switch flag.Arg(0) {
+	case "config":
+		if err := configcmd.Run(flag.Args()[1:]); err != nil {
+			logging.Error(fmt.Sprintf("Config failed: %v\n", err))
+			os.Exit(1)
+		}
+		return
+	case "daemon":
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		if err := daemoncmd.Run(ctx, flag.Args()[1:], p.SocketFile()); err != nil {
+			fmt.Fprintf(os.Stderr, "Daemon failed: %v\n", err)
+			logging.Error(fmt.Sprintf("Daemon failed: %v\n", err))
+			os.Exit(1)
+		}
+		return
+	case "serve-webhooks":
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		if err := daemoncmd.RunWebhooks(ctx, flag.Args()[1:], p.SocketFile()); err != nil {
+			fmt.Fprintf(os.Stderr, "Serving webhooks failed: %v\n", err)
+			logging.Error(fmt.Sprintf("Serving webhooks failed: %v\n", err))
+			os.Exit(1)
+		}
+		return
+	case "list":
+		// Read through a running daemon, as the TUI does, falling back to the store
+		var source daemon.Backend = daemon.NewLocal(context.Background(), nil)
+		if client, err := daemon.Dial(p.SocketFile()); err == nil {
+			source = client
+		}
+		if err := list.Run(flag.Args()[1:], source); err != nil {
+			fmt.Fprintf(os.Stderr, "List failed: %v\n", err)
+			logging.Error(fmt.Sprintf("List failed: %v\n", err))
+			os.Exit(1)
+		}
+		return
+	case "login":
+		if err := login.Run(flag.Args()[1:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Login failed: %v\n", err)
+			logging.Error(fmt.Sprintf("Login failed: %v\n", err))
+			os.Exit(1)
+		}
+		return
+	case "setup":
+		launch, err := setup.Run(flag.Args()[1:])
+		if err != nil {
+			logging.Error(fmt.Sprintf("Setup failed: %v\n", err))
+			os.Exit(1)
+		}
+		if !launch {
+			return
+		}
+	default:
+		if err := setup.Ensure(); err != nil {
+			logging.Error(fmt.Sprintf("Setup failed: %v\n", err))
+			os.Exit(1)
+		}
+	}
+
+	// Stop on quit or SIGINT/SIGTERM, letting in-flight saves finish
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Share a running daemon's fetcher, rather than polling separately
+	if client, err := daemon.Dial(p.SocketFile()); err == nil {
+		logging.Info("Attached to daemon")
+		tui.Source = client
+		if err := tui.Start(ctx); err != nil {
+			os.Exit(1)
+		}
+		return
 	}
 
 	client := &http.Client{}
-	scheduler.FetchIssues(client)
-	tui.Start()
+	hooks.New().Start(ctx, events.Default)
+	notifier.New(&http.Client{Timeout: 30 * time.Second}).Start(ctx, events.Default)
+	daemoncmd.StartIndexer(ctx, client)
+
+	sched := scheduler.New(client)
+	sched.Start(ctx)
+	tui.Source = daemon.NewLocal(ctx, sched.Refresh)
+
+	tui.Start(ctx)
+
+	stop()
+	sched.Wait()
+	logging.Info("BugBox stopped")
 }
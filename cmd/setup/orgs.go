@@ -0,0 +1,155 @@
+package setup
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/rivo/tview"
+	"github.com/shaunmolloy/bugbox/internal/credentials"
+	"github.com/shaunmolloy/bugbox/internal/issues"
+	"github.com/shaunmolloy/bugbox/internal/issues/github"
+	"github.com/shaunmolloy/bugbox/internal/storage/config"
+)
+
+// verifyToken checks token against the API
+func verifyToken(token string, client issues.HttpClient) (github.TokenInfo, error) {
+	return github.VerifyToken(token, client)
+}
+
+// candidateToken returns the token to verify before ref is saved: token when
+// one was entered, otherwise the one already in ref's store
+func candidateToken(conf config.Config, ref config.TokenRef, token string) (string, error) {
+	if token != "" {
+		return token, nil
+	}
+	conf.Token = ref
+	conf.GitHubToken = ""
+	return credentials.GitHubToken(conf)
+}
+
+// describeToken summarises who a token belongs to, its scopes and expiry
+func describeToken(info github.TokenInfo) string {
+	lines := []string{fmt.Sprintf("Authenticated as %s", info.User.Login)}
+
+	if len(info.Scopes) > 0 {
+		lines = append(lines, fmt.Sprintf("Scopes: %s", strings.Join(info.Scopes, ", ")))
+		if !slices.Contains(info.Scopes, "repo") {
+			lines = append(lines, "Warning: without the repo scope, issues in private repos won't be listed")
+		}
+	} else {
+		lines = append(lines, "Scopes: not reported (fine-grained token)")
+	}
+
+	if info.ExpiresAt.IsZero() {
+		lines = append(lines, "Expires: never")
+	} else {
+		days := int(time.Until(info.ExpiresAt).Hours() / 24)
+		lines = append(lines, fmt.Sprintf("Expires: %s (in %d days)", info.ExpiresAt.Format("2006-01-02"), days))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// selectOrgs shows the user's orgs as a checklist, returning the chosen orgs
+func selectOrgs(orgs []github.Org, selected []string, info github.TokenInfo) ([]string, error) {
+	app := tview.NewApplication()
+	chosen := make(map[string]bool)
+	for _, org := range selected {
+		chosen[org] = true
+	}
+
+	form := tview.NewForm()
+	form.SetBorder(true).SetTitle("Select orgs to track")
+
+	listed := make(map[string]bool)
+	for _, org := range orgs {
+		listed[org.Login] = true
+		label := org.Login
+		if org.SSORequired {
+			label += " [red](SSO authorisation required)"
+		}
+		form.AddCheckbox(label, chosen[org.Login], func(login string) func(bool) {
+			return func(checked bool) { chosen[login] = checked }
+		}(org.Login))
+	}
+
+	// Keep orgs in config the token's user isn't a member of, e.g. public orgs
+	var others []string
+	for _, org := range selected {
+		if !listed[org] {
+			others = append(others, org)
+		}
+	}
+	other := strings.Join(others, " ")
+	form.AddInputField("Other orgs (space-separated)", other, 40, nil, func(text string) {
+		other = text
+	})
+
+	cancelled := false
+	form.AddButton("Save", func() { app.Stop() })
+	form.AddButton("Cancel", func() {
+		cancelled = true
+		app.Stop()
+	})
+	form.SetCancelFunc(func() {
+		cancelled = true
+		app.Stop()
+	})
+
+	summary := tview.NewTextView().SetText(describeToken(info) + ssoHint(orgs))
+	summary.SetBorder(true).SetTitle("Token")
+
+	layout := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(summary, strings.Count(summary.GetText(false), "\n")+3, 0, false).
+		AddItem(form, 0, 1, true)
+
+	if err := app.SetRoot(layout, true).Run(); err != nil {
+		return nil, err
+	}
+	if cancelled {
+		return selected, nil
+	}
+
+	var result []string
+	for _, org := range orgs {
+		if chosen[org.Login] {
+			result = append(result, org.Login)
+		}
+	}
+	for _, org := range config.SplitList([]string{other}) {
+		if !slices.Contains(result, org) {
+			result = append(result, org)
+		}
+	}
+	return result, nil
+}
+
+// ssoHint lists where to authorise the token for SSO enforced orgs
+func ssoHint(orgs []github.Org) string {
+	var lines []string
+	for _, org := range orgs {
+		if org.SSORequired && org.SSOURL != "" {
+			lines = append(lines, fmt.Sprintf("Authorise %s: %s", org.Login, org.SSOURL))
+		}
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return "\n" + strings.Join(lines, "\n")
+}
+
+// checkOrgs reports configured orgs the token can't read because of SSO
+func checkOrgs(orgs []string, token string, client issues.HttpClient) config.ValidationErrors {
+	var errs config.ValidationErrors
+	for _, org := range orgs {
+		if required, url := github.CheckSSO(org, token, client); required {
+			errs = append(errs, config.ValidationError{
+				Field:   "orgs",
+				Message: strings.TrimSpace(fmt.Sprintf("%s requires SSO authorisation for this token %s", org, url)),
+			})
+		}
+	}
+	return errs
+}
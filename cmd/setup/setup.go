@@ -2,36 +2,251 @@ package setup
 
 import (
 	"bufio"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
+	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/shaunmolloy/bugbox/internal/credentials"
+	"github.com/shaunmolloy/bugbox/internal/issues/github"
 	"github.com/shaunmolloy/bugbox/internal/logging"
 	"github.com/shaunmolloy/bugbox/internal/storage/config"
+	"golang.org/x/term"
 )
 
-func Setup() error {
-	if !isSetup() {
+// client is used to verify the token and discover orgs
+var client = &http.Client{Timeout: 30 * time.Second}
+
+// Options are the values `bugbox setup` accepts as flags
+type Options struct {
+	Token       string
+	TokenSource string
+	TokenValue  string
+	Orgs        []string
+	JSON        bool
+	SkipVerify  bool
+}
+
+// interactive returns true when no values were passed as flags
+func (o Options) interactive() bool {
+	return o.Token == "" && o.TokenSource == "" && o.TokenValue == "" && len(o.Orgs) == 0
+}
+
+// Run handles `bugbox setup [flags]`. launch reports whether the TUI should
+// start afterwards, which is only the case for interactive setup.
+func Run(args []string) (launch bool, err error) {
+	var opts Options
+
+	flags := flag.NewFlagSet("setup", flag.ContinueOnError)
+	flags.StringVar(&opts.Token, "token", "", "GitHub token to store")
+	flags.StringVar(&opts.TokenSource, "token-source", "", "where to store the token: keyring, file, env or command")
+	flags.StringVar(&opts.TokenValue, "token-value", "", "env var, command, keyring account or file path for the token source")
+	flags.Func("org", "GitHub org to track, repeatable or comma-separated", func(value string) error {
+		opts.Orgs = append(opts.Orgs, config.SplitList([]string{value})...)
 		return nil
+	})
+	flags.BoolVar(&opts.JSON, "json", false, "print validation errors as JSON")
+	flags.BoolVar(&opts.SkipVerify, "skip-verify", false, "don't check the token and orgs against the GitHub API")
+
+	if err := flags.Parse(args); err != nil {
+		return false, err
 	}
 
+	if !opts.interactive() {
+		return false, NonInteractive(opts)
+	}
+
+	if !IsTerminal() {
+		err := errors.New("stdin is not a terminal, pass --token and --org to set up non-interactively")
+		ReportErrors(os.Stderr, err, opts.JSON)
+		return false, err
+	}
+
+	return true, Interactive()
+}
+
+// Ensure runs setup on startup when the config is invalid. Without a terminal
+// to prompt on, it reports the validation errors instead.
+func Ensure() error {
+	err := config.Validate()
+	if err == nil {
+		return nil
+	}
+
+	if !IsTerminal() {
+		ReportErrors(os.Stderr, err, false)
+		return err
+	}
+
+	return Interactive()
+}
+
+// Interactive prompts for each config value on stdin
+func Interactive() error {
 	clearTerminal()
 
 	logging.Info("Starting setup")
 
-	conf, err := config.LoadConfig()
+	conf, err := LoadConfig()
+	if err != nil {
+		ReportErrors(os.Stderr, config.ValidationError{Field: "config", Message: err.Error()}, false)
+		return err
+	}
+
+	previous := conf.Token
+	ref, token, err := handleGitHubToken(conf)
+	if err != nil {
+		logging.Error(fmt.Sprintf("Error storing token: %v\n", err))
+		return err
+	}
+
+	// Check the token before it replaces one that works
+	accessToken, err := candidateToken(conf, ref, token)
+	if err != nil {
+		logging.Error(fmt.Sprintf("Error reading token: %v\n", err))
+		fmt.Printf("\nError: %v\n", err)
+		return err
+	}
+	info, err := verifyToken(accessToken, client)
 	if err != nil {
-		conf = config.Config{} // fallback to default
+		logging.Error(fmt.Sprintf("Error verifying token: %v\n", err))
+		fmt.Printf("\nError: %v\n", err)
+		return err
+	}
+	fmt.Printf("\n%s\n", describeToken(info))
+
+	if err := StoreToken(&conf, ref, token); err != nil {
+		logging.Error(fmt.Sprintf("Error storing token: %v\n", err))
+		return err
+	}
+
+	if err := handleGitHubOrgs(&conf, accessToken, info); err != nil {
+		return err
+	}
+
+	return save(conf, previous)
+}
+
+// NonInteractive applies opts to the config without prompting
+func NonInteractive(opts Options) error {
+	logging.Info("Starting non-interactive setup")
+
+	conf, err := LoadConfig()
+	if err != nil {
+		ReportErrors(os.Stderr, config.ValidationError{Field: "config", Message: err.Error()}, opts.JSON)
+		return err
+	}
+
+	previous := conf.Token
+	ref, token := conf.Token, ""
+	changeToken := opts.TokenSource != "" || opts.TokenValue != "" || opts.Token != ""
+	if changeToken {
+		ref = config.TokenRef{
+			Source: cmp.Or(opts.TokenSource, conf.Token.Source, DefaultTokenSource()),
+			Value:  opts.TokenValue,
+		}
+		if ref.Value == "" && ref.Source == conf.Token.Source {
+			ref.Value = conf.Token.Value
+		}
+		if ref, err = prepareToken(ref, opts.Token); err != nil {
+			ReportErrors(os.Stderr, config.ValidationError{Field: "token", Message: err.Error()}, opts.JSON)
+			return err
+		}
+		token = opts.Token
+	}
+
+	if len(opts.Orgs) > 0 {
+		conf.Orgs = opts.Orgs
+	}
+
+	// Check the token before it replaces one that works
+	if !opts.SkipVerify {
+		accessToken, err := candidateToken(conf, ref, token)
+		if err == nil {
+			var info github.TokenInfo
+			if info, err = verifyToken(accessToken, client); err == nil {
+				logging.Info(fmt.Sprintf("Token verified for %s", info.User.Login))
+			}
+		}
+		if err != nil {
+			ReportErrors(os.Stderr, config.ValidationError{Field: "token", Message: err.Error()}, opts.JSON)
+			return err
+		}
+
+		if errs := checkOrgs(conf.Orgs, accessToken, client); len(errs) > 0 {
+			ReportErrors(os.Stderr, errs, opts.JSON)
+			return errs
+		}
+	}
+
+	if changeToken {
+		if err := StoreToken(&conf, ref, token); err != nil {
+			ReportErrors(os.Stderr, config.ValidationError{Field: "token", Message: err.Error()}, opts.JSON)
+			return err
+		}
+	}
+
+	if err := save(conf, previous); err != nil {
+		ReportErrors(os.Stderr, err, opts.JSON)
+		return err
+	}
+	return nil
+}
+
+// LoadConfig reads the config, treating a missing file as empty so it can be
+// created. Any other error is returned, so a malformed file isn't overwritten.
+func LoadConfig() (config.Config, error) {
+	conf, err := config.LoadConfig()
+	if err != nil && !os.IsNotExist(err) {
+		return conf, fmt.Errorf("reading %s: %w", config.ConfigPath, err)
 	}
+	return conf, nil
+}
 
-	handleGitHubToken(&conf)
-	handleGitHubOrgs(&conf)
+// ReportErrors prints err, one validation error per line or as JSON
+func ReportErrors(w io.Writer, err error, asJSON bool) {
+	var errs config.ValidationErrors
+	var single config.ValidationError
+	switch {
+	case errors.As(err, &errs):
+	case errors.As(err, &single):
+		errs = config.ValidationErrors{single}
+	default:
+		errs = config.ValidationErrors{{Field: "setup", Message: err.Error()}}
+	}
+
+	if asJSON {
+		encoder := json.NewEncoder(w)
+		encoder.Encode(map[string]config.ValidationErrors{"errors": errs})
+		return
+	}
+
+	for _, e := range errs {
+		fmt.Fprintf(w, "error: %s\n", e.Error())
+	}
+}
+
+// IsTerminal returns true when stdin is an interactive terminal
+func IsTerminal() bool {
+	return term.IsTerminal(int(os.Stdin.Fd()))
+}
 
+// save writes conf, then removes the token from the store it moved from now
+// nothing points at it
+func save(conf config.Config, previous config.TokenRef) error {
 	logging.Info("Saving config...")
 	if err := config.SaveConfig(conf); err != nil {
 		logging.Error(fmt.Sprintf("Error saving config: %v\n", err))
 		return err
 	}
+	RemoveOldToken(previous, conf.Token)
 
 	if err := config.Validate(); err != nil {
 		logging.Error(fmt.Sprintf("Config format is invalid: %v\n", err))
@@ -42,30 +257,142 @@ func Setup() error {
 	return nil
 }
 
-func isSetup() bool {
-	if len(os.Args) > 1 && os.Args[1] == "setup" {
-		return true
+func clearTerminal() {
+	fmt.Print("\033[H\033[2J")
+}
+
+// handleGitHubToken prompts for where to keep the token, and the token when
+// bugbox stores it. Nothing is written until it's verified.
+func handleGitHubToken(conf config.Config) (config.TokenRef, string, error) {
+	current, _ := credentials.GitHubToken(conf)
+
+	source := cmp.Or(conf.Token.Source, DefaultTokenSource())
+	fmt.Printf("\nStore token in (keyring, file, env, command) [%s]: ", source)
+	source = cmp.Or(parseInput(), source)
+
+	ref := config.TokenRef{Source: source}
+	if source == conf.Token.Source {
+		ref.Value = conf.Token.Value
 	}
-	if err := config.Validate(); err != nil {
-		return true
+
+	var token string
+	switch source {
+	case credentials.SourceEnv:
+		fmt.Printf("\nEnter the environment variable holding the token [%s]: ", cmp.Or(ref.Value, credentials.DefaultEnvVar))
+		ref.Value = cmp.Or(parseInput(), ref.Value)
+	case credentials.SourceCommand:
+		fmt.Printf("\nEnter the command that prints the token [%s]: ", cmp.Or(ref.Value, "gh auth token"))
+		ref.Value = cmp.Or(parseInput(), ref.Value, "gh auth token")
+	default:
+		fmt.Printf("\nEnter your GitHub personal access token [%s]: ", credentials.Mask(current))
+		token = parseInput()
+		if token == "" && !credentials.SameStore(ref, conf.Token) {
+			// Move the current token to the new store
+			token = current
+		}
 	}
-	return false
+
+	ref, err := prepareToken(ref, token)
+	return ref, token, err
 }
 
-func clearTerminal() {
-	fmt.Print("\033[H\033[2J")
+// prepareToken checks token can be kept where ref points, returning ref with
+// the default file path filled in. Nothing is written.
+func prepareToken(ref config.TokenRef, token string) (config.TokenRef, error) {
+	if ref.Source == credentials.SourceFile && ref.Value == "" {
+		ref.Value = filepath.Join(filepath.Dir(config.ConfigPath), "token.enc")
+	}
+
+	store, err := credentials.New(ref)
+	if err != nil {
+		return ref, err
+	}
+
+	switch ref.Source {
+	case credentials.SourceEnv, credentials.SourceCommand:
+		if token != "" {
+			return ref, fmt.Errorf("%s token source is read-only, set the token there instead", ref.Source)
+		}
+	default:
+		// Keep an already stored token when only the reference changes
+		if token == "" {
+			if _, err := store.Get(); err != nil {
+				return ref, fmt.Errorf("no token entered")
+			}
+		}
+	}
+	return ref, nil
 }
 
-func handleGitHubToken(conf *config.Config) error {
-	fmt.Printf("\nEnter your GitHub personal access token [%s]: ", conf.GitHubToken)
-	input := parseInput()
-	if input != "" {
-		conf.GitHubToken = input
+// StoreToken writes token to the store ref points at and references it from
+// conf. Read-only sources (env, command) only record the reference. Verify
+// the token first, and call RemoveOldToken once conf is saved.
+func StoreToken(conf *config.Config, ref config.TokenRef, token string) error {
+	ref, err := prepareToken(ref, token)
+	if err != nil {
+		return err
+	}
+
+	if token != "" {
+		store, err := credentials.New(ref)
+		if err != nil {
+			return err
+		}
+		if err := store.Set(token); err != nil {
+			return err
+		}
 	}
+
+	// The token now lives outside config.json
+	conf.Token = ref
+	conf.GitHubToken = ""
 	return nil
 }
 
-func handleGitHubOrgs(conf *config.Config) error {
+// RemoveOldToken removes the token from the store it moved from, so no copy
+// is left behind. It logs rather than fails, as the token is saved elsewhere.
+func RemoveOldToken(previous config.TokenRef, current config.TokenRef) {
+	if previous.Source == "" || credentials.SameStore(previous, current) {
+		return
+	}
+
+	store, err := credentials.New(previous)
+	if err == nil {
+		err = store.Delete()
+	}
+	if err != nil && !errors.Is(err, credentials.ErrReadOnly) && !os.IsNotExist(err) {
+		logging.Warn(fmt.Sprintf("Failed to remove token from old %s store: %v", previous.Source, err))
+	}
+}
+
+// DefaultTokenSource is the keyring when available, otherwise an encrypted file
+func DefaultTokenSource() string {
+	if credentials.KeyringAvailable() {
+		return credentials.SourceKeyring
+	}
+	return credentials.SourceFile
+}
+
+func handleGitHubOrgs(conf *config.Config, token string, info github.TokenInfo) error {
+	orgs, err := github.FetchOrgs(token, client)
+	if err != nil {
+		logging.Error(fmt.Sprintf("Error fetching orgs: %v\n", err))
+	}
+
+	// Fall back to typing orgs in when none could be discovered
+	if err != nil || len(orgs) == 0 {
+		return promptGitHubOrgs(conf)
+	}
+
+	selected, err := selectOrgs(orgs, conf.Orgs, info)
+	if err != nil {
+		return err
+	}
+	conf.Orgs = selected
+	return nil
+}
+
+func promptGitHubOrgs(conf *config.Config) error {
 	fmt.Printf("\nEnter GitHub org(s) (space-separated) [%s]: ", strings.Join(conf.Orgs, " "))
 	input := strings.Split(parseInput(), " ")
 	if len(input) > 0 && input[0] != "" {
@@ -74,9 +401,11 @@ func handleGitHubOrgs(conf *config.Config) error {
 	return nil
 }
 
+// stdin is shared so buffered input isn't lost between prompts
+var stdin = bufio.NewReader(os.Stdin)
+
 func parseInput() string {
-	reader := bufio.NewReader(os.Stdin)
-	value, err := reader.ReadString('\n')
+	value, err := stdin.ReadString('\n')
 	value = strings.TrimSpace(value)
 	if err != nil {
 		logging.Error(fmt.Sprintf("Error reading value: %v\n", err))
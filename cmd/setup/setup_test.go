@@ -0,0 +1,115 @@
+package setup
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/shaunmolloy/bugbox/internal/credentials"
+	"github.com/shaunmolloy/bugbox/internal/storage/config"
+)
+
+// stubTransport answers every request with status
+type stubTransport struct {
+	status int
+}
+
+func (s stubTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode: s.status,
+		Status:     http.StatusText(s.status),
+		Header:     make(http.Header),
+		Body:       io.NopCloser(strings.NewReader(`{}`)),
+		Request:    req,
+	}, nil
+}
+
+func TestNonInteractive(t *testing.T) {
+	setup := func(t *testing.T) (config.TokenRef, []byte) {
+		dir := t.TempDir()
+		config.ConfigPath = filepath.Join(dir, "config.json")
+
+		old := config.TokenRef{Source: credentials.SourceFile, Value: filepath.Join(dir, "old.enc")}
+		store, _ := credentials.New(old)
+		if err := store.Set("old-token"); err != nil {
+			t.Fatal("expected nil, got error")
+		}
+		if err := config.SaveConfig(config.Config{Orgs: []string{"example"}, Token: old}); err != nil {
+			t.Fatal("expected nil, got error")
+		}
+		saved, _ := os.ReadFile(config.ConfigPath)
+
+		// Every token is rejected
+		previous := client
+		client = &http.Client{Transport: stubTransport{status: http.StatusUnauthorized}}
+		t.Cleanup(func() { client = previous })
+		return old, saved
+	}
+
+	assertUnchanged := func(t *testing.T, old config.TokenRef, saved []byte) {
+		t.Helper()
+		store, _ := credentials.New(old)
+		if token, err := store.Get(); err != nil || token != "old-token" {
+			t.Errorf("expected the old token to be readable, got %q, %v", token, err)
+		}
+		if got, _ := os.ReadFile(config.ConfigPath); !bytes.Equal(got, saved) {
+			t.Errorf("expected config.json to be unchanged, got %s", got)
+		}
+	}
+
+	t.Run("keeps the old token when the new one fails verification", func(t *testing.T) {
+		old, saved := setup(t)
+		next := filepath.Join(filepath.Dir(old.Value), "new.enc")
+
+		err := NonInteractive(Options{Token: "bad-token", TokenSource: credentials.SourceFile, TokenValue: next})
+		if err == nil {
+			t.Fatal("expected error, got nil")
+		}
+
+		assertUnchanged(t, old, saved)
+		if _, err := os.Stat(next); !os.IsNotExist(err) {
+			t.Errorf("expected the new token not to be stored, got %v", err)
+		}
+	})
+
+	t.Run("doesn't overwrite the token in the same store before verifying", func(t *testing.T) {
+		old, saved := setup(t)
+
+		if err := NonInteractive(Options{Token: "typo", TokenSource: old.Source, TokenValue: old.Value}); err == nil {
+			t.Fatal("expected error, got nil")
+		}
+
+		assertUnchanged(t, old, saved)
+	})
+}
+
+func TestLoadConfig(t *testing.T) {
+	t.Run("returns an empty config when there's no file", func(t *testing.T) {
+		config.ConfigPath = filepath.Join(t.TempDir(), "config.json")
+
+		conf, err := LoadConfig()
+		if err != nil {
+			t.Fatal("expected nil, got error")
+		}
+		if len(conf.Orgs) != 0 {
+			t.Errorf("expected an empty config, got %+v", conf)
+		}
+	})
+
+	t.Run("doesn't save over a malformed config", func(t *testing.T) {
+		config.ConfigPath = filepath.Join(t.TempDir(), "config.json")
+		malformed := []byte(`{"orgs": ["example"],}`)
+		os.WriteFile(config.ConfigPath, malformed, 0600)
+
+		if err := NonInteractive(Options{Orgs: []string{"other"}, SkipVerify: true}); err == nil {
+			t.Fatal("expected error, got nil")
+		}
+		if got, _ := os.ReadFile(config.ConfigPath); !bytes.Equal(got, malformed) {
+			t.Errorf("expected config.json to be unchanged, got %s", got)
+		}
+	})
+}
@@ -0,0 +1,192 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/shaunmolloy/bugbox/internal/issues"
+)
+
+// DefaultBaseURL is where the device flow endpoints live
+const DefaultBaseURL = "https://github.com"
+
+// DefaultClientID is the OAuth app used when none is configured, set at build
+// time with -ldflags "-X github.com/shaunmolloy/bugbox/internal/auth.DefaultClientID=..."
+var DefaultClientID = ""
+
+// DefaultScopes are requested when logging in
+var DefaultScopes = []string{"repo", "read:org"}
+
+const deviceGrantType = "urn:ietf:params:oauth:grant-type:device_code"
+
+// DeviceCode is the code the user enters at VerificationURI
+type DeviceCode struct {
+	DeviceCode      string `json:"device_code"`
+	UserCode        string `json:"user_code"`
+	VerificationURI string `json:"verification_uri"`
+	ExpiresIn       int    `json:"expires_in"`
+	Interval        int    `json:"interval"`
+}
+
+// DeviceFlow implements GitHub's OAuth device authorization flow
+type DeviceFlow struct {
+	ClientID string
+	Scopes   []string
+	BaseURL  string
+	Client   issues.HttpClient
+	// Sleep waits between polls, swapped out in tests
+	Sleep func(time.Duration)
+	// Now is the current time, swapped out in tests
+	Now func() time.Time
+}
+
+// tokenResponse is the body of /login/oauth/access_token
+type tokenResponse struct {
+	AccessToken           string `json:"access_token"`
+	RefreshToken          string `json:"refresh_token"`
+	ExpiresIn             int    `json:"expires_in"`
+	RefreshTokenExpiresIn int    `json:"refresh_token_expires_in"`
+	Scope                 string `json:"scope"`
+	Error                 string `json:"error"`
+	ErrorDescription      string `json:"error_description"`
+	Interval              int    `json:"interval"`
+}
+
+// RequestCode starts the flow, returning the code to show the user
+func (f *DeviceFlow) RequestCode() (DeviceCode, error) {
+	if f.ClientID == "" {
+		return DeviceCode{}, fmt.Errorf("no OAuth client ID configured, set oauth.client_id")
+	}
+
+	form := url.Values{}
+	form.Set("client_id", f.ClientID)
+	form.Set("scope", strings.Join(f.Scopes, " "))
+
+	var code DeviceCode
+	if err := f.post("/login/device/code", form, &code); err != nil {
+		return DeviceCode{}, err
+	}
+	if code.DeviceCode == "" {
+		return DeviceCode{}, fmt.Errorf("no device code in response")
+	}
+	return code, nil
+}
+
+// PollToken waits for the user to authorise the code, returning the token
+func (f *DeviceFlow) PollToken(code DeviceCode) (Token, error) {
+	interval := time.Duration(max(code.Interval, 1)) * time.Second
+	deadline := f.now().Add(time.Duration(code.ExpiresIn) * time.Second)
+
+	form := url.Values{}
+	form.Set("client_id", f.ClientID)
+	form.Set("device_code", code.DeviceCode)
+	form.Set("grant_type", deviceGrantType)
+
+	for {
+		if code.ExpiresIn > 0 && f.now().After(deadline) {
+			return Token{}, fmt.Errorf("device code expired, run login again")
+		}
+
+		f.sleep(interval)
+
+		var resp tokenResponse
+		if err := f.post("/login/oauth/access_token", form, &resp); err != nil {
+			return Token{}, err
+		}
+
+		switch resp.Error {
+		case "":
+			return f.token(resp), nil
+		case "authorization_pending":
+			continue
+		case "slow_down":
+			// GitHub adds 5 seconds each time we poll too quickly
+			interval = time.Duration(max(resp.Interval, int(interval.Seconds())+5)) * time.Second
+			continue
+		case "expired_token":
+			return Token{}, fmt.Errorf("device code expired, run login again")
+		case "access_denied":
+			return Token{}, fmt.Errorf("authorisation was denied")
+		default:
+			return Token{}, fmt.Errorf("%s: %s", resp.Error, resp.ErrorDescription)
+		}
+	}
+}
+
+// Refresh exchanges a refresh token for a new token
+func (f *DeviceFlow) Refresh(refreshToken string) (Token, error) {
+	form := url.Values{}
+	form.Set("client_id", f.ClientID)
+	form.Set("grant_type", "refresh_token")
+	form.Set("refresh_token", refreshToken)
+
+	var resp tokenResponse
+	if err := f.post("/login/oauth/access_token", form, &resp); err != nil {
+		return Token{}, err
+	}
+	if resp.Error != "" {
+		return Token{}, fmt.Errorf("refreshing token: %s: %s", resp.Error, resp.ErrorDescription)
+	}
+	return f.token(resp), nil
+}
+
+func (f *DeviceFlow) token(resp tokenResponse) Token {
+	token := Token{
+		AccessToken:  resp.AccessToken,
+		RefreshToken: resp.RefreshToken,
+		Scope:        resp.Scope,
+	}
+	if resp.ExpiresIn > 0 {
+		token.ExpiresAt = f.now().Add(time.Duration(resp.ExpiresIn) * time.Second)
+	}
+	if resp.RefreshTokenExpiresIn > 0 {
+		token.RefreshExpiresAt = f.now().Add(time.Duration(resp.RefreshTokenExpiresIn) * time.Second)
+	}
+	return token
+}
+
+func (f *DeviceFlow) post(path string, form url.Values, result any) error {
+	req, err := http.NewRequest("POST", strings.TrimSuffix(f.baseURL(), "/")+path, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := f.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GitHub OAuth error: %s", resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+
+func (f *DeviceFlow) baseURL() string {
+	if f.BaseURL != "" {
+		return f.BaseURL
+	}
+	return DefaultBaseURL
+}
+
+func (f *DeviceFlow) sleep(d time.Duration) {
+	if f.Sleep != nil {
+		f.Sleep(d)
+		return
+	}
+	time.Sleep(d)
+}
+
+func (f *DeviceFlow) now() time.Time {
+	if f.Now != nil {
+		return f.Now()
+	}
+	return time.Now()
+}
@@ -0,0 +1,210 @@
+package auth
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// stubServer implements the device flow endpoints, answering pending polls
+// times before issuing a token
+func stubServer(t *testing.T, pending int, finalError string) *httptest.Server {
+	var mu sync.Mutex
+	polls := 0
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login/device/code", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.Form.Get("client_id") != "client" {
+			http.Error(w, "bad client", http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"device_code":      "device",
+			"user_code":        "ABCD-1234",
+			"verification_uri": "https://github.com/login/device",
+			"expires_in":       900,
+			"interval":         5,
+		})
+	})
+	mux.HandleFunc("/login/oauth/access_token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+
+		if r.Form.Get("grant_type") == "refresh_token" {
+			if r.Form.Get("refresh_token") != "refresh" {
+				json.NewEncoder(w).Encode(map[string]any{"error": "bad_refresh_token"})
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]any{
+				"access_token":  "refreshed",
+				"refresh_token": "refresh-2",
+				"expires_in":    28800,
+			})
+			return
+		}
+
+		if r.Form.Get("device_code") != "device" || r.Form.Get("grant_type") != deviceGrantType {
+			json.NewEncoder(w).Encode(map[string]any{"error": "incorrect_device_code"})
+			return
+		}
+
+		mu.Lock()
+		polls++
+		current := polls
+		mu.Unlock()
+
+		switch {
+		case current == 1 && pending > 1:
+			json.NewEncoder(w).Encode(map[string]any{"error": "slow_down", "interval": 10})
+		case current <= pending:
+			json.NewEncoder(w).Encode(map[string]any{"error": "authorization_pending"})
+		case finalError != "":
+			json.NewEncoder(w).Encode(map[string]any{"error": finalError})
+		default:
+			json.NewEncoder(w).Encode(map[string]any{
+				"access_token":             "access",
+				"refresh_token":            "refresh",
+				"expires_in":               28800,
+				"refresh_token_expires_in": 15811200,
+				"scope":                    "repo,read:org",
+			})
+		}
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestDeviceFlow(t *testing.T) {
+	now := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	newFlow := func(server *httptest.Server, slept *[]time.Duration) *DeviceFlow {
+		return &DeviceFlow{
+			ClientID: "client",
+			Scopes:   DefaultScopes,
+			BaseURL:  server.URL,
+			Client:   server.Client(),
+			Sleep:    func(d time.Duration) { *slept = append(*slept, d) },
+			Now:      func() time.Time { return now },
+		}
+	}
+
+	t.Run("returns token after pending polls", func(t *testing.T) {
+		var slept []time.Duration
+		flow := newFlow(stubServer(t, 2, ""), &slept)
+
+		code, err := flow.RequestCode()
+		if err != nil {
+			t.Fatalf("expected nil, got %v", err)
+		}
+		if code.UserCode != "ABCD-1234" {
+			t.Errorf("got %q, want %q", code.UserCode, "ABCD-1234")
+		}
+
+		token, err := flow.PollToken(code)
+		if err != nil {
+			t.Fatalf("expected nil, got %v", err)
+		}
+
+		if token.AccessToken != "access" || token.RefreshToken != "refresh" {
+			t.Errorf("unexpected token %+v", token)
+		}
+		if !token.ExpiresAt.Equal(now.Add(8 * time.Hour)) {
+			t.Errorf("got %v, want %v", token.ExpiresAt, now.Add(8*time.Hour))
+		}
+
+		// Polls after slow_down wait longer
+		want := []time.Duration{5 * time.Second, 10 * time.Second, 10 * time.Second}
+		if !slices.Equal(slept, want) {
+			t.Errorf("got %v, want %v", slept, want)
+		}
+	})
+
+	t.Run("returns error when access is denied", func(t *testing.T) {
+		var slept []time.Duration
+		flow := newFlow(stubServer(t, 0, "access_denied"), &slept)
+
+		code, _ := flow.RequestCode()
+		if _, err := flow.PollToken(code); err == nil {
+			t.Fatal("expected error, got nil")
+		}
+	})
+
+	t.Run("returns error without client ID", func(t *testing.T) {
+		var slept []time.Duration
+		flow := newFlow(stubServer(t, 0, ""), &slept)
+		flow.ClientID = ""
+
+		if _, err := flow.RequestCode(); err == nil {
+			t.Fatal("expected error, got nil")
+		}
+	})
+
+	t.Run("returns refreshed token", func(t *testing.T) {
+		var slept []time.Duration
+		flow := newFlow(stubServer(t, 0, ""), &slept)
+
+		token, err := flow.Refresh("refresh")
+		if err != nil {
+			t.Fatalf("expected nil, got %v", err)
+		}
+		if token.AccessToken != "refreshed" || token.RefreshToken != "refresh-2" {
+			t.Errorf("unexpected token %+v", token)
+		}
+	})
+
+	t.Run("returns error for rejected refresh token", func(t *testing.T) {
+		var slept []time.Duration
+		flow := newFlow(stubServer(t, 0, ""), &slept)
+
+		if _, err := flow.Refresh("example"); err == nil {
+			t.Fatal("expected error, got nil")
+		}
+	})
+}
+
+func TestParseToken(t *testing.T) {
+	t.Run("returns false for plain tokens", func(t *testing.T) {
+		if _, ok := ParseToken("ghp_example"); ok {
+			t.Fatal("expected false, got true")
+		}
+	})
+
+	t.Run("round trips a stored token", func(t *testing.T) {
+		want := Token{AccessToken: "access", RefreshToken: "refresh"}
+
+		got, ok := ParseToken(want.String())
+		if !ok {
+			t.Fatal("expected true, got false")
+		}
+		if got.AccessToken != want.AccessToken || got.RefreshToken != want.RefreshToken {
+			t.Errorf("got %+v, want %+v", got, want)
+		}
+	})
+}
+
+func TestExpired(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		token    Token
+		expected bool
+	}{
+		{Token{}, false},
+		{Token{ExpiresAt: now.Add(time.Hour)}, false},
+		{Token{ExpiresAt: now.Add(time.Minute)}, true},
+		{Token{ExpiresAt: now.Add(-time.Hour)}, true},
+	}
+
+	for _, test := range tests {
+		result := test.token.Expired(now)
+		if result != test.expected {
+			t.Errorf("expected %v, got %v for %v", test.expected, result, test.token.ExpiresAt)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package auth
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// expiryMargin refreshes tokens slightly before they expire
+const expiryMargin = 5 * time.Minute
+
+// Token is an OAuth token from the device flow, stored as JSON in a credential store
+type Token struct {
+	AccessToken      string    `json:"access_token"`
+	RefreshToken     string    `json:"refresh_token,omitempty"`
+	ExpiresAt        time.Time `json:"expires_at,omitempty"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at,omitempty"`
+	Scope            string    `json:"scope,omitempty"`
+}
+
+// ParseToken reads a stored secret as an OAuth token. ok is false for plain
+// tokens, e.g. personal access tokens.
+func ParseToken(secret string) (token Token, ok bool) {
+	if !strings.HasPrefix(strings.TrimSpace(secret), "{") {
+		return Token{}, false
+	}
+	if err := json.Unmarshal([]byte(secret), &token); err != nil || token.AccessToken == "" {
+		return Token{}, false
+	}
+	return token, true
+}
+
+// String encodes the token for storage
+func (t Token) String() string {
+	data, _ := json.Marshal(t)
+	return string(data)
+}
+
+// Expired returns true when the access token has, or is about to, expire
+func (t Token) Expired(now time.Time) bool {
+	return !t.ExpiresAt.IsZero() && now.Add(expiryMargin).After(t.ExpiresAt)
+}
+
+// CanRefresh returns true when the refresh token is still usable
+func (t Token) CanRefresh(now time.Time) bool {
+	if t.RefreshToken == "" {
+		return false
+	}
+	return t.RefreshExpiresAt.IsZero() || now.Before(t.RefreshExpiresAt)
+}
@@ -0,0 +1,146 @@
+package credentials
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shaunmolloy/bugbox/internal/auth"
+	"github.com/shaunmolloy/bugbox/internal/issues"
+	"github.com/shaunmolloy/bugbox/internal/storage/config"
+)
+
+const (
+	SourceEnv     = "env"
+	SourceCommand = "command"
+	SourceKeyring = "keyring"
+	SourceFile    = "file"
+)
+
+// DefaultEnvVar is read when an env token source doesn't name a variable
+const DefaultEnvVar = "BUGBOX_GITHUB_TOKEN"
+
+// httpClient refreshes expired OAuth tokens
+var httpClient issues.HttpClient = &http.Client{Timeout: 30 * time.Second}
+
+var mu sync.Mutex
+
+// ErrReadOnly is returned when writing to a source bugbox can't write to
+var ErrReadOnly = errors.New("token source is read-only")
+
+// Store reads and writes a single secret
+type Store interface {
+	Get() (string, error)
+	Set(secret string) error
+	Delete() error
+}
+
+// New returns the store a token reference points at
+func New(ref config.TokenRef) (Store, error) {
+	ref = resolve(ref)
+	switch ref.Source {
+	case SourceEnv:
+		return &envStore{name: ref.Value}, nil
+	case SourceCommand:
+		if ref.Value == "" {
+			return nil, fmt.Errorf("command token source needs a command")
+		}
+		return &commandStore{command: ref.Value}, nil
+	case SourceKeyring:
+		return &keyringStore{account: ref.Value}, nil
+	case SourceFile:
+		if ref.Value == "" {
+			return nil, fmt.Errorf("file token source needs a path")
+		}
+		return &fileStore{path: ref.Value}, nil
+	default:
+		return nil, fmt.Errorf("unknown token source %q", ref.Source)
+	}
+}
+
+// SameStore returns true if a and b point at the same secret
+func SameStore(a config.TokenRef, b config.TokenRef) bool {
+	return resolve(a) == resolve(b)
+}
+
+// resolve fills in a reference's default value
+func resolve(ref config.TokenRef) config.TokenRef {
+	switch ref.Source {
+	case SourceEnv:
+		ref.Value = cmp.Or(ref.Value, DefaultEnvVar)
+	case SourceKeyring:
+		ref.Value = cmp.Or(ref.Value, "github")
+	case SourceFile:
+		if ref.Value != "" {
+			ref.Value = filepath.Clean(ref.Value)
+		}
+	}
+	return ref
+}
+
+// GitHubToken resolves the token for a config, falling back to a legacy plaintext token
+func GitHubToken(conf config.Config) (string, error) {
+	if conf.Token.Source == "" {
+		if conf.GitHubToken == "" {
+			return "", fmt.Errorf("no token configured")
+		}
+		return conf.GitHubToken, nil
+	}
+
+	// Serialise lookups so concurrent fetches don't refresh the same token twice
+	mu.Lock()
+	defer mu.Unlock()
+
+	store, err := New(conf.Token)
+	if err != nil {
+		return "", err
+	}
+
+	secret, err := store.Get()
+	if err != nil {
+		return "", fmt.Errorf("reading token from %s: %w", conf.Token.Source, err)
+	}
+
+	// Tokens from `bugbox login` are stored as JSON and may need refreshing
+	token, ok := auth.ParseToken(secret)
+	if !ok {
+		return secret, nil
+	}
+	if !token.Expired(time.Now()) {
+		return token.AccessToken, nil
+	}
+	if !token.CanRefresh(time.Now()) {
+		return "", fmt.Errorf("token has expired, run bugbox login")
+	}
+
+	flow := &auth.DeviceFlow{
+		ClientID: cmp.Or(conf.OAuth.ClientID, auth.DefaultClientID),
+		BaseURL:  conf.OAuth.BaseURL,
+		Client:   httpClient,
+	}
+	token, err = flow.Refresh(token.RefreshToken)
+	if err != nil {
+		return "", err
+	}
+
+	if err := store.Set(token.String()); err != nil {
+		return "", fmt.Errorf("saving refreshed token: %w", err)
+	}
+	return token.AccessToken, nil
+}
+
+// Mask hides all but the last 4 characters of a secret
+func Mask(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	if len(secret) <= 4 {
+		return strings.Repeat("*", len(secret))
+	}
+	return strings.Repeat("*", 4) + secret[len(secret)-4:]
+}
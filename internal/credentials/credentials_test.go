@@ -0,0 +1,303 @@
+package credentials
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shaunmolloy/bugbox/internal/auth"
+	"github.com/shaunmolloy/bugbox/internal/storage/config"
+)
+
+func TestGitHubToken(t *testing.T) {
+	t.Run("returns legacy plaintext token", func(t *testing.T) {
+		conf := config.Config{GitHubToken: "example"}
+
+		got, err := GitHubToken(conf)
+		if err != nil {
+			t.Fatal("expected nil, got error")
+		}
+		if got != "example" {
+			t.Errorf("got %q, want %q", got, "example")
+		}
+	})
+
+	t.Run("returns error when no token configured", func(t *testing.T) {
+		if _, err := GitHubToken(config.Config{}); err == nil {
+			t.Fatal("expected error, got nil")
+		}
+	})
+
+	t.Run("returns token from env source", func(t *testing.T) {
+		t.Setenv("EXAMPLE_TOKEN", "from-env")
+		conf := config.Config{Token: config.TokenRef{Source: SourceEnv, Value: "EXAMPLE_TOKEN"}}
+
+		got, err := GitHubToken(conf)
+		if err != nil {
+			t.Fatal("expected nil, got error")
+		}
+		if got != "from-env" {
+			t.Errorf("got %q, want %q", got, "from-env")
+		}
+	})
+
+	t.Run("returns error for unset env source", func(t *testing.T) {
+		t.Setenv("EXAMPLE_TOKEN", "")
+		conf := config.Config{Token: config.TokenRef{Source: SourceEnv, Value: "EXAMPLE_TOKEN"}}
+
+		if _, err := GitHubToken(conf); err == nil {
+			t.Fatal("expected error, got nil")
+		}
+	})
+
+	t.Run("returns error for unknown source", func(t *testing.T) {
+		conf := config.Config{Token: config.TokenRef{Source: "example"}}
+
+		if _, err := GitHubToken(conf); err == nil {
+			t.Fatal("expected error, got nil")
+		}
+	})
+}
+
+func TestGitHubTokenRefresh(t *testing.T) {
+	t.Setenv(PassphraseEnvVar, "example")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.URL.Path != "/login/oauth/access_token" || r.Form.Get("refresh_token") != "refresh" {
+			json.NewEncoder(w).Encode(map[string]any{"error": "bad_refresh_token"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"access_token":  "refreshed",
+			"refresh_token": "refresh-2",
+			"expires_in":    28800,
+		})
+	}))
+	defer server.Close()
+
+	httpClient = server.Client()
+	defer func() { httpClient = &http.Client{} }()
+
+	newConf := func(t *testing.T, token auth.Token) config.Config {
+		ref := config.TokenRef{Source: SourceFile, Value: filepath.Join(t.TempDir(), "token.enc")}
+		store, _ := New(ref)
+		if err := store.Set(token.String()); err != nil {
+			t.Fatal("expected nil, got error")
+		}
+		return config.Config{Token: ref, OAuth: config.OAuthConfig{ClientID: "client", BaseURL: server.URL}}
+	}
+
+	t.Run("returns access token when not expired", func(t *testing.T) {
+		conf := newConf(t, auth.Token{AccessToken: "access", ExpiresAt: time.Now().Add(time.Hour)})
+
+		got, err := GitHubToken(conf)
+		if err != nil {
+			t.Fatalf("expected nil, got %v", err)
+		}
+		if got != "access" {
+			t.Errorf("got %q, want %q", got, "access")
+		}
+	})
+
+	t.Run("refreshes and stores expired token", func(t *testing.T) {
+		conf := newConf(t, auth.Token{AccessToken: "access", RefreshToken: "refresh", ExpiresAt: time.Now().Add(-time.Hour)})
+
+		got, err := GitHubToken(conf)
+		if err != nil {
+			t.Fatalf("expected nil, got %v", err)
+		}
+		if got != "refreshed" {
+			t.Errorf("got %q, want %q", got, "refreshed")
+		}
+
+		store, _ := New(conf.Token)
+		secret, _ := store.Get()
+		if token, _ := auth.ParseToken(secret); token.RefreshToken != "refresh-2" {
+			t.Errorf("got %q, want %q", token.RefreshToken, "refresh-2")
+		}
+	})
+
+	t.Run("returns error for expired token without refresh token", func(t *testing.T) {
+		conf := newConf(t, auth.Token{AccessToken: "access", ExpiresAt: time.Now().Add(-time.Hour)})
+
+		if _, err := GitHubToken(conf); err == nil {
+			t.Fatal("expected error, got nil")
+		}
+	})
+}
+
+func TestCommandStore(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires sh")
+	}
+
+	t.Run("returns trimmed command output", func(t *testing.T) {
+		store, _ := New(config.TokenRef{Source: SourceCommand, Value: "echo from-command"})
+
+		got, err := store.Get()
+		if err != nil {
+			t.Fatal("expected nil, got error")
+		}
+		if got != "from-command" {
+			t.Errorf("got %q, want %q", got, "from-command")
+		}
+	})
+
+	t.Run("returns error for read-only set", func(t *testing.T) {
+		store, _ := New(config.TokenRef{Source: SourceCommand, Value: "true"})
+
+		if err := store.Set("example"); err != ErrReadOnly {
+			t.Errorf("got %v, want %v", err, ErrReadOnly)
+		}
+	})
+}
+
+func TestKeyringStore(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("requires secret-tool")
+	}
+
+	t.Run("looks up the token with secret-tool", func(t *testing.T) {
+		var gotArgs []string
+		execCommand = func(name string, args ...string) *exec.Cmd {
+			gotArgs = append([]string{name}, args...)
+			return exec.Command("echo", "from-keyring")
+		}
+		defer func() { execCommand = exec.Command }()
+
+		store, _ := New(config.TokenRef{Source: SourceKeyring})
+
+		got, err := store.Get()
+		if err != nil {
+			t.Fatal("expected nil, got error")
+		}
+		if got != "from-keyring" {
+			t.Errorf("got %q, want %q", got, "from-keyring")
+		}
+		if len(gotArgs) != 6 || gotArgs[0] != "secret-tool" || gotArgs[5] != "github" {
+			t.Errorf("unexpected command %v", gotArgs)
+		}
+	})
+}
+
+func TestKeyringStoreSecurity(t *testing.T) {
+	t.Run("passes the secret to security on stdin", func(t *testing.T) {
+		stdin := filepath.Join(t.TempDir(), "stdin")
+		var gotArgs []string
+		goos = "darwin"
+		execCommand = func(name string, args ...string) *exec.Cmd {
+			gotArgs = append([]string{name}, args...)
+			return exec.Command("sh", "-c", `cat > "$0"`, stdin)
+		}
+		defer func() { execCommand, goos = exec.Command, runtime.GOOS }()
+
+		store, _ := New(config.TokenRef{Source: SourceKeyring})
+		if err := store.Set("secret"); err != nil {
+			t.Fatalf("expected nil, got error: %v", err)
+		}
+
+		if slices.Contains(gotArgs, "secret") || gotArgs[len(gotArgs)-1] != "-w" {
+			t.Errorf("expected the secret off the command line, got %v", gotArgs)
+		}
+		if data, _ := os.ReadFile(stdin); !strings.HasPrefix(string(data), "secret\n") {
+			t.Errorf("got stdin %q, want the secret", data)
+		}
+	})
+}
+
+func TestSameStore(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b config.TokenRef
+		want bool
+	}{
+		{"returns true for the default keyring account", config.TokenRef{Source: SourceKeyring}, config.TokenRef{Source: SourceKeyring, Value: "github"}, true},
+		{"returns true for the same file", config.TokenRef{Source: SourceFile, Value: "/tmp/token.enc"}, config.TokenRef{Source: SourceFile, Value: "/tmp/./token.enc"}, true},
+		{"returns false for different sources", config.TokenRef{Source: SourceKeyring}, config.TokenRef{Source: SourceFile, Value: "/tmp/token.enc"}, false},
+		{"returns false for different accounts", config.TokenRef{Source: SourceKeyring}, config.TokenRef{Source: SourceKeyring, Value: "work"}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := SameStore(test.a, test.b); got != test.want {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestFileStore(t *testing.T) {
+	t.Run("round trips an encrypted token", func(t *testing.T) {
+		t.Setenv(PassphraseEnvVar, "")
+		path := filepath.Join(t.TempDir(), "token.enc")
+		store, _ := New(config.TokenRef{Source: SourceFile, Value: path})
+
+		if err := store.Set("secret"); err != nil {
+			t.Fatal("expected nil, got error")
+		}
+
+		got, err := store.Get()
+		if err != nil {
+			t.Fatal("expected nil, got error")
+		}
+		if got != "secret" {
+			t.Errorf("got %q, want %q", got, "secret")
+		}
+	})
+
+	t.Run("returns error for wrong passphrase", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "token.enc")
+		store, _ := New(config.TokenRef{Source: SourceFile, Value: path})
+
+		t.Setenv(PassphraseEnvVar, "right")
+		if err := store.Set("secret"); err != nil {
+			t.Fatal("expected nil, got error")
+		}
+
+		t.Setenv(PassphraseEnvVar, "wrong")
+		if _, err := store.Get(); err == nil {
+			t.Fatal("expected error, got nil")
+		}
+	})
+
+	t.Run("removes token and key on delete", func(t *testing.T) {
+		t.Setenv(PassphraseEnvVar, "")
+		path := filepath.Join(t.TempDir(), "token.enc")
+		store, _ := New(config.TokenRef{Source: SourceFile, Value: path})
+
+		store.Set("secret")
+		if err := store.Delete(); err != nil {
+			t.Fatal("expected nil, got error")
+		}
+		if _, err := store.Get(); err == nil {
+			t.Fatal("expected error, got nil")
+		}
+	})
+}
+
+func TestMask(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"", ""},
+		{"abc", "***"},
+		{"ghp_1234567890abcd", "****abcd"},
+	}
+
+	for _, test := range tests {
+		result := Mask(test.input)
+		if result != test.expected {
+			t.Errorf("expected %q, got %q", test.expected, result)
+		}
+	}
+}
@@ -0,0 +1,257 @@
+package credentials
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// PassphraseEnvVar optionally supplies the passphrase for file tokens
+const PassphraseEnvVar = "BUGBOX_TOKEN_PASSPHRASE"
+
+const keyringService = "bugbox"
+
+// execCommand and goos are swapped out in tests
+var (
+	execCommand = exec.Command
+	goos        = runtime.GOOS
+)
+
+// envStore reads the token from an environment variable
+type envStore struct {
+	name string
+}
+
+func (s *envStore) Get() (string, error) {
+	token := os.Getenv(s.name)
+	if token == "" {
+		return "", fmt.Errorf("%s is not set", s.name)
+	}
+	return token, nil
+}
+
+func (s *envStore) Set(secret string) error { return ErrReadOnly }
+func (s *envStore) Delete() error           { return ErrReadOnly }
+
+// commandStore reads the token from a command's stdout, e.g. `gh auth token`
+type commandStore struct {
+	command string
+}
+
+func (s *commandStore) Get() (string, error) {
+	var cmd *exec.Cmd
+	if goos == "windows" {
+		cmd = execCommand("cmd", "/c", s.command)
+	} else {
+		cmd = execCommand("sh", "-c", s.command)
+	}
+
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("running %q: %w", s.command, err)
+	}
+
+	token := strings.TrimSpace(string(out))
+	if token == "" {
+		return "", fmt.Errorf("%q returned no token", s.command)
+	}
+	return token, nil
+}
+
+func (s *commandStore) Set(secret string) error { return ErrReadOnly }
+func (s *commandStore) Delete() error           { return ErrReadOnly }
+
+// keyringStore keeps the token in the OS keyring via secret-tool or security
+type keyringStore struct {
+	account string
+}
+
+func (s *keyringStore) Get() (string, error) {
+	var cmd *exec.Cmd
+	switch goos {
+	case "linux":
+		cmd = execCommand("secret-tool", "lookup", "service", keyringService, "account", s.account)
+	case "darwin":
+		cmd = execCommand("security", "find-generic-password", "-s", keyringService, "-a", s.account, "-w")
+	default:
+		return "", fmt.Errorf("keyring unsupported on %s", goos)
+	}
+
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("keyring lookup: %w", err)
+	}
+
+	token := strings.TrimSpace(string(out))
+	if token == "" {
+		return "", fmt.Errorf("no token in keyring for %s", s.account)
+	}
+	return token, nil
+}
+
+func (s *keyringStore) Set(secret string) error {
+	var cmd *exec.Cmd
+	switch goos {
+	case "linux":
+		cmd = execCommand("secret-tool", "store", "--label=bugbox GitHub token", "service", keyringService, "account", s.account)
+		cmd.Stdin = strings.NewReader(secret)
+	case "darwin":
+		// -w last prompts for the secret, keeping it out of the process list.
+		// It's sent twice in case security asks for it to be retyped.
+		cmd = execCommand("security", "add-generic-password", "-U", "-s", keyringService, "-a", s.account, "-w")
+		cmd.Stdin = strings.NewReader(secret + "\n" + secret + "\n")
+	default:
+		return fmt.Errorf("keyring unsupported on %s", goos)
+	}
+
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("keyring store: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+func (s *keyringStore) Delete() error {
+	var cmd *exec.Cmd
+	switch goos {
+	case "linux":
+		cmd = execCommand("secret-tool", "clear", "service", keyringService, "account", s.account)
+	case "darwin":
+		cmd = execCommand("security", "delete-generic-password", "-s", keyringService, "-a", s.account)
+	default:
+		return fmt.Errorf("keyring unsupported on %s", goos)
+	}
+	return cmd.Run()
+}
+
+// KeyringAvailable returns true if an OS keyring tool is installed
+func KeyringAvailable() bool {
+	var tool string
+	switch goos {
+	case "linux":
+		tool = "secret-tool"
+	case "darwin":
+		tool = "security"
+	default:
+		return false
+	}
+	_, err := exec.LookPath(tool)
+	return err == nil
+}
+
+// fileStore keeps the token AES-GCM encrypted on disk. The key is derived from
+// BUGBOX_TOKEN_PASSPHRASE when set, otherwise it's a random key file next to
+// the token, which only obfuscates it: anyone who can read one can read both.
+type fileStore struct {
+	path string
+}
+
+func (s *fileStore) Get() (string, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return "", err
+	}
+
+	gcm, err := s.cipher(false)
+	if err != nil {
+		return "", err
+	}
+
+	if len(data) < gcm.NonceSize() {
+		return "", fmt.Errorf("token file is corrupt")
+	}
+	nonce, ciphertext := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", fmt.Errorf("decrypting token: %w", err)
+	}
+	return string(plaintext), nil
+}
+
+func (s *fileStore) Set(secret string) error {
+	gcm, err := s.cipher(true)
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return err
+	}
+
+	data := gcm.Seal(nonce, nonce, []byte(secret), nil)
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, data, 0600)
+}
+
+func (s *fileStore) Delete() error {
+	if err := os.Remove(s.path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := os.Remove(s.keyPath()); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (s *fileStore) keyPath() string {
+	return s.path + ".key"
+}
+
+// cipher builds the AES-GCM cipher, creating the key file if asked to
+func (s *fileStore) cipher(create bool) (cipher.AEAD, error) {
+	key, err := s.key(create)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func (s *fileStore) key(create bool) ([]byte, error) {
+	if passphrase := os.Getenv(PassphraseEnvVar); passphrase != "" {
+		sum := sha256.Sum256([]byte(passphrase))
+		return sum[:], nil
+	}
+
+	key, err := os.ReadFile(s.keyPath())
+	if err == nil && len(key) == 32 {
+		return key, nil
+	}
+	if !create {
+		return nil, fmt.Errorf("reading token key: %w", fallbackErr(err))
+	}
+
+	key = make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.keyPath()), 0700); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(s.keyPath(), key, 0600); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+func fallbackErr(err error) error {
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf("invalid key length")
+}
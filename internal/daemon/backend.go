@@ -0,0 +1,106 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/shaunmolloy/bugbox/internal/events"
+	"github.com/shaunmolloy/bugbox/internal/status"
+	"github.com/shaunmolloy/bugbox/internal/storage/config"
+)
+
+// Backend is the issue store and fetcher the TUI and CLI work with, either
+// in-process or through a running daemon
+type Backend interface {
+	Issues() (config.Issues, error)
+	MarkRead(org string, repo string, id int, read bool) error
+	MarkReadMany(changes []MarkReadParams) error
+	Refresh() error
+	Status() (status.Snapshot, error)
+	DismissErrors() error
+	Subscribe(ctx context.Context) (<-chan events.Event, error)
+}
+
+// Local works with the issue store and scheduler in this process
+type Local struct {
+	refresh func()
+}
+
+// NewLocal creates a Local backend, publishing sync progress until ctx is
+// cancelled. refresh requests an immediate fetch, e.g. Scheduler.Refresh.
+func NewLocal(ctx context.Context, refresh func()) *Local {
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-status.Sync.Changed:
+				snapshot := status.Sync.Get()
+				events.Default.Publish(events.Event{Type: events.StatusChanged, Status: &snapshot})
+			}
+		}
+	}()
+
+	return &Local{refresh: refresh}
+}
+
+func (l *Local) Issues() (config.Issues, error) {
+	return config.LoadIssues()
+}
+
+// MarkRead updates an issue's read state, merging with any concurrent fetch
+func (l *Local) MarkRead(org string, repo string, id int, read bool) error {
+	err := config.UpdateIssues(func(latest config.Issues) error {
+		issue, ok := latest[org][repo][id]
+		if !ok {
+			return fmt.Errorf("issue %s/%s#%d not found", org, repo, id)
+		}
+		issue.Read = read
+		latest[org][repo][id] = issue
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	events.Default.Publish(events.Event{Type: events.IssuesUpdated})
+	return nil
+}
+
+// MarkReadMany updates the read state of several issues in one save,
+// skipping any no longer stored, e.g. closed since
+func (l *Local) MarkReadMany(changes []MarkReadParams) error {
+	err := config.UpdateIssues(func(latest config.Issues) error {
+		for _, change := range changes {
+			if issue, ok := latest[change.Org][change.Repo][change.ID]; ok {
+				issue.Read = change.Read
+				latest[change.Org][change.Repo][change.ID] = issue
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	events.Default.Publish(events.Event{Type: events.IssuesUpdated})
+	return nil
+}
+
+func (l *Local) Refresh() error {
+	l.refresh()
+	return nil
+}
+
+func (l *Local) Status() (status.Snapshot, error) {
+	return status.Sync.Get(), nil
+}
+
+func (l *Local) DismissErrors() error {
+	status.Sync.DismissErrors()
+	return nil
+}
+
+func (l *Local) Subscribe(ctx context.Context) (<-chan events.Event, error) {
+	return events.Default.Subscribe(ctx), nil
+}
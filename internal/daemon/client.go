@@ -0,0 +1,148 @@
+package daemon
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/shaunmolloy/bugbox/internal/events"
+	"github.com/shaunmolloy/bugbox/internal/status"
+	"github.com/shaunmolloy/bugbox/internal/storage/config"
+)
+
+// callTimeout bounds each request, so a stuck daemon doesn't hang the TUI
+const callTimeout = 10 * time.Second
+
+// Client is a Backend talking to a daemon over its socket
+type Client struct {
+	path string
+}
+
+// Dial connects to the daemon listening at path, failing if none is running
+func Dial(path string) (*Client, error) {
+	conn, err := net.DialTimeout("unix", path, time.Second)
+	if err != nil {
+		return nil, err
+	}
+	conn.Close()
+	return &Client{path: path}, nil
+}
+
+func (c *Client) Issues() (config.Issues, error) {
+	var issues config.Issues
+	err := c.call(MethodList, nil, &issues)
+	return issues, err
+}
+
+func (c *Client) MarkRead(org string, repo string, id int, read bool) error {
+	return c.call(MethodMarkRead, MarkReadParams{Org: org, Repo: repo, ID: id, Read: read}, nil)
+}
+
+func (c *Client) MarkReadMany(changes []MarkReadParams) error {
+	return c.call(MethodMarkReadMany, MarkReadManyParams{Issues: changes}, nil)
+}
+
+func (c *Client) Refresh() error {
+	return c.call(MethodRefresh, nil, nil)
+}
+
+func (c *Client) Status() (status.Snapshot, error) {
+	var snapshot status.Snapshot
+	err := c.call(MethodStatus, nil, &snapshot)
+	return snapshot, err
+}
+
+func (c *Client) DismissErrors() error {
+	return c.call(MethodDismissErrors, nil, nil)
+}
+
+// Subscribe streams events from the daemon. The channel is closed when ctx
+// is cancelled or the daemon goes away.
+func (c *Client) Subscribe(ctx context.Context) (<-chan events.Event, error) {
+	conn, err := c.send(MethodSubscribe, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bufio.NewReader(conn))
+	if err := readResponse(dec, nil); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	// Events may be a long time apart
+	conn.SetDeadline(time.Time{})
+
+	ch := make(chan events.Event)
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+	go func() {
+		defer close(ch)
+		for {
+			var event events.Event
+			if err := dec.Decode(&event); err != nil {
+				return
+			}
+			select {
+			case ch <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
+
+// call sends one request and decodes the result into result, if not nil
+func (c *Client) call(method string, params any, result any) error {
+	conn, err := c.send(method, params)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return readResponse(json.NewDecoder(bufio.NewReader(conn)), result)
+}
+
+// send opens a connection and writes a request
+func (c *Client) send(method string, params any) (net.Conn, error) {
+	req := Request{Method: method}
+	if params != nil {
+		raw, err := json.Marshal(params)
+		if err != nil {
+			return nil, err
+		}
+		req.Params = raw
+	}
+
+	conn, err := net.DialTimeout("unix", c.path, time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("connecting to daemon: %w", err)
+	}
+	conn.SetDeadline(time.Now().Add(callTimeout))
+
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+func readResponse(dec *json.Decoder, result any) error {
+	var resp Response
+	if err := dec.Decode(&resp); err != nil {
+		return fmt.Errorf("reading daemon response: %w", err)
+	}
+	if resp.Error != "" {
+		return errors.New(resp.Error)
+	}
+	if result != nil && resp.Result != nil {
+		return json.Unmarshal(resp.Result, result)
+	}
+	return nil
+}
@@ -0,0 +1,231 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shaunmolloy/bugbox/internal/events"
+	"github.com/shaunmolloy/bugbox/internal/status"
+	"github.com/shaunmolloy/bugbox/internal/storage/config"
+	"github.com/shaunmolloy/bugbox/internal/types"
+)
+
+// fakeBackend records calls and serves canned data
+type fakeBackend struct {
+	mu        sync.Mutex
+	issues    config.Issues
+	markRead  []MarkReadParams
+	refreshed int
+	bus       *events.Bus
+}
+
+func (f *fakeBackend) Issues() (config.Issues, error) { return f.issues, nil }
+
+func (f *fakeBackend) MarkRead(org string, repo string, id int, read bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.issues[org][repo][id]; !ok {
+		return errors.New("issue not found")
+	}
+	f.markRead = append(f.markRead, MarkReadParams{org, repo, id, read})
+	return nil
+}
+
+func (f *fakeBackend) MarkReadMany(changes []MarkReadParams) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.markRead = append(f.markRead, changes...)
+	return nil
+}
+
+func (f *fakeBackend) Refresh() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.refreshed++
+	return nil
+}
+
+func (f *fakeBackend) Status() (status.Snapshot, error) {
+	return status.Snapshot{Syncing: true, Current: "example", Done: 1, Total: 3}, nil
+}
+
+func (f *fakeBackend) DismissErrors() error { return nil }
+
+func (f *fakeBackend) Subscribe(ctx context.Context) (<-chan events.Event, error) {
+	return f.bus.Subscribe(ctx), nil
+}
+
+func startServer(t *testing.T, backend Backend) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "bugbox.sock")
+
+	server, err := Listen(path, backend)
+	if err != nil {
+		t.Fatalf("expected nil, got error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		server.Serve(ctx)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	return path
+}
+
+func TestClient(t *testing.T) {
+	backend := &fakeBackend{
+		issues: config.Issues{"example": {"repo": {1: types.Issue{ID: 1, Title: "Bug"}}}},
+		bus:    events.NewBus(),
+	}
+	path := startServer(t, backend)
+
+	client, err := Dial(path)
+	if err != nil {
+		t.Fatalf("expected nil, got error: %v", err)
+	}
+
+	t.Run("returns issues", func(t *testing.T) {
+		issues, err := client.Issues()
+		if err != nil {
+			t.Fatalf("expected nil, got error: %v", err)
+		}
+		if issues["example"]["repo"][1].Title != "Bug" {
+			t.Errorf("unexpected issues %+v", issues)
+		}
+	})
+
+	t.Run("marks issues read", func(t *testing.T) {
+		if err := client.MarkRead("example", "repo", 1, true); err != nil {
+			t.Fatalf("expected nil, got error: %v", err)
+		}
+		if len(backend.markRead) != 1 || !backend.markRead[0].Read {
+			t.Errorf("unexpected calls %+v", backend.markRead)
+		}
+	})
+
+	t.Run("marks several issues read at once", func(t *testing.T) {
+		backend.markRead = nil
+		changes := []MarkReadParams{{"example", "repo", 1, false}, {"example", "other", 2, true}}
+		if err := client.MarkReadMany(changes); err != nil {
+			t.Fatalf("expected nil, got error: %v", err)
+		}
+		if len(backend.markRead) != 2 || backend.markRead[0].Read || backend.markRead[1].Repo != "other" {
+			t.Errorf("unexpected calls %+v", backend.markRead)
+		}
+	})
+
+	t.Run("returns backend errors", func(t *testing.T) {
+		err := client.MarkRead("example", "repo", 2, true)
+		if err == nil || err.Error() != "issue not found" {
+			t.Errorf("got %v, want issue not found", err)
+		}
+	})
+
+	t.Run("requests a refresh", func(t *testing.T) {
+		if err := client.Refresh(); err != nil {
+			t.Fatalf("expected nil, got error: %v", err)
+		}
+		if backend.refreshed != 1 {
+			t.Errorf("got %d refreshes, want 1", backend.refreshed)
+		}
+	})
+
+	t.Run("returns sync status", func(t *testing.T) {
+		snapshot, err := client.Status()
+		if err != nil {
+			t.Fatalf("expected nil, got error: %v", err)
+		}
+		if snapshot.Current != "example" || snapshot.Total != 3 {
+			t.Errorf("unexpected status %+v", snapshot)
+		}
+	})
+
+	t.Run("streams events to subscribers", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		ch, err := client.Subscribe(ctx)
+		if err != nil {
+			t.Fatalf("expected nil, got error: %v", err)
+		}
+		backend.bus.Publish(events.Event{Type: events.IssuesUpdated})
+
+		select {
+		case event := <-ch:
+			if event.Type != events.IssuesUpdated {
+				t.Errorf("got %q, want %q", event.Type, events.IssuesUpdated)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for event")
+		}
+	})
+}
+
+func TestLocal(t *testing.T) {
+	t.Run("marks several issues read in one save, skipping missing issues", func(t *testing.T) {
+		config.IssuesPath = filepath.Join(t.TempDir(), "issues.json")
+		config.SaveIssues(config.Issues{"example": {"api": {
+			1: {ID: 1, Org: "example", Repo: "api"},
+			2: {ID: 2, Org: "example", Repo: "api", Read: true},
+			3: {ID: 3, Org: "example", Repo: "api"},
+		}}})
+
+		l := &Local{}
+		err := l.MarkReadMany([]MarkReadParams{
+			{Org: "example", Repo: "api", ID: 1, Read: true},
+			{Org: "example", Repo: "api", ID: 2, Read: false},
+			{Org: "example", Repo: "web", ID: 9, Read: true},
+		})
+		if err != nil {
+			t.Fatalf("expected nil, got error: %v", err)
+		}
+
+		issues, _ := config.LoadIssues()
+		api := issues["example"]["api"]
+		if !api[1].Read || api[2].Read || api[3].Read {
+			t.Errorf("unexpected read states %+v", api)
+		}
+		if _, ok := issues["example"]["web"]; ok {
+			t.Error("expected the missing issue to be skipped")
+		}
+	})
+}
+
+func TestListen(t *testing.T) {
+	t.Run("returns ErrRunning when a daemon is listening", func(t *testing.T) {
+		path := startServer(t, &fakeBackend{bus: events.NewBus()})
+
+		if _, err := Listen(path, &fakeBackend{}); !errors.Is(err, ErrRunning) {
+			t.Errorf("got %v, want ErrRunning", err)
+		}
+	})
+
+	t.Run("replaces a stale socket", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "bugbox.sock")
+		if err := os.WriteFile(path, nil, 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		server, err := Listen(path, &fakeBackend{})
+		if err != nil {
+			t.Fatalf("expected nil, got error: %v", err)
+		}
+		server.listener.Close()
+	})
+
+	t.Run("returns an error dialling without a daemon", func(t *testing.T) {
+		if _, err := Dial(filepath.Join(t.TempDir(), "bugbox.sock")); err == nil {
+			t.Fatal("expected error, got nil")
+		}
+	})
+}
@@ -0,0 +1,40 @@
+package daemon
+
+import "encoding/json"
+
+// Methods supported over the socket. Each request is one line of JSON,
+// answered by one Response line. Subscribe then streams events.Event lines.
+const (
+	MethodList          = "list"
+	MethodMarkRead      = "mark_read"
+	MethodMarkReadMany  = "mark_read_many"
+	MethodRefresh       = "refresh"
+	MethodStatus        = "status"
+	MethodDismissErrors = "dismiss_errors"
+	MethodSubscribe     = "subscribe"
+)
+
+// Request is a call from a client
+type Request struct {
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+// Response answers a Request, with Error set when it failed
+type Response struct {
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+// MarkReadParams are the params of MethodMarkRead
+type MarkReadParams struct {
+	Org  string `json:"org"`
+	Repo string `json:"repo"`
+	ID   int    `json:"id"`
+	Read bool   `json:"read"`
+}
+
+// MarkReadManyParams are the params of MethodMarkReadMany
+type MarkReadManyParams struct {
+	Issues []MarkReadParams `json:"issues"`
+}
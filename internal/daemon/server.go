@@ -0,0 +1,181 @@
+package daemon
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/shaunmolloy/bugbox/internal/events"
+	"github.com/shaunmolloy/bugbox/internal/logging"
+)
+
+// ErrRunning is returned by Listen when another daemon owns the socket
+var ErrRunning = errors.New("daemon is already running")
+
+// Server answers requests on a Unix socket using a Backend
+type Server struct {
+	backend  Backend
+	listener net.Listener
+	path     string
+	wg       sync.WaitGroup
+}
+
+// Listen creates the socket at path, replacing it if left behind by a
+// daemon that didn't shut down cleanly
+func Listen(path string, backend Backend) (*Server, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+
+	if _, err := os.Stat(path); err == nil {
+		if conn, err := net.Dial("unix", path); err == nil {
+			conn.Close()
+			return nil, ErrRunning
+		}
+		logging.Info(fmt.Sprintf("Removing stale socket %s", path))
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only the current user may talk to the daemon
+	if err := os.Chmod(path, 0o600); err != nil {
+		listener.Close()
+		return nil, err
+	}
+
+	return &Server{backend: backend, listener: listener, path: path}, nil
+}
+
+// Serve accepts connections until ctx is cancelled, then closes the socket
+// and waits for open connections to finish
+func (s *Server) Serve(ctx context.Context) error {
+	go func() {
+		<-ctx.Done()
+		s.listener.Close()
+	}()
+	defer os.Remove(s.path)
+
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			s.wg.Wait()
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.handle(ctx, conn)
+		}()
+	}
+}
+
+func (s *Server) handle(ctx context.Context, conn net.Conn) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Unblock reads and writes on shutdown
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	var req Request
+	if err := json.NewDecoder(bufio.NewReader(conn)).Decode(&req); err != nil {
+		logging.Error(fmt.Sprintf("Invalid daemon request: %v", err))
+		return
+	}
+	logging.Debug(fmt.Sprintf("Daemon request: %s", req.Method))
+
+	enc := json.NewEncoder(conn)
+	if req.Method == MethodSubscribe {
+		// Stop streaming once the client hangs up
+		go func() {
+			io.Copy(io.Discard, conn)
+			cancel()
+		}()
+		s.subscribe(ctx, enc)
+		return
+	}
+
+	result, err := s.dispatch(req)
+	resp := Response{}
+	if err != nil {
+		resp.Error = err.Error()
+	} else if result != nil {
+		if resp.Result, err = json.Marshal(result); err != nil {
+			resp.Error = err.Error()
+		}
+	}
+
+	if err := enc.Encode(resp); err != nil {
+		logging.Error(fmt.Sprintf("Error writing daemon response: %v", err))
+	}
+}
+
+// dispatch calls the backend for a request
+func (s *Server) dispatch(req Request) (any, error) {
+	switch req.Method {
+	case MethodList:
+		return s.backend.Issues()
+	case MethodMarkRead:
+		var params MarkReadParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, fmt.Errorf("invalid params: %w", err)
+		}
+		return nil, s.backend.MarkRead(params.Org, params.Repo, params.ID, params.Read)
+	case MethodMarkReadMany:
+		var params MarkReadManyParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, fmt.Errorf("invalid params: %w", err)
+		}
+		return nil, s.backend.MarkReadMany(params.Issues)
+	case MethodRefresh:
+		return nil, s.backend.Refresh()
+	case MethodStatus:
+		return s.backend.Status()
+	case MethodDismissErrors:
+		return nil, s.backend.DismissErrors()
+	default:
+		return nil, fmt.Errorf("unknown method %q", req.Method)
+	}
+}
+
+// subscribe acknowledges the request then streams events until the client
+// goes away or the server stops
+func (s *Server) subscribe(ctx context.Context, enc *json.Encoder) {
+	updates, err := s.backend.Subscribe(ctx)
+	if err != nil {
+		enc.Encode(Response{Error: err.Error()})
+		return
+	}
+	if err := enc.Encode(Response{}); err != nil {
+		return
+	}
+
+	for event := range updates {
+		// Fetched bodies are only for the daemon's own search index
+		if event.Type == events.IssuesFetched {
+			continue
+		}
+		if err := enc.Encode(event); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package events
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/shaunmolloy/bugbox/internal/status"
+	"github.com/shaunmolloy/bugbox/internal/types"
+)
+
+// Event types
+const (
+	// IssuesUpdated is sent after issues are fetched or changed
+	IssuesUpdated = "issues_updated"
+	// StatusChanged is sent as sync progress changes, with the new status
+	StatusChanged = "status_changed"
+	// NewIssues is sent after a fetch finds issues not seen before, with the issues
+	NewIssues = "new_issues"
+	// ChangedIssues is sent after a fetch finds stored issues were retitled,
+	// relabelled or closed, with the issues
+	ChangedIssues = "changed_issues"
+	// IssuesFetched is sent with every issue fetched or delivered, bodies
+	// included, for the search index
+	IssuesFetched = "issues_fetched"
+)
+
+// Event is something subscribers may want to react to, e.g. by redrawing
+type Event struct {
+	Type   string           `json:"type"`
+	At     time.Time        `json:"at"`
+	Status *status.Snapshot `json:"status,omitempty"`
+	Issues []types.Issue    `json:"issues,omitempty"`
+}
+
+// subscriberBuffer is how many events a slow subscriber can fall behind
+// before further events are dropped for it
+const subscriberBuffer = 16
+
+// Bus fans out published events to every subscriber
+type Bus struct {
+	mu          sync.Mutex
+	subscribers map[chan Event]struct{}
+	queues      map[*queue]struct{}
+}
+
+// Default is the bus shared by the scheduler, daemon and TUI
+var Default = NewBus()
+
+func NewBus() *Bus {
+	return &Bus{
+		subscribers: make(map[chan Event]struct{}),
+		queues:      make(map[*queue]struct{}),
+	}
+}
+
+// Subscribe returns a channel of events, closed once ctx is cancelled
+func (b *Bus) Subscribe(ctx context.Context) <-chan Event {
+	ch := make(chan Event, subscriberBuffer)
+
+	b.mu.Lock()
+	b.subscribers[ch] = struct{}{}
+	b.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		b.mu.Lock()
+		delete(b.subscribers, ch)
+		close(ch)
+		b.mu.Unlock()
+	}()
+
+	return ch
+}
+
+// Queue returns a channel of events of the given types, closed once ctx is
+// cancelled. Unlike Subscribe no event is dropped: they queue for as long as
+// the subscriber takes, so it suits hooks, notifications and indexing
+func (b *Bus) Queue(ctx context.Context, types ...string) <-chan Event {
+	q := &queue{types: types, ready: make(chan struct{}, 1)}
+	out := make(chan Event)
+
+	b.mu.Lock()
+	b.queues[q] = struct{}{}
+	b.mu.Unlock()
+
+	go func() {
+		defer close(out)
+		defer func() {
+			b.mu.Lock()
+			delete(b.queues, q)
+			b.mu.Unlock()
+		}()
+
+		for {
+			select {
+			case <-q.ready:
+			case <-ctx.Done():
+				return
+			}
+			for _, event := range q.take() {
+				select {
+				case out <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return out
+}
+
+// queue holds events for a Queue subscriber until it's ready for them
+type queue struct {
+	types  []string
+	mu     sync.Mutex
+	events []Event
+	ready  chan struct{}
+}
+
+func (q *queue) push(event Event) {
+	if len(q.types) > 0 && !slices.Contains(q.types, event.Type) {
+		return
+	}
+
+	q.mu.Lock()
+	q.events = append(q.events, event)
+	q.mu.Unlock()
+
+	select {
+	case q.ready <- struct{}{}:
+	default:
+		// Already signalled, the pending events will be taken together
+	}
+}
+
+func (q *queue) take() []Event {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	events := q.events
+	q.events = nil
+	return events
+}
+
+// Publish sends event to every subscriber without blocking
+func (b *Bus) Publish(event Event) {
+	if event.At.IsZero() {
+		event.At = time.Now()
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subscribers {
+		select {
+		case ch <- event:
+		default:
+			// Subscriber is behind, it'll catch up on the next event
+		}
+	}
+	for q := range b.queues {
+		q.push(event)
+	}
+}
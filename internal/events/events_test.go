@@ -0,0 +1,125 @@
+package events
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestBus(t *testing.T) {
+	t.Run("sends events to every subscriber", func(t *testing.T) {
+		bus := NewBus()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		first := bus.Subscribe(ctx)
+		second := bus.Subscribe(ctx)
+		bus.Publish(Event{Type: IssuesUpdated})
+
+		for _, ch := range []<-chan Event{first, second} {
+			select {
+			case event := <-ch:
+				if event.Type != IssuesUpdated || event.At.IsZero() {
+					t.Errorf("unexpected event %+v", event)
+				}
+			case <-time.After(time.Second):
+				t.Fatal("timed out waiting for event")
+			}
+		}
+	})
+
+	t.Run("closes the channel when unsubscribed", func(t *testing.T) {
+		bus := NewBus()
+		ctx, cancel := context.WithCancel(context.Background())
+
+		ch := bus.Subscribe(ctx)
+		cancel()
+
+		select {
+		case _, ok := <-ch:
+			if ok {
+				t.Fatal("expected channel to be closed")
+			}
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for close")
+		}
+
+		// Publishing after unsubscribing must not panic
+		bus.Publish(Event{Type: IssuesUpdated})
+	})
+
+	t.Run("drops events for slow subscribers without blocking", func(t *testing.T) {
+		bus := NewBus()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		ch := bus.Subscribe(ctx)
+		for i := 0; i < subscriberBuffer+10; i++ {
+			bus.Publish(Event{Type: IssuesUpdated})
+		}
+
+		if len(ch) != subscriberBuffer {
+			t.Errorf("got %d buffered events, want %d", len(ch), subscriberBuffer)
+		}
+	})
+}
+
+func TestBusQueue(t *testing.T) {
+	t.Run("keeps every event for slow subscribers", func(t *testing.T) {
+		bus := NewBus()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		ch := bus.Queue(ctx)
+		total := subscriberBuffer * 4
+		for i := 0; i < total; i++ {
+			bus.Publish(Event{Type: NewIssues})
+		}
+
+		for i := 0; i < total; i++ {
+			select {
+			case <-ch:
+			case <-time.After(time.Second):
+				t.Fatalf("got %d events, want %d", i, total)
+			}
+		}
+	})
+
+	t.Run("only sends the given types", func(t *testing.T) {
+		bus := NewBus()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		ch := bus.Queue(ctx, NewIssues)
+		bus.Publish(Event{Type: StatusChanged})
+		bus.Publish(Event{Type: NewIssues})
+
+		select {
+		case event := <-ch:
+			if event.Type != NewIssues {
+				t.Errorf("got %s, want %s", event.Type, NewIssues)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for event")
+		}
+	})
+
+	t.Run("closes the channel when unsubscribed", func(t *testing.T) {
+		bus := NewBus()
+		ctx, cancel := context.WithCancel(context.Background())
+
+		ch := bus.Queue(ctx)
+		cancel()
+
+		select {
+		case _, ok := <-ch:
+			if ok {
+				t.Fatal("expected channel to be closed")
+			}
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for close")
+		}
+
+		bus.Publish(Event{Type: IssuesUpdated})
+	})
+}
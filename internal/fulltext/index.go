@@ -0,0 +1,440 @@
+package fulltext
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/shaunmolloy/bugbox/internal/storage/config"
+	"github.com/shaunmolloy/bugbox/internal/types"
+)
+
+// IndexPath is the location of the search index, set by main at startup
+var IndexPath string
+
+// snippetRadius is how many characters of context a snippet shows either
+// side of a match
+const snippetRadius = 40
+
+// Doc is the text of an issue beyond its title
+type Doc struct {
+	Body     string   `json:"body"`
+	Comments []string `json:"comments,omitempty"`
+	// UpdatedAt is the issue's updated time when it was indexed
+	UpdatedAt time.Time `json:"updated_at"`
+	// Pending is set when comments still need fetching
+	Pending bool `json:"pending,omitempty"`
+}
+
+// Index is an inverted index of issue bodies and comments, keyed by Key
+type Index struct {
+	mu       sync.Mutex
+	docs     map[string]Doc
+	text     map[string]string // lowercased body and comments, for substring checks
+	postings map[string]map[string]struct{}
+	// suffixes of every word, sorted so the words containing a search can be
+	// found by binary search. Rebuilt on the next search once words change.
+	suffixes []suffix
+	// matches caches the docs containing each searched value, as the same
+	// value is checked against every issue
+	matches map[string]map[string]struct{}
+	// changed holds the keys added or removed since the last save, and
+	// logged how many changes are in the log since the last compaction
+	changed map[string]struct{}
+	logged  int
+}
+
+// suffix is the end of word from some offset, e.g. "gin" of "login"
+type suffix struct {
+	text string
+	word string
+}
+
+// logEntry is a change appended to the log beside the saved index, with a
+// nil Doc for a removed key
+type logEntry struct {
+	Key string `json:"key"`
+	Doc *Doc   `json:"doc,omitempty"`
+}
+
+// Key identifies an issue in the index
+func Key(issue types.Issue) string {
+	return fmt.Sprintf("%s/%s#%d", issue.Org, issue.Repo, issue.ID)
+}
+
+func NewIndex() *Index {
+	return &Index{
+		docs:     make(map[string]Doc),
+		text:     make(map[string]string),
+		postings: make(map[string]map[string]struct{}),
+		matches:  make(map[string]map[string]struct{}),
+		changed:  make(map[string]struct{}),
+	}
+}
+
+// Load reads the index saved at path and the changes logged since,
+// returning an empty index if there isn't one
+func Load(path string) (*Index, error) {
+	idx := NewIndex()
+
+	var docs map[string]Doc
+	if err := config.LoadFromFile(path, &docs); err != nil && !os.IsNotExist(err) {
+		return idx, err
+	}
+	for key, doc := range docs {
+		idx.add(key, doc)
+	}
+
+	file, err := os.Open(logPath(path))
+	if os.IsNotExist(err) {
+		return idx, nil
+	}
+	if err != nil {
+		return idx, err
+	}
+	defer file.Close()
+
+	// Stop at the first bad entry, e.g. one cut short by a crash
+	decoder := json.NewDecoder(file)
+	for {
+		var entry logEntry
+		if err := decoder.Decode(&entry); err != nil {
+			break
+		}
+		idx.remove(entry.Key)
+		if entry.Doc != nil {
+			idx.add(entry.Key, *entry.Doc)
+		}
+		idx.logged++
+	}
+	return idx, nil
+}
+
+// Save appends the changes since the last save to the log beside path,
+// writing the whole index to path instead once the log outgrows it
+func (idx *Index) Save(path string) error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	if idx.logged+len(idx.changed) > len(idx.docs) {
+		return idx.compact(path)
+	}
+	if len(idx.changed) == 0 {
+		return nil
+	}
+
+	var data []byte
+	for key := range idx.changed {
+		entry := logEntry{Key: key}
+		if doc, ok := idx.docs[key]; ok {
+			entry.Doc = &doc
+		}
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		data = append(append(data, line...), '\n')
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(logPath(path), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if _, err := file.Write(data); err != nil {
+		return err
+	}
+
+	idx.logged += len(idx.changed)
+	clear(idx.changed)
+	return nil
+}
+
+// compact writes the whole index to path and empties the log
+func (idx *Index) compact(path string) error {
+	if err := config.SaveToFile(path, idx.docs); err != nil {
+		return err
+	}
+	if err := os.Remove(logPath(path)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	idx.logged = 0
+	clear(idx.changed)
+	return nil
+}
+
+// Version changes whenever the index saved at path does, so readers can
+// tell when to load it again
+func Version(path string) string {
+	var version string
+	for _, file := range []string{path, logPath(path)} {
+		if info, err := os.Stat(file); err == nil {
+			version += fmt.Sprintf("%d:%d;", info.ModTime().UnixNano(), info.Size())
+		}
+	}
+	return version
+}
+
+func logPath(path string) string {
+	return path + ".log"
+}
+
+// Len returns the number of indexed issues
+func (idx *Index) Len() int {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	return len(idx.docs)
+}
+
+// Doc returns the indexed text for key
+func (idx *Index) Doc(key string) (Doc, bool) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	doc, ok := idx.docs[key]
+	return doc, ok
+}
+
+// Add indexes doc under key, replacing what was there
+func (idx *Index) Add(key string, doc Doc) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.remove(key)
+	idx.add(key, doc)
+	idx.changed[key] = struct{}{}
+}
+
+// Remove drops key from the index
+func (idx *Index) Remove(key string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.remove(key)
+	idx.changed[key] = struct{}{}
+}
+
+func (idx *Index) add(key string, doc Doc) {
+	text := strings.ToLower(strings.Join(append([]string{doc.Body}, doc.Comments...), "\n"))
+	idx.docs[key] = doc
+	idx.text[key] = text
+
+	for _, word := range words(text) {
+		if idx.postings[word] == nil {
+			idx.postings[word] = make(map[string]struct{})
+			idx.suffixes = nil
+		}
+		idx.postings[word][key] = struct{}{}
+	}
+	clear(idx.matches)
+}
+
+func (idx *Index) remove(key string) {
+	if _, ok := idx.docs[key]; !ok {
+		return
+	}
+
+	for _, word := range words(idx.text[key]) {
+		delete(idx.postings[word], key)
+		if len(idx.postings[word]) == 0 {
+			delete(idx.postings, word)
+			idx.suffixes = nil
+		}
+	}
+	delete(idx.docs, key)
+	delete(idx.text, key)
+	clear(idx.matches)
+}
+
+// Contains returns true if issue's body or comments contain value, ignoring
+// case. Like title searches, value may be part of a word.
+func (idx *Index) Contains(issue types.Issue, value string) bool {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	value = strings.ToLower(value)
+	matches, ok := idx.matches[value]
+	if !ok {
+		matches = idx.search(value)
+		idx.matches[value] = matches
+	}
+	_, ok = matches[Key(issue)]
+	return ok
+}
+
+// Where a part of a searched value is in the words of matching docs
+const (
+	within   = iota // anywhere, e.g. "ogi" in "login"
+	ending          // at the end, for the first of several parts
+	starting        // at the start, for the last of several parts
+	whole           // the whole word, for the parts in between
+)
+
+// search returns the docs containing value, narrowing them down by the words
+// in value before checking the text
+func (idx *Index) search(value string) map[string]struct{} {
+	parts := words(value)
+
+	var candidates map[string]struct{}
+	for i, part := range parts {
+		position := whole
+		switch {
+		case len(parts) == 1:
+			position = within
+		case i == 0:
+			position = ending
+		case i == len(parts)-1:
+			position = starting
+		}
+
+		found := make(map[string]struct{})
+		for _, word := range idx.vocabulary(part, position) {
+			for key := range idx.postings[word] {
+				if _, ok := candidates[key]; ok || candidates == nil {
+					found[key] = struct{}{}
+				}
+			}
+		}
+		candidates = found
+		if len(candidates) == 0 {
+			break
+		}
+	}
+
+	matches := make(map[string]struct{})
+	for key := range candidates {
+		if strings.Contains(idx.text[key], value) {
+			matches[key] = struct{}{}
+		}
+	}
+	return matches
+}
+
+// vocabulary returns the indexed words with part at position, looking whole
+// words up directly and the rest by binary search of the sorted suffixes
+func (idx *Index) vocabulary(part string, position int) []string {
+	if position == whole {
+		if _, ok := idx.postings[part]; ok {
+			return []string{part}
+		}
+		return nil
+	}
+
+	if idx.suffixes == nil {
+		idx.sortSuffixes()
+	}
+	from, _ := slices.BinarySearchFunc(idx.suffixes, part, func(s suffix, part string) int {
+		return strings.Compare(s.text, part)
+	})
+
+	var found []string
+	for _, s := range idx.suffixes[from:] {
+		if !strings.HasPrefix(s.text, part) {
+			break
+		}
+		switch {
+		case position == ending && s.text != part:
+		case position == starting && s.text != s.word:
+		default:
+			found = append(found, s.word)
+		}
+	}
+	return found
+}
+
+// sortSuffixes lists every suffix of every word, in order
+func (idx *Index) sortSuffixes() {
+	idx.suffixes = make([]suffix, 0, len(idx.postings))
+	for word := range idx.postings {
+		for i := range word {
+			idx.suffixes = append(idx.suffixes, suffix{text: word[i:], word: word})
+		}
+	}
+	slices.SortFunc(idx.suffixes, func(a, b suffix) int {
+		return strings.Compare(a.text, b.text)
+	})
+}
+
+// Snippet is the context around a match, with Start and End the rune
+// offsets of the matched text within Text
+type Snippet struct {
+	Text       string
+	Start, End int
+}
+
+// Snippet returns context around the first of values found in issue's body,
+// then its comments
+func (idx *Index) Snippet(issue types.Issue, values []string) (Snippet, bool) {
+	idx.mu.Lock()
+	doc, ok := idx.docs[Key(issue)]
+	idx.mu.Unlock()
+	if !ok {
+		return Snippet{}, false
+	}
+
+	for _, text := range append([]string{doc.Body}, doc.Comments...) {
+		runes := []rune(strings.Join(strings.Fields(text), " "))
+		lower := []rune(strings.Map(unicode.ToLower, string(runes)))
+		for _, value := range values {
+			at := indexRunes(lower, []rune(strings.ToLower(value)))
+			if at < 0 || value == "" {
+				continue
+			}
+			return snippet(runes, at, at+len([]rune(value))), true
+		}
+	}
+	return Snippet{}, false
+}
+
+// snippet cuts text down to the match at [start, end) and its context
+func snippet(text []rune, start int, end int) Snippet {
+	from := max(start-snippetRadius, 0)
+	to := min(end+snippetRadius, len(text))
+
+	prefix, suffix := "", ""
+	if from > 0 {
+		prefix = "…"
+	}
+	if to < len(text) {
+		suffix = "…"
+	}
+
+	offset := len([]rune(prefix)) - from
+	return Snippet{
+		Text:  prefix + string(text[from:to]) + suffix,
+		Start: start + offset,
+		End:   end + offset,
+	}
+}
+
+func indexRunes(text []rune, value []rune) int {
+	for i := 0; i+len(value) <= len(text); i++ {
+		if string(text[i:i+len(value)]) == string(value) {
+			return i
+		}
+	}
+	return -1
+}
+
+// words splits text into its distinct lowercase words
+func words(text string) []string {
+	seen := make(map[string]struct{})
+	var unique []string
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if _, ok := seen[word]; ok {
+			continue
+		}
+		seen[word] = struct{}{}
+		unique = append(unique, word)
+	}
+	return unique
+}
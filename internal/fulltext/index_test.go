@@ -0,0 +1,201 @@
+package fulltext
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/shaunmolloy/bugbox/internal/types"
+)
+
+var (
+	crash = types.Issue{ID: 1, Org: "example", Repo: "api"}
+	login = types.Issue{ID: 2, Org: "example", Repo: "web"}
+)
+
+func newTestIndex() *Index {
+	idx := NewIndex()
+	idx.Add(Key(crash), Doc{
+		Body:     "The importer panics on empty CSV files.",
+		Comments: []string{"Stack trace: nil pointer dereference in parser.go"},
+	})
+	idx.Add(Key(login), Doc{Body: "SSO login redirects back to the start page"})
+	return idx
+}
+
+func TestContains(t *testing.T) {
+	idx := newTestIndex()
+
+	t.Run("returns true for words in bodies and comments", func(t *testing.T) {
+		if !idx.Contains(crash, "panics") {
+			t.Error("expected body match")
+		}
+		if !idx.Contains(crash, "dereference") {
+			t.Error("expected comment match")
+		}
+		if idx.Contains(login, "panics") {
+			t.Error("expected no match")
+		}
+	})
+
+	t.Run("ignores case and matches parts of words", func(t *testing.T) {
+		if !idx.Contains(crash, "IMPORT") {
+			t.Error("expected match")
+		}
+	})
+
+	t.Run("matches phrases only when the words are together", func(t *testing.T) {
+		if !idx.Contains(login, "back to the start") {
+			t.Error("expected match")
+		}
+		if idx.Contains(login, "start back") {
+			t.Error("expected no match")
+		}
+	})
+
+	t.Run("matches phrases starting and ending within words", func(t *testing.T) {
+		if !idx.Contains(login, "ogin redirects ba") {
+			t.Error("expected match")
+		}
+		if idx.Contains(login, "log redirects") {
+			t.Error("expected no match")
+		}
+	})
+
+	t.Run("reflects removed and replaced docs", func(t *testing.T) {
+		idx := newTestIndex()
+		idx.Contains(crash, "panics") // cache the result
+
+		idx.Add(Key(crash), Doc{Body: "Fixed"})
+		if idx.Contains(crash, "panics") {
+			t.Error("expected replaced text not to match")
+		}
+
+		idx.Remove(Key(login))
+		if idx.Contains(login, "login") || idx.Len() != 1 {
+			t.Error("expected removed doc not to match")
+		}
+	})
+}
+
+func TestSnippet(t *testing.T) {
+	idx := newTestIndex()
+
+	t.Run("returns context around the match", func(t *testing.T) {
+		snippet, ok := idx.Snippet(crash, []string{"nil pointer"})
+		if !ok {
+			t.Fatal("expected snippet")
+		}
+		if snippet.Text != "Stack trace: nil pointer dereference in parser.go" {
+			t.Errorf("got %q", snippet.Text)
+		}
+		if got := string([]rune(snippet.Text)[snippet.Start:snippet.End]); got != "nil pointer" {
+			t.Errorf("expected match at offsets, got %q", got)
+		}
+	})
+
+	t.Run("trims long text either side", func(t *testing.T) {
+		idx := NewIndex()
+		long := "start " + strings.Repeat("a", 100) + " needle " + strings.Repeat("b", 100)
+		idx.Add(Key(crash), Doc{Body: long})
+
+		snippet, _ := idx.Snippet(crash, []string{"NEEDLE"})
+		runes := []rune(snippet.Text)
+		if runes[0] != '…' || runes[len(runes)-1] != '…' {
+			t.Errorf("expected ellipses, got %q", snippet.Text)
+		}
+		if got := string(runes[snippet.Start:snippet.End]); got != "needle" {
+			t.Errorf("expected match at offsets, got %q", got)
+		}
+	})
+
+	t.Run("returns false without a match", func(t *testing.T) {
+		if _, ok := idx.Snippet(login, []string{"panics"}); ok {
+			t.Error("expected no snippet")
+		}
+	})
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("returns an empty index when there's no file", func(t *testing.T) {
+		idx, err := Load(filepath.Join(t.TempDir(), "index.json"))
+		if err != nil {
+			t.Fatal("expected nil, got error")
+		}
+		if idx.Len() != 0 {
+			t.Errorf("expected empty index, got %d", idx.Len())
+		}
+	})
+
+	t.Run("returns changes logged since the index was written", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "index.json")
+		idx := newTestIndex()
+		idx.Add("example/docs#3", Doc{Body: "Typo"})
+		if err := idx.compact(path); err != nil {
+			t.Fatal("expected nil, got error")
+		}
+
+		idx.Add(Key(crash), Doc{Body: "Fixed"})
+		idx.Remove(Key(login))
+		if err := idx.Save(path); err != nil {
+			t.Fatal("expected nil, got error")
+		}
+		if _, err := os.Stat(path + ".log"); err != nil {
+			t.Fatalf("expected changes to be logged, got %v", err)
+		}
+
+		loaded, err := Load(path)
+		if err != nil {
+			t.Fatal("expected nil, got error")
+		}
+		if loaded.Len() != 2 || !loaded.Contains(crash, "fixed") || loaded.Contains(crash, "panics") || loaded.Contains(login, "login") {
+			t.Error("expected logged changes to be applied")
+		}
+	})
+
+	t.Run("compacts the log once it outgrows the index", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "index.json")
+		idx := newTestIndex()
+		// The first save logs both docs, the second would make the log longer
+		for range 2 {
+			idx.Add(Key(crash), Doc{Body: "Fixed"})
+			if err := idx.Save(path); err != nil {
+				t.Fatal("expected nil, got error")
+			}
+		}
+
+		if _, err := os.Stat(path + ".log"); !os.IsNotExist(err) {
+			t.Errorf("expected the log to be removed, got %v", err)
+		}
+		if loaded, _ := Load(path); loaded.Len() != 2 {
+			t.Errorf("expected 2 docs, got %d", loaded.Len())
+		}
+	})
+
+	t.Run("ignores a log entry cut short", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "index.json")
+		newTestIndex().compact(path)
+		os.WriteFile(path+".log", []byte(`{"key":"example/api#1","doc":{"bo`), 0644)
+
+		loaded, err := Load(path)
+		if err != nil || !loaded.Contains(crash, "panics") {
+			t.Errorf("expected the saved index, got %v", err)
+		}
+	})
+
+	t.Run("returns the saved index", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "index.json")
+		if err := newTestIndex().Save(path); err != nil {
+			t.Fatal("expected nil, got error")
+		}
+
+		idx, err := Load(path)
+		if err != nil {
+			t.Fatal("expected nil, got error")
+		}
+		if !idx.Contains(crash, "dereference") {
+			t.Error("expected loaded index to match")
+		}
+	})
+}
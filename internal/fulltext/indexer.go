@@ -0,0 +1,107 @@
+package fulltext
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/shaunmolloy/bugbox/internal/events"
+	"github.com/shaunmolloy/bugbox/internal/logging"
+	"github.com/shaunmolloy/bugbox/internal/types"
+)
+
+// maxCommentFetches limits comment requests per batch of fetched issues, so
+// the first full sync doesn't use up the API rate limit. Issues left over
+// have their comments fetched as they're seen again.
+const maxCommentFetches = 50
+
+// CommentsFunc fetches the bodies of an issue's comments
+type CommentsFunc func(ctx context.Context, issue types.Issue) ([]string, error)
+
+// Indexer keeps the index saved at path up to date as issues are fetched
+type Indexer struct {
+	index    *Index
+	path     string
+	comments CommentsFunc
+}
+
+// NewIndexer loads the index at path to update it
+func NewIndexer(path string, comments CommentsFunc) (*Indexer, error) {
+	index, err := Load(path)
+	if err != nil {
+		return nil, fmt.Errorf("loading search index: %w", err)
+	}
+	return &Indexer{index: index, path: path, comments: comments}, nil
+}
+
+// Start indexes issues fetched on bus until ctx is cancelled, letting the
+// TUI know to reload the index
+func (x *Indexer) Start(ctx context.Context, bus *events.Bus) {
+	// Queued rather than subscribed, so fetches published while comments are
+	// being fetched aren't dropped
+	updates := bus.Queue(ctx, events.IssuesFetched)
+	go func() {
+		for event := range updates {
+			changed, err := x.Update(ctx, event.Issues)
+			if err != nil {
+				logging.Error(fmt.Sprintf("Error saving search index: %v", err))
+				continue
+			}
+			if changed {
+				bus.Publish(events.Event{Type: events.IssuesUpdated})
+			}
+		}
+	}()
+}
+
+// Update indexes issues updated since they were last indexed, dropping
+// closed ones, and saves the index if anything changed
+func (x *Indexer) Update(ctx context.Context, issues []types.Issue) (bool, error) {
+	changed := false
+	fetches := 0
+
+	for _, issue := range issues {
+		key := Key(issue)
+		existing, ok := x.index.Doc(key)
+
+		if issue.State == types.StateClosed {
+			if ok {
+				x.index.Remove(key)
+				changed = true
+			}
+			continue
+		}
+		if ok && !existing.Pending && !issue.UpdatedAt.After(existing.UpdatedAt) {
+			continue
+		}
+
+		doc := Doc{Body: issue.Body, Comments: existing.Comments, UpdatedAt: issue.UpdatedAt}
+		switch {
+		case issue.Comments == 0:
+			doc.Comments = nil
+		case fetches >= maxCommentFetches || ctx.Err() != nil:
+			doc.Pending = true
+		default:
+			fetches++
+			comments, err := x.comments(ctx, issue)
+			if err != nil {
+				// Likely rate limited, so leave the rest for later
+				logging.Error(fmt.Sprintf("Error fetching comments for %s: %v", key, err))
+				fetches = maxCommentFetches
+				doc.Pending = true
+				break
+			}
+			doc.Comments = comments
+		}
+
+		x.index.Add(key, doc)
+		changed = true
+	}
+
+	if !changed {
+		return false, nil
+	}
+	if fetches > 0 {
+		logging.Info(fmt.Sprintf("Indexed comments for %d issue(s)", fetches))
+	}
+	return true, x.index.Save(x.path)
+}
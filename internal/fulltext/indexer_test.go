@@ -0,0 +1,98 @@
+package fulltext
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shaunmolloy/bugbox/internal/types"
+)
+
+func TestUpdate(t *testing.T) {
+	updated := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	newIndexer := func(t *testing.T, comments CommentsFunc) *Indexer {
+		x, err := NewIndexer(filepath.Join(t.TempDir(), "index.json"), comments)
+		if err != nil {
+			t.Fatal("expected nil, got error")
+		}
+		return x
+	}
+
+	t.Run("indexes bodies and comments and saves", func(t *testing.T) {
+		x := newIndexer(t, func(ctx context.Context, issue types.Issue) ([]string, error) {
+			return []string{"Seeing this too"}, nil
+		})
+		issue := types.Issue{ID: 1, Org: "example", Repo: "api", Body: "Crashes on start", Comments: 1, UpdatedAt: updated}
+
+		changed, err := x.Update(context.Background(), []types.Issue{issue})
+		if err != nil || !changed {
+			t.Fatalf("expected change, got %t, %v", changed, err)
+		}
+		if !x.index.Contains(issue, "crashes") || !x.index.Contains(issue, "seeing") {
+			t.Error("expected body and comments indexed")
+		}
+
+		saved, _ := Load(x.path)
+		if saved.Len() != 1 {
+			t.Errorf("expected saved index, got %d docs", saved.Len())
+		}
+	})
+
+	t.Run("skips issues not updated since indexed", func(t *testing.T) {
+		calls := 0
+		x := newIndexer(t, func(ctx context.Context, issue types.Issue) ([]string, error) {
+			calls++
+			return nil, nil
+		})
+		issue := types.Issue{ID: 1, Org: "example", Repo: "api", Comments: 2, UpdatedAt: updated}
+
+		x.Update(context.Background(), []types.Issue{issue})
+		changed, _ := x.Update(context.Background(), []types.Issue{issue})
+		if changed || calls != 1 {
+			t.Errorf("expected one fetch and no change, got %d fetches, changed %t", calls, changed)
+		}
+
+		issue.UpdatedAt = updated.Add(time.Hour)
+		x.Update(context.Background(), []types.Issue{issue})
+		if calls != 2 {
+			t.Errorf("expected refetch after update, got %d fetches", calls)
+		}
+	})
+
+	t.Run("leaves comments pending after an error or the limit", func(t *testing.T) {
+		fail := true
+		x := newIndexer(t, func(ctx context.Context, issue types.Issue) ([]string, error) {
+			if fail {
+				return nil, errors.New("rate limited")
+			}
+			return []string{"Retried"}, nil
+		})
+		issue := types.Issue{ID: 1, Org: "example", Repo: "api", Body: "Body", Comments: 1, UpdatedAt: updated}
+
+		x.Update(context.Background(), []types.Issue{issue})
+		if doc, _ := x.index.Doc(Key(issue)); !doc.Pending || !x.index.Contains(issue, "body") {
+			t.Errorf("expected body indexed with comments pending, got %+v", doc)
+		}
+
+		fail = false
+		x.Update(context.Background(), []types.Issue{issue})
+		if doc, _ := x.index.Doc(Key(issue)); doc.Pending || !x.index.Contains(issue, "retried") {
+			t.Errorf("expected comments indexed on retry, got %+v", doc)
+		}
+	})
+
+	t.Run("removes closed issues", func(t *testing.T) {
+		x := newIndexer(t, nil)
+		issue := types.Issue{ID: 1, Org: "example", Repo: "api", Body: "Body", UpdatedAt: updated}
+
+		x.Update(context.Background(), []types.Issue{issue})
+		issue.State = types.StateClosed
+		x.Update(context.Background(), []types.Issue{issue})
+		if x.index.Len() != 0 {
+			t.Errorf("expected closed issue removed, got %d docs", x.index.Len())
+		}
+	})
+}
@@ -0,0 +1,106 @@
+package fuzzy
+
+import (
+	"unicode"
+)
+
+// Scores, loosely following fzf: matched characters score, with bonuses for
+// runs and word starts and penalties for gaps between them
+const (
+	scoreMatch        = 16
+	bonusConsecutive  = 8
+	bonusBoundary     = 8
+	bonusCamel        = 7
+	bonusFirstChar    = 2 // multiplier for a boundary bonus on the first character
+	penaltyGapStart   = 3
+	penaltyGapExtends = 1
+)
+
+// Match finds pattern's characters in order in text, ignoring case. It
+// returns a score, higher for tighter matches at word starts, and the rune
+// positions of the matched characters.
+func Match(pattern []rune, text []rune) (score int, positions []int, ok bool) {
+	if len(pattern) == 0 {
+		return 0, nil, true
+	}
+
+	// Find the first occurrence of the whole pattern
+	p := 0
+	end := -1
+	for i, r := range text {
+		if unicode.ToLower(r) == pattern[p] {
+			p++
+			if p == len(pattern) {
+				end = i
+				break
+			}
+		}
+	}
+	if end < 0 {
+		return 0, nil, false
+	}
+
+	// Walk back from the end for the shortest match
+	p = len(pattern) - 1
+	start := end
+	for i := end; i >= 0; i-- {
+		if unicode.ToLower(text[i]) == pattern[p] {
+			p--
+			if p < 0 {
+				start = i
+				break
+			}
+		}
+	}
+
+	// Score the match within [start, end]
+	positions = make([]int, 0, len(pattern))
+	p = 0
+	inGap := false
+	consecutive := 0
+	for i := start; i <= end && p < len(pattern); i++ {
+		if unicode.ToLower(text[i]) != pattern[p] {
+			if len(positions) > 0 {
+				if inGap {
+					score -= penaltyGapExtends
+				} else {
+					score -= penaltyGapStart
+				}
+			}
+			inGap = true
+			consecutive = 0
+			continue
+		}
+
+		bonus := charBonus(text, i)
+		if p == 0 {
+			bonus *= bonusFirstChar
+		}
+		if consecutive > 0 {
+			bonus = max(bonus, bonusConsecutive)
+		}
+		score += scoreMatch + bonus
+
+		positions = append(positions, i)
+		inGap = false
+		consecutive++
+		p++
+	}
+
+	return score, positions, true
+}
+
+// charBonus rewards characters starting a word, e.g. after a space or in camelCase
+func charBonus(text []rune, i int) int {
+	if i == 0 {
+		return bonusBoundary
+	}
+	prev, cur := text[i-1], text[i]
+	switch {
+	case !unicode.IsLetter(prev) && !unicode.IsDigit(prev) && (unicode.IsLetter(cur) || unicode.IsDigit(cur)):
+		return bonusBoundary
+	case unicode.IsLower(prev) && unicode.IsUpper(cur):
+		return bonusCamel
+	}
+	return 0
+}
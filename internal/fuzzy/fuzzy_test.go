@@ -0,0 +1,158 @@
+package fuzzy
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/shaunmolloy/bugbox/internal/types"
+)
+
+func TestMatch(t *testing.T) {
+	t.Run("returns positions of characters matched in order", func(t *testing.T) {
+		_, positions, ok := Match([]rune("lgn"), []rune("Fix login"))
+		if !ok {
+			t.Fatal("expected match")
+		}
+		if !slices.Equal(positions, []int{4, 6, 8}) {
+			t.Errorf("expected [4 6 8], got %v", positions)
+		}
+	})
+
+	t.Run("ignores case", func(t *testing.T) {
+		if _, _, ok := Match([]rune("login"), []rune("LOGIN page")); !ok {
+			t.Error("expected match")
+		}
+	})
+
+	t.Run("returns false when characters are missing or out of order", func(t *testing.T) {
+		if _, _, ok := Match([]rune("nigol"), []rune("login")); ok {
+			t.Error("expected no match")
+		}
+		if _, _, ok := Match([]rune("logout"), []rune("login")); ok {
+			t.Error("expected no match")
+		}
+	})
+
+	t.Run("matches everything with an empty pattern", func(t *testing.T) {
+		if _, _, ok := Match(nil, []rune("login")); !ok {
+			t.Error("expected match")
+		}
+	})
+
+	t.Run("uses the shortest match", func(t *testing.T) {
+		_, positions, _ := Match([]rune("ab"), []rune("a-x-ab"))
+		if !slices.Equal(positions, []int{4, 5}) {
+			t.Errorf("expected [4 5], got %v", positions)
+		}
+	})
+
+	t.Run("scores runs and word starts above scattered matches", func(t *testing.T) {
+		tight, _, _ := Match([]rune("log"), []rune("fix login"))
+		start, _, _ := Match([]rune("log"), []rune("fix the logs"))
+		scattered, _, _ := Match([]rune("log"), []rune("floating"))
+		if tight <= scattered || start <= scattered {
+			t.Errorf("expected %d and %d above %d", tight, start, scattered)
+		}
+
+		boundary, _, _ := Match([]rune("fl"), []rune("fix login"))
+		inside, _, _ := Match([]rune("fl"), []rune("waffle"))
+		if boundary <= inside {
+			t.Errorf("expected %d above %d", boundary, inside)
+		}
+	})
+}
+
+func newIssue(id int, title string, repo string, labels ...string) types.Issue {
+	issue := types.Issue{
+		ID:        id,
+		Title:     title,
+		Org:       "example",
+		Repo:      repo,
+		CreatedAt: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	for _, label := range labels {
+		issue.Labels = append(issue.Labels, types.Label{Name: label})
+	}
+	return issue
+}
+
+func TestSearch(t *testing.T) {
+	index := NewIndex([]types.Issue{
+		newIssue(1, "Flaky test on CI", "web"),
+		newIssue(2, "Login fails with SSO", "auth", "bug"),
+		newIssue(3, "Add logging to the importer", "api"),
+		newIssue(42, "Crash on start", "web", "good first issue"),
+	})
+
+	ids := func(results []Result) []int {
+		var ids []int
+		for _, result := range results {
+			ids = append(ids, result.Issue.ID)
+		}
+		return ids
+	}
+
+	t.Run("returns every issue in order for an empty query", func(t *testing.T) {
+		if got := ids(index.Search("")); !slices.Equal(got, []int{1, 2, 3, 42}) {
+			t.Errorf("expected [1 2 3 42], got %v", got)
+		}
+	})
+
+	t.Run("ranks the best match first", func(t *testing.T) {
+		got := ids(index.Search("login"))
+		if len(got) == 0 || got[0] != 2 {
+			t.Errorf("expected issue 2 first, got %v", got)
+		}
+	})
+
+	t.Run("matches abbreviations", func(t *testing.T) {
+		if got := ids(index.Search("lgnfl")); !slices.Equal(got, []int{2}) {
+			t.Errorf("expected [2], got %v", got)
+		}
+	})
+
+	t.Run("requires every term to match a field", func(t *testing.T) {
+		if got := ids(index.Search("crash web")); !slices.Equal(got, []int{42}) {
+			t.Errorf("expected [42], got %v", got)
+		}
+		if got := ids(index.Search("crash auth")); len(got) != 0 {
+			t.Errorf("expected no results, got %v", got)
+		}
+	})
+
+	t.Run("matches labels and numbers", func(t *testing.T) {
+		if got := ids(index.Search("gfi")); !slices.Contains(got, 42) {
+			t.Errorf("expected 42 in %v", got)
+		}
+		if got := ids(index.Search("#42")); !slices.Equal(got, []int{42}) {
+			t.Errorf("expected [42], got %v", got)
+		}
+	})
+
+	t.Run("returns positions for highlighting", func(t *testing.T) {
+		results := index.Search("crash web")
+		if len(results) != 1 {
+			t.Fatalf("expected 1 result, got %d", len(results))
+		}
+		if !slices.Equal(results[0].Title, []int{0, 1, 2, 3, 4}) {
+			t.Errorf("expected title [0 1 2 3 4], got %v", results[0].Title)
+		}
+		if !slices.Equal(results[0].Repo, []int{0, 1, 2}) {
+			t.Errorf("expected repo [0 1 2], got %v", results[0].Repo)
+		}
+	})
+}
+
+func BenchmarkSearch(b *testing.B) {
+	issues := make([]types.Issue, 20000)
+	for i := range issues {
+		issues[i] = newIssue(i, "Intermittent failure when uploading large attachments", "uploads", "bug", "needs triage")
+	}
+	index := NewIndex(issues)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		index.Search("uplfail")
+	}
+}
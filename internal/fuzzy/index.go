@@ -0,0 +1,132 @@
+package fuzzy
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/shaunmolloy/bugbox/internal/types"
+)
+
+// Result is an issue matching a search, with the rune positions of matched
+// characters in each field for highlighting
+type Result struct {
+	Issue types.Issue
+	Score int
+	Title []int
+	Repo  []int
+	Org   []int
+}
+
+// fields searched, with titles weighted above the rest
+const (
+	fieldTitle = iota
+	fieldRepo
+	fieldOrg
+	fieldLabels
+	fieldNumber
+)
+
+var fieldWeights = []int{3, 2, 1, 1, 1}
+
+// entry is an issue with its searchable fields prepared once
+type entry struct {
+	issue  types.Issue
+	fields [][]rune
+}
+
+// Index holds issues ready to search, so each keystroke doesn't need to
+// reload or re-prepare them
+type Index struct {
+	entries []entry
+}
+
+// NewIndex prepares issues for searching, keeping their order for ties
+func NewIndex(issues []types.Issue) *Index {
+	entries := make([]entry, len(issues))
+	for i, issue := range issues {
+		labels := make([]string, len(issue.Labels))
+		for j, label := range issue.Labels {
+			labels[j] = label.Name
+		}
+
+		entries[i] = entry{
+			issue: issue,
+			fields: [][]rune{
+				fieldTitle:  []rune(issue.Title),
+				fieldRepo:   []rune(issue.Repo),
+				fieldOrg:    []rune(issue.Org),
+				fieldLabels: []rune(strings.Join(labels, " ")),
+				fieldNumber: []rune("#" + strconv.Itoa(issue.ID)),
+			},
+		}
+	}
+	return &Index{entries: entries}
+}
+
+// Len returns the number of issues in the index
+func (idx *Index) Len() int {
+	return len(idx.entries)
+}
+
+// Search returns issues matching every space separated term of query, best
+// first. Each term may match a different field.
+func (idx *Index) Search(query string) []Result {
+	var terms [][]rune
+	for _, term := range strings.Fields(query) {
+		terms = append(terms, []rune(strings.ToLower(term)))
+	}
+
+	results := make([]Result, 0, len(idx.entries))
+	for _, e := range idx.entries {
+		result, ok := e.match(terms)
+		if ok {
+			results = append(results, result)
+		}
+	}
+
+	slices.SortStableFunc(results, func(a, b Result) int {
+		return b.Score - a.Score
+	})
+	return results
+}
+
+// match scores every term against its best field
+func (e entry) match(terms [][]rune) (Result, bool) {
+	result := Result{Issue: e.issue}
+
+	for _, term := range terms {
+		best, bestField := -1, -1
+		var bestPositions []int
+		for field, text := range e.fields {
+			score, positions, ok := Match(term, text)
+			if !ok {
+				continue
+			}
+			score *= fieldWeights[field]
+			if score > best {
+				best, bestField, bestPositions = score, field, positions
+			}
+		}
+		if bestField < 0 {
+			return Result{}, false
+		}
+
+		result.Score += best
+		switch bestField {
+		case fieldTitle:
+			result.Title = merge(result.Title, bestPositions)
+		case fieldRepo:
+			result.Repo = merge(result.Repo, bestPositions)
+		case fieldOrg:
+			result.Org = merge(result.Org, bestPositions)
+		}
+	}
+	return result, true
+}
+
+func merge(a []int, b []int) []int {
+	merged := append(slices.Clone(a), b...)
+	slices.Sort(merged)
+	return slices.Compact(merged)
+}
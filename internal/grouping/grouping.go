@@ -0,0 +1,169 @@
+package grouping
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/shaunmolloy/bugbox/internal/types"
+)
+
+// Field is what issues are grouped by
+type Field string
+
+const (
+	None      Field = ""
+	Org       Field = "org"
+	Repo      Field = "repo"
+	Label     Field = "label"
+	Assignee  Field = "assignee"
+	Milestone Field = "milestone"
+	Age       Field = "age"
+)
+
+// Fields lists every grouping, in the order they're cycled through
+var Fields = []Field{None, Org, Repo, Label, Assignee, Milestone, Age}
+
+// Age buckets, newest first
+const (
+	Today    = "Today"
+	ThisWeek = "This week"
+	Older    = "Older"
+)
+
+// Parse reads a grouping such as "repo", or "" for none
+func Parse(text string) (Field, error) {
+	field := Field(strings.ToLower(strings.TrimSpace(text)))
+	if !slices.Contains(Fields, field) {
+		return None, fmt.Errorf("unknown group %q", text)
+	}
+	return field, nil
+}
+
+// Next returns the following grouping, back to None after the last
+func (f Field) Next() Field {
+	return Fields[(slices.Index(Fields, f)+1)%len(Fields)]
+}
+
+// Group is a named section of issues, holding their indexes in the list
+// they were grouped from
+type Group struct {
+	Name    string
+	Indexes []int
+}
+
+// Groups sorts issues into groups, keeping their order within each group.
+// Names ignore case, and issues with several labels or assignees are in
+// each of their groups.
+func Groups(issues []types.Issue, field Field, now time.Time) []Group {
+	byName := make(map[string]*Group)
+	var groups []*Group
+	for i, issue := range issues {
+		for _, name := range Names(issue, field, now) {
+			key := strings.ToLower(name)
+			if byName[key] == nil {
+				byName[key] = &Group{Name: name}
+				groups = append(groups, byName[key])
+			}
+			// An issue labelled both "bug" and "Bug" is listed once, and as
+			// issues are added in order a repeat can only be the last one
+			group := byName[key]
+			if n := len(group.Indexes); n == 0 || group.Indexes[n-1] != i {
+				group.Indexes = append(group.Indexes, i)
+			}
+		}
+	}
+
+	slices.SortFunc(groups, func(a, b *Group) int {
+		return compare(field, a.Name, b.Name)
+	})
+
+	sorted := make([]Group, len(groups))
+	for i, group := range groups {
+		sorted[i] = *group
+	}
+	return sorted
+}
+
+// Names returns the groups an issue is in
+func Names(issue types.Issue, field Field, now time.Time) []string {
+	switch field {
+	case Org:
+		return []string{issue.Org}
+	case Repo:
+		return []string{issue.Org + "/" + issue.Repo}
+	case Label:
+		if len(issue.Labels) == 0 {
+			return []string{noLabel}
+		}
+		names := make([]string, len(issue.Labels))
+		for i, label := range issue.Labels {
+			names[i] = label.Name
+		}
+		return names
+	case Assignee:
+		if len(issue.Assignees) == 0 {
+			return []string{noAssignee}
+		}
+		names := make([]string, len(issue.Assignees))
+		for i, user := range issue.Assignees {
+			names[i] = user.Login
+		}
+		return names
+	case Milestone:
+		if issue.Milestone == nil || issue.Milestone.Title == "" {
+			return []string{noMilestone}
+		}
+		return []string{issue.Milestone.Title}
+	case Age:
+		return []string{ageBucket(issue.CreatedAt, now)}
+	}
+	return []string{""}
+}
+
+// Names of the groups for issues without a label, assignee or milestone
+const (
+	noLabel     = "No label"
+	noAssignee  = "Unassigned"
+	noMilestone = "No milestone"
+)
+
+// ageBucket returns Today for issues created since midnight, This week for
+// the last 7 days, or Older
+func ageBucket(created time.Time, now time.Time) string {
+	created = created.In(now.Location())
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch {
+	case !created.Before(midnight):
+		return Today
+	case now.Sub(created) < 7*24*time.Hour:
+		return ThisWeek
+	}
+	return Older
+}
+
+// compare orders age buckets newest first, and other groups by name, with
+// the group of issues missing the field last
+func compare(field Field, a, b string) int {
+	if field == Age {
+		order := []string{Today, ThisWeek, Older}
+		return cmp.Compare(slices.Index(order, a), slices.Index(order, b))
+	}
+
+	missing := func(name string) bool {
+		return name == noLabel || name == noAssignee || name == noMilestone
+	}
+	if c := cmp.Compare(boolInt(missing(a)), boolInt(missing(b))); c != 0 {
+		return c
+	}
+	return cmp.Compare(strings.ToLower(a), strings.ToLower(b))
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,122 @@
+package grouping
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/shaunmolloy/bugbox/internal/types"
+)
+
+func names(groups []Group) []string {
+	out := make([]string, len(groups))
+	for i, group := range groups {
+		out[i] = group.Name
+	}
+	return out
+}
+
+func TestParse(t *testing.T) {
+	t.Run("returns the field", func(t *testing.T) {
+		for _, field := range Fields {
+			got, err := Parse(string(field))
+			if err != nil {
+				t.Fatalf("expected nil, got error: %v", err)
+			}
+			if got != field {
+				t.Errorf("expected %q, got %q", field, got)
+			}
+		}
+	})
+
+	t.Run("returns an error for an unknown field", func(t *testing.T) {
+		if _, err := Parse("colour"); err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+}
+
+func TestNext(t *testing.T) {
+	t.Run("cycles back to none", func(t *testing.T) {
+		field := None
+		for range Fields {
+			field = field.Next()
+		}
+		if field != None {
+			t.Errorf("expected none, got %q", field)
+		}
+	})
+}
+
+func TestGroups(t *testing.T) {
+	now := time.Date(2030, 1, 10, 12, 0, 0, 0, time.UTC)
+	issues := []types.Issue{
+		{ID: 1, Org: "acme", Repo: "web", CreatedAt: now.Add(-time.Hour), Labels: []types.Label{{Name: "bug"}, {Name: "ui"}}},
+		{ID: 2, Org: "Beta", Repo: "api", CreatedAt: now.Add(-13 * time.Hour), Assignees: []types.User{{Login: "alice"}}},
+		{ID: 3, Org: "acme", Repo: "api", CreatedAt: now.Add(-30 * 24 * time.Hour), Labels: []types.Label{{Name: "Bug"}}, Milestone: &types.Milestone{Title: "v1"}},
+	}
+
+	tests := []struct {
+		field Field
+		want  []string
+	}{
+		{None, []string{""}},
+		{Org, []string{"acme", "Beta"}},
+		{Repo, []string{"acme/api", "acme/web", "Beta/api"}},
+		{Label, []string{"bug", "ui", "No label"}},
+		{Assignee, []string{"alice", "Unassigned"}},
+		{Milestone, []string{"v1", "No milestone"}},
+		{Age, []string{Today, ThisWeek, Older}},
+	}
+
+	for _, test := range tests {
+		t.Run("groups by "+string(test.field), func(t *testing.T) {
+			if got := names(Groups(issues, test.field, now)); !slices.Equal(got, test.want) {
+				t.Errorf("expected %v, got %v", test.want, got)
+			}
+		})
+	}
+
+	t.Run("keeps issues in order within a group", func(t *testing.T) {
+		groups := Groups(issues, Org, now)
+		if !slices.Equal(groups[0].Indexes, []int{0, 2}) {
+			t.Errorf("expected [0 2], got %v", groups[0].Indexes)
+		}
+	})
+
+	t.Run("puts issues in each of their labels", func(t *testing.T) {
+		groups := Groups(issues, Label, now)
+		if !slices.Equal(groups[0].Indexes, []int{0, 2}) || !slices.Equal(groups[1].Indexes, []int{0}) {
+			t.Errorf("expected issue 1 under bug and ui, got %+v", groups)
+		}
+	})
+
+	t.Run("lists an issue once under labels differing only in case", func(t *testing.T) {
+		labelled := []types.Issue{{ID: 1, Labels: []types.Label{{Name: "bug"}, {Name: "Bug"}}}}
+		groups := Groups(labelled, Label, now)
+		if len(groups) != 1 || !slices.Equal(groups[0].Indexes, []int{0}) {
+			t.Errorf("expected [0] under bug, got %+v", groups)
+		}
+	})
+}
+
+func TestAgeBucket(t *testing.T) {
+	now := time.Date(2030, 1, 10, 0, 30, 0, 0, time.UTC)
+	tests := []struct {
+		created time.Time
+		want    string
+	}{
+		{now.Add(-10 * time.Minute), Today},
+		{now.Add(-time.Hour), ThisWeek},
+		{now.Add(-6 * 24 * time.Hour), ThisWeek},
+		{now.Add(-8 * 24 * time.Hour), Older},
+	}
+
+	for _, test := range tests {
+		t.Run("returns "+test.want, func(t *testing.T) {
+			if got := ageBucket(test.created, now); got != test.want {
+				t.Errorf("expected %q, got %q", test.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,189 @@
+package hooks
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shaunmolloy/bugbox/internal/events"
+	"github.com/shaunmolloy/bugbox/internal/logging"
+	"github.com/shaunmolloy/bugbox/internal/status"
+	"github.com/shaunmolloy/bugbox/internal/storage/config"
+	"github.com/shaunmolloy/bugbox/internal/types"
+)
+
+// commandTimeout stops a stuck hook command from holding up later ones
+const commandTimeout = 30 * time.Second
+
+// Runner fires the configured hooks as new issues arrive
+type Runner struct {
+	mu       sync.Mutex
+	limiters map[string]*limiter
+	now      func() time.Time
+	// command and notify are vars so tests can record calls
+	command func(ctx context.Context, name string, command string, stdin []byte) error
+	notify  func(title string, body string) error
+}
+
+func New() *Runner {
+	return &Runner{
+		limiters: make(map[string]*limiter),
+		now:      time.Now,
+		command:  runCommand,
+		notify:   notify,
+	}
+}
+
+// Start fires hooks for new issues published on bus until ctx is cancelled
+func (r *Runner) Start(ctx context.Context, bus *events.Bus) {
+	// Queued rather than subscribed, so a burst of new issues isn't dropped
+	// while a slow hook runs
+	updates := bus.Queue(ctx, events.NewIssues)
+	go func() {
+		for event := range updates {
+			// Reload so hook changes apply without a restart
+			conf, _ := config.LoadConfig()
+			r.Fire(ctx, conf.Hooks, event.Issues)
+		}
+	}()
+}
+
+// Fire runs each hook for the issues matching its filter, within its rate limit
+func (r *Runner) Fire(ctx context.Context, hooks []config.HookConfig, issues []types.Issue) {
+	for i, hook := range hooks {
+		name := cmp.Or(hook.Name, fmt.Sprintf("hook %d", i+1))
+		limit, period := hook.Limit()
+		key := limitKey(hook)
+
+		suppressed := 0
+		for _, issue := range issues {
+			if !hook.Filter.Match(issue) {
+				continue
+			}
+			if !r.limiter(key).allow(r.now(), limit, period) {
+				suppressed++
+				continue
+			}
+			r.fire(ctx, name, hook, issue)
+		}
+
+		if suppressed == 0 {
+			continue
+		}
+		logging.Info(fmt.Sprintf("Rate limited %s, skipped %d issue(s)", name, suppressed))
+		// One summary instead of a flood, e.g. after adding an org
+		if hook.Notify {
+			if err := r.notify("bugbox", fmt.Sprintf("%d more new issue(s) for %s", suppressed, name)); err != nil {
+				r.report(name, err)
+			}
+		}
+	}
+}
+
+func (r *Runner) fire(ctx context.Context, name string, hook config.HookConfig, issue types.Issue) {
+	logging.Info(fmt.Sprintf("Running %s for %s/%s#%d", name, issue.Org, issue.Repo, issue.ID))
+
+	if hook.Command != "" {
+		data, err := json.Marshal(issue)
+		if err == nil {
+			err = r.command(ctx, name, hook.Command, data)
+		}
+		if err != nil {
+			r.report(name, err)
+		}
+	}
+
+	if hook.Notify {
+		title := fmt.Sprintf("%s/%s#%d", issue.Org, issue.Repo, issue.ID)
+		if err := r.notify(title, issue.Title); err != nil {
+			r.report(name, err)
+		}
+	}
+}
+
+// report logs a failed hook and shows it in the TUI's error panel
+func (r *Runner) report(name string, err error) {
+	logging.Error(fmt.Sprintf("Hook %s failed: %v", name, err))
+	status.Sync.Error("", fmt.Errorf("%s: %w", name, err))
+}
+
+func (r *Runner) limiter(key string) *limiter {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.limiters[key]; !ok {
+		r.limiters[key] = &limiter{}
+	}
+	return r.limiters[key]
+}
+
+// limitKey identifies a hook by its command and filter rather than its
+// position, so reordering or adding hooks doesn't move limits between them
+func limitKey(hook config.HookConfig) string {
+	filter, _ := json.Marshal(hook.Filter)
+	return fmt.Sprintf("%s|%t|%s", hook.Command, hook.Notify, filter)
+}
+
+// limiter allows up to a limit of events in any sliding period
+type limiter struct {
+	times []time.Time
+}
+
+func (l *limiter) allow(now time.Time, limit int, period time.Duration) bool {
+	// Forget events that have left the window
+	kept := l.times[:0]
+	for _, t := range l.times {
+		if now.Sub(t) < period {
+			kept = append(kept, t)
+		}
+	}
+	l.times = kept
+
+	if len(l.times) >= limit {
+		return false
+	}
+	l.times = append(l.times, now)
+	return true
+}
+
+// runCommand runs command with sh -c, passing stdin and the hook name
+func runCommand(ctx context.Context, name string, command string, stdin []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, commandTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Stdin = bytes.NewReader(stdin)
+	cmd.Env = append(os.Environ(), "BUGBOX_HOOK="+name)
+
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// notify shows a desktop notification
+func notify(title string, body string) error {
+	var cmd *exec.Cmd
+
+	switch runtime.GOOS {
+	case "linux":
+		cmd = exec.Command("notify-send", "--app-name=bugbox", title, body)
+	case "darwin":
+		quote := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+		script := fmt.Sprintf(`display notification "%s" with title "%s"`, quote.Replace(body), quote.Replace(title))
+		cmd = exec.Command("osascript", "-e", script)
+	default:
+		return fmt.Errorf("notifications are unsupported on %s", runtime.GOOS)
+	}
+
+	return cmd.Run()
+}
@@ -0,0 +1,193 @@
+package hooks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shaunmolloy/bugbox/internal/events"
+	"github.com/shaunmolloy/bugbox/internal/storage/config"
+	"github.com/shaunmolloy/bugbox/internal/types"
+)
+
+type commandCall struct {
+	name  string
+	issue types.Issue
+}
+
+// newTestRunner records commands and notifications instead of running them
+func newTestRunner(now *time.Time) (*Runner, *[]commandCall, *[]string) {
+	var commands []commandCall
+	var notifications []string
+
+	r := New()
+	r.now = func() time.Time { return *now }
+	r.command = func(ctx context.Context, name string, command string, stdin []byte) error {
+		var issue types.Issue
+		if err := json.Unmarshal(stdin, &issue); err != nil {
+			return err
+		}
+		commands = append(commands, commandCall{name, issue})
+		return nil
+	}
+	r.notify = func(title string, body string) error {
+		notifications = append(notifications, title+": "+body)
+		return nil
+	}
+	return r, &commands, &notifications
+}
+
+func TestRunnerFire(t *testing.T) {
+	issues := []types.Issue{
+		{ID: 1, Org: "example", Repo: "api", Title: "Outage", Labels: []types.Label{{Name: "P0"}}},
+		{ID: 2, Org: "example", Repo: "api", Title: "Typo"},
+		{ID: 3, Org: "other", Repo: "api", Title: "Outage", Labels: []types.Label{{Name: "P0"}}},
+	}
+
+	t.Run("runs commands for matching issues with the issue on stdin", func(t *testing.T) {
+		now := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+		r, commands, _ := newTestRunner(&now)
+
+		r.Fire(context.Background(), []config.HookConfig{{
+			Name:    "pager",
+			Filter:  config.IssueFilter{Orgs: []string{"example"}, Labels: []string{"P0"}},
+			Command: "page-me",
+		}}, issues)
+
+		if len(*commands) != 1 || (*commands)[0].issue.ID != 1 || (*commands)[0].name != "pager" {
+			t.Errorf("unexpected commands %+v", *commands)
+		}
+	})
+
+	t.Run("sends a notification for each matching issue", func(t *testing.T) {
+		now := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+		r, _, notifications := newTestRunner(&now)
+
+		r.Fire(context.Background(), []config.HookConfig{{
+			Filter: config.IssueFilter{Labels: []string{"P0"}},
+			Notify: true,
+		}}, issues)
+
+		want := []string{"example/api#1: Outage", "other/api#3: Outage"}
+		if len(*notifications) != 2 || (*notifications)[0] != want[0] || (*notifications)[1] != want[1] {
+			t.Errorf("got %v, want %v", *notifications, want)
+		}
+	})
+
+	t.Run("rate limits and summarises the rest", func(t *testing.T) {
+		now := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+		r, _, notifications := newTestRunner(&now)
+		hooks := []config.HookConfig{{Name: "all", Notify: true, RateLimit: 2, RatePeriod: config.Duration(time.Minute)}}
+
+		r.Fire(context.Background(), hooks, issues)
+		want := []string{"example/api#1: Outage", "example/api#2: Typo", "bugbox: 1 more new issue(s) for all"}
+		if len(*notifications) != 3 || (*notifications)[2] != want[2] {
+			t.Fatalf("got %v, want %v", *notifications, want)
+		}
+
+		// The window slides, allowing more once the period has passed
+		now = now.Add(time.Minute)
+		r.Fire(context.Background(), hooks, issues[:1])
+		if len(*notifications) != 4 {
+			t.Errorf("expected the limit to reset, got %v", *notifications)
+		}
+	})
+
+	t.Run("continues after a failing command", func(t *testing.T) {
+		now := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+		r, _, _ := newTestRunner(&now)
+		calls := 0
+		r.command = func(ctx context.Context, name string, command string, stdin []byte) error {
+			calls++
+			return errors.New("exit status 1")
+		}
+
+		r.Fire(context.Background(), []config.HookConfig{{Command: "false"}}, issues)
+		if calls != 3 {
+			t.Errorf("got %d calls, want 3", calls)
+		}
+	})
+
+	t.Run("keeps rate limits when hooks are reordered", func(t *testing.T) {
+		now := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+		r, commands, _ := newTestRunner(&now)
+		pager := config.HookConfig{Command: "page-me", RateLimit: 1, RatePeriod: config.Duration(time.Minute)}
+		logger := config.HookConfig{Command: "log-it", RateLimit: 1, RatePeriod: config.Duration(time.Minute)}
+
+		r.Fire(context.Background(), []config.HookConfig{pager}, issues[:1])
+		r.Fire(context.Background(), []config.HookConfig{logger, pager}, issues[1:2])
+
+		// The pager already used its limit as the first hook
+		if len(*commands) != 2 || (*commands)[1].name != "hook 1" {
+			t.Errorf("unexpected commands %+v", *commands)
+		}
+	})
+}
+
+func TestRunnerStart(t *testing.T) {
+	t.Run("runs hooks for every event published while a hook is slow", func(t *testing.T) {
+		config.ConfigPath = filepath.Join(t.TempDir(), "config.json")
+		config.SaveConfig(config.Config{Hooks: []config.HookConfig{{Command: "slow", RateLimit: 100}}})
+
+		var mu sync.Mutex
+		var ids []int
+		release := make(chan struct{})
+		r := New()
+		r.command = func(ctx context.Context, name string, command string, stdin []byte) error {
+			<-release
+			var issue types.Issue
+			if err := json.Unmarshal(stdin, &issue); err != nil {
+				return err
+			}
+			mu.Lock()
+			ids = append(ids, issue.ID)
+			mu.Unlock()
+			return nil
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		bus := events.NewBus()
+		r.Start(ctx, bus)
+
+		total := 50
+		for i := 1; i <= total; i++ {
+			bus.Publish(events.Event{Type: events.NewIssues, Issues: []types.Issue{{ID: i, Org: "example", Repo: "api"}}})
+		}
+		close(release)
+
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			mu.Lock()
+			got := len(ids)
+			mu.Unlock()
+			if got == total {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("got %d hook runs, want %d", got, total)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	})
+}
+
+func TestRunCommand(t *testing.T) {
+	t.Run("passes stdin and the hook name", func(t *testing.T) {
+		err := runCommand(context.Background(), "pager", `[ "$(cat)" = "hello" ] && [ "$BUGBOX_HOOK" = "pager" ]`, []byte("hello"))
+		if err != nil {
+			t.Fatalf("expected nil, got error: %v", err)
+		}
+	})
+
+	t.Run("returns output on failure", func(t *testing.T) {
+		err := runCommand(context.Background(), "pager", "echo oops >&2; exit 1", nil)
+		if err == nil || err.Error() != "exit status 1: oops" {
+			t.Errorf("got %v, want exit status 1: oops", err)
+		}
+	})
+}
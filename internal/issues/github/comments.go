@@ -0,0 +1,55 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/shaunmolloy/bugbox/internal/credentials"
+	"github.com/shaunmolloy/bugbox/internal/issues"
+	"github.com/shaunmolloy/bugbox/internal/logging"
+	"github.com/shaunmolloy/bugbox/internal/storage/config"
+	"github.com/shaunmolloy/bugbox/internal/types"
+)
+
+// FetchComments fetches the bodies of an issue's first page of comments
+func FetchComments(ctx context.Context, issue types.Issue, client issues.HttpClient) ([]string, error) {
+	conf, _ := config.LoadConfig()
+
+	token, err := credentials.GitHubToken(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	api := fmt.Sprintf("%s/repos/%s/%s/issues/%d/comments?per_page=%d", baseURL, issue.Org, issue.Repo, issue.ID, perPage)
+	logging.Debug(fmt.Sprintf("Fetching %s", api))
+	req, err := newRequest("GET", api, token)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GitHub API error: %s", resp.Status)
+	}
+
+	var result []struct {
+		Body string `json:"body"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+
+	comments := make([]string, len(result))
+	for i, comment := range result {
+		comments[i] = comment.Body
+	}
+	return comments, nil
+}
@@ -0,0 +1,65 @@
+package github
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/shaunmolloy/bugbox/internal/issues"
+	"github.com/shaunmolloy/bugbox/internal/storage/config"
+	"github.com/shaunmolloy/bugbox/internal/types"
+)
+
+func TestFetchComments(t *testing.T) {
+	issue := types.Issue{ID: 7, Org: "example", Repo: "api"}
+
+	t.Run("returns comment bodies", func(t *testing.T) {
+		config.ConfigPath = filepath.Join(t.TempDir(), "config.json")
+		config.SaveConfig(config.Config{GitHubToken: "example", Orgs: []string{"example"}})
+
+		var path string
+		client := &issues.ClientMock{
+			DoFunc: func(req *http.Request) (*http.Response, error) {
+				path = req.URL.Path
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(strings.NewReader(`[{"body": "Same here"}, {"body": "Fixed on main"}]`)),
+				}, nil
+			},
+		}
+
+		comments, err := FetchComments(context.Background(), issue, client)
+		if err != nil {
+			t.Fatal("expected nil, got error")
+		}
+		if path != "/repos/example/api/issues/7/comments" {
+			t.Errorf("unexpected path %q", path)
+		}
+		if !slices.Equal(comments, []string{"Same here", "Fixed on main"}) {
+			t.Errorf("got %q", comments)
+		}
+	})
+
+	t.Run("returns error for a failed response", func(t *testing.T) {
+		config.ConfigPath = filepath.Join(t.TempDir(), "config.json")
+		config.SaveConfig(config.Config{GitHubToken: "example", Orgs: []string{"example"}})
+
+		client := &issues.ClientMock{
+			DoFunc: func(req *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: http.StatusForbidden,
+					Status:     "403 Forbidden",
+					Body:       io.NopCloser(strings.NewReader(`{}`)),
+				}, nil
+			},
+		}
+
+		if _, err := FetchComments(context.Background(), issue, client); err == nil {
+			t.Fatal("expected error, got nil")
+		}
+	})
+}
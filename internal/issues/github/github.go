@@ -1,127 +1,290 @@
 package github
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
+	"slices"
 
+	"github.com/shaunmolloy/bugbox/internal/credentials"
+	"github.com/shaunmolloy/bugbox/internal/events"
 	"github.com/shaunmolloy/bugbox/internal/issues"
 	"github.com/shaunmolloy/bugbox/internal/logging"
 	"github.com/shaunmolloy/bugbox/internal/storage/config"
 	"github.com/shaunmolloy/bugbox/internal/types"
 )
 
-const baseURL = "https://api.github.com"
+// Provider names GitHub in per-provider config, e.g. polling schedules
+const Provider = "github"
 
-func FetchAllIssues(fetchAll bool, client issues.HttpClient) error {
+const (
+	perPage  = 100
+	maxPages = 10
+)
+
+// baseURL is a var so tests can point it at a stub server
+var baseURL = "https://api.github.com"
+
+// FetchAllIssues fetches issues for every org in config
+func FetchAllIssues(ctx context.Context, fetchAll bool, client issues.HttpClient) error {
 	conf, _ := config.LoadConfig()
+	return FetchIssuesForOrgs(ctx, conf.Orgs, fetchAll, client, nil)
+}
 
-	// Load existing issues
-	issuesConf, err := config.LoadIssues()
-	if err != nil {
-		issuesConf = config.Issues{} // Initialize an empty Issues map
-		logging.Error(fmt.Sprintf("Error loading existing issues: %v", err))
+// FetchIssuesForOrgs fetches issues for orgs concurrently and merges them
+// into issues.json. Orgs that fail are skipped and returned as FetchErrors.
+// Nothing is saved if ctx is cancelled while fetching.
+func FetchIssuesForOrgs(ctx context.Context, orgs []string, fetchAll bool, client issues.HttpClient, progress Progress) error {
+	conf, _ := config.LoadConfig()
+
+	// Results are indexed by org so merging is deterministic
+	results := make([][]types.Issue, len(orgs))
+	errs := make([]error, len(orgs))
+
+	// Orgs and their pages share one limit on requests in flight
+	limit := newRequestLimit(conf.FetchWorkers())
+
+	forEach(len(orgs), conf.FetchWorkers(), func(i int) {
+		if progress != nil {
+			progress.OrgStarted(orgs[i])
+		}
+		results[i], errs[i] = fetchIssues(ctx, orgs[i], fetchAll, client, limit)
+		if progress != nil && ctx.Err() == nil {
+			progress.OrgFinished(orgs[i], errs[i])
+		}
+	})
+
+	if ctx.Err() != nil {
+		return ctx.Err()
 	}
 
-	for _, org := range conf.Orgs {
-		issues, err := FetchIssues(org, fetchAll, client)
-		if err != nil {
-			logging.Error(fmt.Sprintf("Error fetching issues for org %s: %v", org, err))
+	var fetched []types.Issue
+	var fetchErrs FetchErrors
+	for i, org := range orgs {
+		if errs[i] != nil {
+			logging.Error(fmt.Sprintf("Error fetching issues for org %s: %v", org, errs[i]))
+			fetchErrs = append(fetchErrs, OrgError{Org: org, Err: errs[i]})
 			continue
 		}
+		fetched = append(fetched, results[i]...)
+	}
 
-		// Process and store issues by org/repo/number
-		for _, issue := range issues {
-			issue.Repo = parseRepo(issue.URL)
-
-			// Ensure maps exist for this org and repo
-			if _, ok := issuesConf[issue.Org]; !ok {
-				issuesConf[issue.Org] = make(map[string]map[int]types.Issue)
-			}
-			if _, ok := issuesConf[issue.Org][issue.Repo]; !ok {
-				issuesConf[issue.Org][issue.Repo] = make(map[int]types.Issue)
+	if err := ApplyIssues(fetched); err != nil {
+		return err
+	}
+	if progress != nil {
+		for i, org := range orgs {
+			if errs[i] == nil {
+				progress.OrgSynced(org)
 			}
+		}
+	}
 
-			// Check if issue already exists to preserve Read status
-			if existingIssue, exists := issuesConf[issue.Org][issue.Repo][issue.ID]; exists {
-				issue.Read = existingIssue.Read
-			}
+	if len(fetchErrs) > 0 {
+		return fetchErrs
+	}
+	return nil
+}
+
+// ApplyIssues merges issues into issues.json, letting hooks, notifiers and
+// the TUI know what changed
+func ApplyIssues(fetched []types.Issue) error {
+	var added, changed []types.Issue
+	err := config.UpdateIssues(func(issuesConf config.Issues) error {
+		added, changed = mergeIssues(issuesConf, fetched)
+		return nil
+	})
+	if err != nil {
+		logging.Error(fmt.Sprintf("Error saving issues: %v", err))
+		return err
+	}
 
-			// Remove issue from conf if state is closed
-			if issue.State == types.StateClosed {
-				delete(issuesConf[issue.Org][issue.Repo], issue.ID)
-				if len(issuesConf[issue.Org][issue.Repo]) == 0 {
-					delete(issuesConf[issue.Org], issue.Repo)
-				}
+	// Let the search index pick up bodies, which aren't stored with issues
+	events.Default.Publish(events.Event{Type: events.IssuesFetched, Issues: fetched})
+
+	// Let hooks know about issues they haven't seen before
+	if len(added) > 0 {
+		logging.Info(fmt.Sprintf("Found %d new issue(s)", len(added)))
+		events.Default.Publish(events.Event{Type: events.NewIssues, Issues: added})
+	}
+	if len(changed) > 0 {
+		events.Default.Publish(events.Event{Type: events.ChangedIssues, Issues: changed})
+	}
+
+	// Let the TUI, or daemon subscribers, refresh with the updated issues
+	events.Default.Publish(events.Event{Type: events.IssuesUpdated})
+	return nil
+}
+
+// mergeIssues stores fetched issues by org/repo/number, dropping closed ones
+// and any older than the stored issue.
+// It returns the open issues not stored before, except for orgs fetched for
+// the first time, whose issues are all new, and stored issues that changed.
+func mergeIssues(issuesConf config.Issues, fetched []types.Issue) (added []types.Issue, changed []types.Issue) {
+	known := make(map[string]bool, len(issuesConf))
+	for org := range issuesConf {
+		known[org] = true
+	}
+
+	for _, issue := range fetched {
+		issue.Repo = parseRepo(issue.URL)
+		issue.Body = ""
+
+		// Ensure maps exist for this org and repo
+		if _, ok := issuesConf[issue.Org]; !ok {
+			issuesConf[issue.Org] = make(map[string]map[int]types.Issue)
+		}
+		if _, ok := issuesConf[issue.Org][issue.Repo]; !ok {
+			issuesConf[issue.Org][issue.Repo] = make(map[int]types.Issue)
+		}
+
+		// Check if issue already exists to preserve Read status
+		existingIssue, exists := issuesConf[issue.Org][issue.Repo][issue.ID]
+		if exists {
+			// Skip data older than stored, e.g. a delayed webhook delivery
+			if existingIssue.UpdatedAt.After(issue.UpdatedAt) {
 				continue
 			}
+			issue.Read = existingIssue.Read
+		}
+
+		// Remove issue from conf if state is closed
+		if issue.State == types.StateClosed {
+			if exists {
+				changed = append(changed, issue)
+			}
+			delete(issuesConf[issue.Org][issue.Repo], issue.ID)
+			if len(issuesConf[issue.Org][issue.Repo]) == 0 {
+				delete(issuesConf[issue.Org], issue.Repo)
+			}
+			continue
+		}
+
+		// Store the issue in the hierarchical structure
+		issuesConf[issue.Org][issue.Repo][issue.ID] = issue
 
-			// Store the issue in the hierarchical structure
-			issuesConf[issue.Org][issue.Repo][issue.ID] = issue
+		switch {
+		case !exists && known[issue.Org]:
+			added = append(added, issue)
+		case exists && issueChanged(existingIssue, issue):
+			changed = append(changed, issue)
 		}
 	}
+	return added, changed
+}
 
-	if err := config.SaveIssues(issuesConf); err != nil {
-		logging.Error(fmt.Sprintf("Error saving issues: %v", err))
-		return err
-	}
+// issueChanged returns true if fields worth notifying about differ
+func issueChanged(before types.Issue, after types.Issue) bool {
+	return before.Title != after.Title ||
+		!slices.EqualFunc(before.Labels, after.Labels, func(a, b types.Label) bool {
+			return a.Name == b.Name
+		})
+}
 
-	return nil
+// FetchIssues searches an org's issues, newest first. With fetchAll, every
+// page is fetched, concurrently once the first page reports the total.
+func FetchIssues(ctx context.Context, owner string, fetchAll bool, client issues.HttpClient) ([]types.Issue, error) {
+	conf, _ := config.LoadConfig()
+	return fetchIssues(ctx, owner, fetchAll, client, newRequestLimit(conf.FetchWorkers()))
 }
 
-func FetchIssues(owner string, fetchAll bool, client issues.HttpClient) ([]types.Issue, error) {
+// fetchIssues is FetchIssues with requests counted against limit
+func fetchIssues(ctx context.Context, owner string, fetchAll bool, client issues.HttpClient, limit requestLimit) ([]types.Issue, error) {
 	logging.Info(fmt.Sprintf("Searching GitHub issues in org: %s", owner))
 	conf, _ := config.LoadConfig()
 
+	token, err := credentials.GitHubToken(conf)
+	if err != nil {
+		return nil, err
+	}
+
 	query := fmt.Sprintf("org:%s is:issue sort:created-desc", owner)
-	encodedQuery := url.QueryEscape(query)
+	page := func(n int) (result IssueResponse, err error) {
+		err = limit.do(ctx, func() error {
+			result, err = fetchPage(ctx, query, n, token, client)
+			return err
+		})
+		return result, err
+	}
 
-	page := 1
-	var allIssues []types.Issue
+	first, err := page(1)
+	if err != nil {
+		return nil, err
+	}
 
-	for {
-		api := fmt.Sprintf("%s/search/issues?q=%s&per_page=100&page=%d", baseURL, encodedQuery, page)
-		logging.Debug(fmt.Sprintf("Fetching %s", api))
-		req, err := http.NewRequest("GET", api, nil)
-		if err != nil {
-			return nil, fmt.Errorf("creating request: %w", err)
-		}
+	pages := [][]types.Issue{first.Items}
+	if fetchAll && len(first.Items) > 0 {
+		// The search API returns at most 1000 results
+		count := min((first.Count+perPage-1)/perPage, maxPages)
+		rest := make([][]types.Issue, max(count-1, 0))
+		errs := make([]error, len(rest))
 
-		req.Header.Set("Authorization", "token "+conf.GitHubToken)
-		req.Header.Set("Accept", "application/vnd.github.v3+json")
+		forEach(len(rest), conf.FetchWorkers(), func(i int) {
+			var result IssueResponse
+			result, errs[i] = page(i + 2)
+			rest[i] = result.Items
+		})
 
-		resp, err := client.Do(req)
-		if err != nil {
-			return nil, err
+		for _, err := range errs {
+			if err != nil {
+				return nil, err
+			}
 		}
-		defer resp.Body.Close()
+		pages = append(pages, rest...)
+	}
 
-		if resp.StatusCode != http.StatusOK {
-			return nil, fmt.Errorf("GitHub API error: %s", resp.Status)
+	var allIssues []types.Issue
+	for _, items := range pages {
+		// Set Repo & Org for each issue
+		for i := range items {
+			items[i].Org = owner
+			items[i].Repo = parseRepo(items[i].URL)
+			items[i].Read = false
 		}
+		allIssues = append(allIssues, items...)
+	}
 
-		var result IssueResponse
-		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-			return nil, err
-		}
+	logging.Info(fmt.Sprintf("Found %d issues in org: %s", len(allIssues), owner))
+	return allIssues, nil
+}
 
-		// Set Repo & Org for each issue
-		for i := range result.Items {
-			result.Items[i].Org = owner
-			result.Items[i].Repo = parseRepo(result.Items[i].URL)
-			result.Items[i].Read = false
-		}
+// fetchPage fetches one page of search results
+func fetchPage(ctx context.Context, query string, page int, token string, client issues.HttpClient) (IssueResponse, error) {
+	api := fmt.Sprintf("%s/search/issues?q=%s&per_page=%d&page=%d", baseURL, url.QueryEscape(query), perPage, page)
+	logging.Debug(fmt.Sprintf("Fetching %s", api))
+	req, err := newRequest("GET", api, token)
+	if err != nil {
+		return IssueResponse{}, err
+	}
+	req = req.WithContext(ctx)
 
-		allIssues = append(allIssues, result.Items...)
+	resp, err := client.Do(req)
+	if err != nil {
+		return IssueResponse{}, err
+	}
+	defer resp.Body.Close()
 
-		if !fetchAll || len(result.Items) == 0 || page == 10 {
-			break
-		}
+	if resp.StatusCode != http.StatusOK {
+		return IssueResponse{}, fmt.Errorf("GitHub API error: %s", resp.Status)
+	}
+
+	var result IssueResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return IssueResponse{}, err
+	}
+	return result, nil
+}
 
-		page++
+// newRequest creates an authenticated GitHub API request
+func newRequest(method string, api string, token string) (*http.Request, error) {
+	req, err := http.NewRequest(method, api, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
 	}
 
-	logging.Info(fmt.Sprintf("Found %d issues in org: %s", len(allIssues), owner))
-	return allIssues, nil
+	req.Header.Set("Authorization", "token "+token)
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	return req, nil
 }
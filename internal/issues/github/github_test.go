@@ -1,17 +1,29 @@
 package github
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/shaunmolloy/bugbox/internal/issues"
+	"github.com/shaunmolloy/bugbox/internal/storage/config"
+	"github.com/shaunmolloy/bugbox/internal/types"
 )
 
 func TestFetchAllIssues(t *testing.T) {
 	t.Run("returns nil when fetching all issues", func(t *testing.T) {
 		fetchAll := true
+		config.IssuesPath = filepath.Join(t.TempDir(), "issues.json")
 
 		client := &issues.ClientMock{
 			DoFunc: func(req *http.Request) (*http.Response, error) {
@@ -22,7 +34,7 @@ func TestFetchAllIssues(t *testing.T) {
 			},
 		}
 
-		if err := FetchAllIssues(fetchAll, client); err != nil {
+		if err := FetchAllIssues(context.Background(), fetchAll, client); err != nil {
 			t.Fatal("expected nil, got error")
 		}
 	})
@@ -32,6 +44,8 @@ func TestFetchIssues(t *testing.T) {
 	t.Run("returns nil when fetching issues", func(t *testing.T) {
 		owner := "example"
 		fetchAll := false
+		config.ConfigPath = filepath.Join(t.TempDir(), "config.json")
+		config.SaveConfig(config.Config{GitHubToken: "example", Orgs: []string{owner}})
 
 		client := &issues.ClientMock{
 			DoFunc: func(req *http.Request) (*http.Response, error) {
@@ -42,8 +56,278 @@ func TestFetchIssues(t *testing.T) {
 			},
 		}
 
-		if _, err := FetchIssues(owner, fetchAll, client); err != nil {
+		if _, err := FetchIssues(context.Background(), owner, fetchAll, client); err != nil {
 			t.Fatal("expected nil, got error")
 		}
 	})
 }
+
+func TestFetchIssuesPages(t *testing.T) {
+	t.Run("returns every page in order when fetching all", func(t *testing.T) {
+		config.ConfigPath = filepath.Join(t.TempDir(), "config.json")
+		config.SaveConfig(config.Config{GitHubToken: "example", Workers: 3})
+
+		client := &issues.ClientMock{
+			DoFunc: func(req *http.Request) (*http.Response, error) {
+				page := req.URL.Query().Get("page")
+				body := fmt.Sprintf(`{"total_count": 250, "items": [{"number": %s, "html_url": "https://github.com/example/repo/issues/%s"}]}`, page, page)
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(strings.NewReader(body)),
+				}, nil
+			},
+		}
+
+		got, err := FetchIssues(context.Background(), "example", true, client)
+		if err != nil {
+			t.Fatalf("expected nil, got %v", err)
+		}
+
+		if len(got) != 3 {
+			t.Fatalf("expected 3 issues, got %d", len(got))
+		}
+		for i, issue := range got {
+			if issue.ID != i+1 || issue.Repo != "repo" || issue.Org != "example" {
+				t.Errorf("unexpected issue %+v at %d", issue, i)
+			}
+		}
+	})
+}
+
+func TestFetchIssuesForOrgs(t *testing.T) {
+	t.Run("saves successful orgs and returns failed ones", func(t *testing.T) {
+		dir := t.TempDir()
+		config.ConfigPath = filepath.Join(dir, "config.json")
+		config.IssuesPath = filepath.Join(dir, "issues.json")
+		config.SaveConfig(config.Config{GitHubToken: "example"})
+
+		client := &issues.ClientMock{
+			DoFunc: func(req *http.Request) (*http.Response, error) {
+				if strings.Contains(req.URL.Query().Get("q"), "org:broken") {
+					return &http.Response{
+						StatusCode: http.StatusForbidden,
+						Status:     "403 Forbidden",
+						Body:       io.NopCloser(strings.NewReader(`{}`)),
+					}, nil
+				}
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(strings.NewReader(`{"total_count": 1, "items": [{"number": 1, "html_url": "https://github.com/ok/repo/issues/1"}]}`)),
+				}, nil
+			},
+		}
+
+		err := FetchIssuesForOrgs(context.Background(), []string{"ok", "broken"}, false, client, nil)
+
+		var fetchErrs FetchErrors
+		if !errors.As(err, &fetchErrs) {
+			t.Fatalf("expected FetchErrors, got %v", err)
+		}
+		if len(fetchErrs) != 1 || fetchErrs[0].Org != "broken" {
+			t.Errorf("unexpected errors %v", fetchErrs)
+		}
+
+		saved, _ := config.LoadIssues()
+		if _, ok := saved["ok"]["repo"][1]; !ok {
+			t.Errorf("expected issue from ok org to be saved, got %v", saved)
+		}
+	})
+
+	t.Run("reports orgs as synced only once their issues are saved", func(t *testing.T) {
+		dir := t.TempDir()
+		config.ConfigPath = filepath.Join(dir, "config.json")
+		config.SaveConfig(config.Config{GitHubToken: "example"})
+
+		// A file where the issue store's directory should be makes saving fail
+		os.WriteFile(filepath.Join(dir, "blocked"), nil, 0600)
+		config.IssuesPath = filepath.Join(dir, "blocked", "issues.json")
+
+		client := &issues.ClientMock{
+			DoFunc: func(req *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(strings.NewReader(`{"total_count": 1, "items": [{"number": 1, "html_url": "https://github.com/ok/repo/issues/1"}]}`)),
+				}, nil
+			},
+		}
+
+		progress := &progressRecorder{}
+		if err := FetchIssuesForOrgs(context.Background(), []string{"ok"}, false, client, progress); err == nil {
+			t.Fatal("expected error, got nil")
+		}
+		if len(progress.finished) != 1 || len(progress.synced) != 0 {
+			t.Errorf("expected ok to finish without syncing, got %+v", progress)
+		}
+
+		config.IssuesPath = filepath.Join(dir, "issues.json")
+		if err := FetchIssuesForOrgs(context.Background(), []string{"ok"}, false, client, progress); err != nil {
+			t.Fatalf("expected nil, got %v", err)
+		}
+		if !slices.Equal(progress.synced, []string{"ok"}) {
+			t.Errorf("expected ok to be synced, got %+v", progress)
+		}
+	})
+
+	t.Run("runs at most workers requests at once across orgs and pages", func(t *testing.T) {
+		dir := t.TempDir()
+		config.ConfigPath = filepath.Join(dir, "config.json")
+		config.IssuesPath = filepath.Join(dir, "issues.json")
+		config.SaveConfig(config.Config{GitHubToken: "example", Workers: 2})
+
+		var running, peak atomic.Int32
+		client := &issues.ClientMock{
+			DoFunc: func(req *http.Request) (*http.Response, error) {
+				n := running.Add(1)
+				defer running.Add(-1)
+				for {
+					p := peak.Load()
+					if n <= p || peak.CompareAndSwap(p, n) {
+						break
+					}
+				}
+				time.Sleep(5 * time.Millisecond)
+
+				page := req.URL.Query().Get("page")
+				body := fmt.Sprintf(`{"total_count": 500, "items": [{"number": %s, "html_url": "https://github.com/example/repo/issues/%s"}]}`, page, page)
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(strings.NewReader(body)),
+				}, nil
+			},
+		}
+
+		if err := FetchIssuesForOrgs(context.Background(), []string{"a", "b", "c"}, true, client, nil); err != nil {
+			t.Fatalf("expected nil, got %v", err)
+		}
+		if peak.Load() > 2 {
+			t.Errorf("expected at most 2 concurrent requests, got %d", peak.Load())
+		}
+	})
+
+	t.Run("returns context error without saving when cancelled", func(t *testing.T) {
+		dir := t.TempDir()
+		config.ConfigPath = filepath.Join(dir, "config.json")
+		config.IssuesPath = filepath.Join(dir, "issues.json")
+		config.SaveConfig(config.Config{GitHubToken: "example"})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		client := &issues.ClientMock{
+			DoFunc: func(req *http.Request) (*http.Response, error) {
+				cancel()
+				return nil, req.Context().Err()
+			},
+		}
+
+		if err := FetchIssuesForOrgs(ctx, []string{"example"}, false, client, nil); !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected context.Canceled, got %v", err)
+		}
+		if exists, _ := config.IsExist(config.IssuesPath); exists {
+			t.Fatal("expected no issues file")
+		}
+	})
+}
+
+func TestMergeIssues(t *testing.T) {
+	t.Run("returns issues not stored before", func(t *testing.T) {
+		issuesConf := config.Issues{"example": {"repo": {1: types.Issue{ID: 1, Read: true}}}}
+		fetched := []types.Issue{
+			{ID: 1, Org: "example", URL: "https://github.com/example/repo/issues/1"},
+			{ID: 2, Org: "example", URL: "https://github.com/example/repo/issues/2"},
+		}
+
+		added, _ := mergeIssues(issuesConf, fetched)
+		if len(added) != 1 || added[0].ID != 2 {
+			t.Errorf("got %+v, want issue 2", added)
+		}
+		if !issuesConf["example"]["repo"][1].Read {
+			t.Error("expected read status to be preserved")
+		}
+	})
+
+	t.Run("returns none for an org fetched for the first time", func(t *testing.T) {
+		fetched := []types.Issue{
+			{ID: 1, Org: "example", URL: "https://github.com/example/repo/issues/1"},
+		}
+
+		if added, _ := mergeIssues(config.Issues{}, fetched); len(added) != 0 {
+			t.Errorf("expected no new issues, got %+v", added)
+		}
+	})
+
+	t.Run("returns none for closed issues", func(t *testing.T) {
+		issuesConf := config.Issues{"example": {}}
+		fetched := []types.Issue{
+			{ID: 1, Org: "example", URL: "https://github.com/example/repo/issues/1", State: types.StateClosed},
+		}
+
+		if added, _ := mergeIssues(issuesConf, fetched); len(added) != 0 {
+			t.Errorf("expected no new issues, got %+v", added)
+		}
+	})
+
+	t.Run("keeps stored issues newer than those fetched", func(t *testing.T) {
+		now := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+		issuesConf := config.Issues{"example": {"repo": {
+			1: types.Issue{ID: 1, Title: "Bug", UpdatedAt: now},
+		}}}
+		fetched := []types.Issue{
+			{ID: 1, Org: "example", URL: "https://github.com/example/repo/issues/1", Title: "Old", UpdatedAt: now.Add(-time.Minute)},
+		}
+
+		_, changed := mergeIssues(issuesConf, fetched)
+		if len(changed) != 0 || issuesConf["example"]["repo"][1].Title != "Bug" {
+			t.Errorf("expected the stored issue to be kept, got %+v", issuesConf["example"]["repo"][1])
+		}
+	})
+
+	t.Run("returns stored issues that were relabelled or closed", func(t *testing.T) {
+		issuesConf := config.Issues{"example": {"repo": {
+			1: types.Issue{ID: 1, Title: "Bug"},
+			2: types.Issue{ID: 2, Title: "Crash"},
+			3: types.Issue{ID: 3, Title: "Same"},
+		}}}
+		fetched := []types.Issue{
+			{ID: 1, Org: "example", Title: "Bug", URL: "https://github.com/example/repo/issues/1", Labels: []types.Label{{Name: "bug"}}},
+			{ID: 2, Org: "example", Title: "Crash", URL: "https://github.com/example/repo/issues/2", State: types.StateClosed},
+			{ID: 3, Org: "example", Title: "Same", URL: "https://github.com/example/repo/issues/3"},
+		}
+
+		_, changed := mergeIssues(issuesConf, fetched)
+		if len(changed) != 2 || changed[0].ID != 1 || changed[1].ID != 2 {
+			t.Errorf("got %+v, want issues 1 and 2", changed)
+		}
+	})
+
+	t.Run("doesn't store bodies", func(t *testing.T) {
+		issuesConf := config.Issues{"example": {}}
+		fetched := []types.Issue{
+			{ID: 1, Org: "example", URL: "https://github.com/example/repo/issues/1", Body: "Steps to reproduce"},
+		}
+
+		mergeIssues(issuesConf, fetched)
+		if body := issuesConf["example"]["repo"][1].Body; body != "" {
+			t.Errorf("expected no body, got %q", body)
+		}
+	})
+}
+
+// progressRecorder records the orgs reported to it
+type progressRecorder struct {
+	mu       sync.Mutex
+	finished []string
+	synced   []string
+}
+
+func (p *progressRecorder) OrgStarted(org string) {}
+
+func (p *progressRecorder) OrgFinished(org string, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.finished = append(p.finished, org)
+}
+
+func (p *progressRecorder) OrgSynced(org string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.synced = append(p.synced, org)
+}
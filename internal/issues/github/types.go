@@ -1,8 +1,75 @@
 package github
 
-import "github.com/shaunmolloy/bugbox/internal/types"
+import (
+	"strings"
+	"time"
+
+	"github.com/shaunmolloy/bugbox/internal/types"
+)
 
 type IssueResponse struct {
 	Count int           `json:"total_count"`
 	Items []types.Issue `json:"items"`
 }
+
+// User is the account a token belongs to
+type User struct {
+	Login string `json:"login"`
+	Name  string `json:"name"`
+}
+
+// TokenInfo describes what a token can do, from the /user response headers
+type TokenInfo struct {
+	User      User
+	Scopes    []string  // empty for fine-grained tokens
+	ExpiresAt time.Time // zero when the token doesn't expire
+}
+
+// Org is an organisation the token's user belongs to
+type Org struct {
+	Login string `json:"login"`
+	// SSORequired is set when the org enforces SAML SSO the token isn't authorised for
+	SSORequired bool   `json:"-"`
+	SSOURL      string `json:"-"`
+}
+
+// Progress is told as each org is fetched
+type Progress interface {
+	OrgStarted(org string)
+	OrgFinished(org string, err error)
+	// OrgSynced is called once an org's fetched issues are saved
+	OrgSynced(org string)
+}
+
+// OrgError is a failure fetching one org's issues
+type OrgError struct {
+	Org string
+	Err error
+}
+
+func (e OrgError) Error() string {
+	return e.Org + ": " + e.Err.Error()
+}
+
+func (e OrgError) Unwrap() error {
+	return e.Err
+}
+
+// FetchErrors collects the orgs that failed while others were fetched
+type FetchErrors []OrgError
+
+func (e FetchErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return "fetching issues: " + strings.Join(messages, "; ")
+}
+
+func (e FetchErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, err := range e {
+		errs[i] = err
+	}
+	return errs
+}
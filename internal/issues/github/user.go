@@ -0,0 +1,137 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/shaunmolloy/bugbox/internal/issues"
+	"github.com/shaunmolloy/bugbox/internal/logging"
+)
+
+// expiryLayouts are the formats GitHub uses for the token expiration header
+var expiryLayouts = []string{"2006-01-02 15:04:05 MST", "2006-01-02 15:04:05 -0700"}
+
+// VerifyToken calls /user to check the token works and reports its scopes and expiry
+func VerifyToken(token string, client issues.HttpClient) (TokenInfo, error) {
+	req, err := newRequest("GET", baseURL+"/user", token)
+	if err != nil {
+		return TokenInfo{}, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return TokenInfo{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return TokenInfo{}, fmt.Errorf("token was rejected by GitHub, it may be invalid or expired")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return TokenInfo{}, fmt.Errorf("GitHub API error: %s", resp.Status)
+	}
+
+	var info TokenInfo
+	if err := json.NewDecoder(resp.Body).Decode(&info.User); err != nil {
+		return TokenInfo{}, err
+	}
+
+	for _, scope := range strings.Split(resp.Header.Get("X-OAuth-Scopes"), ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			info.Scopes = append(info.Scopes, scope)
+		}
+	}
+
+	if expiry := resp.Header.Get("GitHub-Authentication-Token-Expiration"); expiry != "" {
+		for _, layout := range expiryLayouts {
+			if t, err := time.Parse(layout, expiry); err == nil {
+				info.ExpiresAt = t
+				break
+			}
+		}
+	}
+
+	return info, nil
+}
+
+// FetchOrgs lists the orgs the token's user belongs to, flagging orgs that
+// enforce SSO the token hasn't been authorised for
+func FetchOrgs(token string, client issues.HttpClient) ([]Org, error) {
+	var orgs []Org
+
+	for page := 1; page <= 10; page++ {
+		api := fmt.Sprintf("%s/user/orgs?per_page=100&page=%d", baseURL, page)
+		req, err := newRequest("GET", api, token)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, fmt.Errorf("GitHub API error: %s", resp.Status)
+		}
+
+		var result []Org
+		err = json.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		orgs = append(orgs, result...)
+		if len(result) < 100 {
+			break
+		}
+	}
+
+	for i := range orgs {
+		orgs[i].SSORequired, orgs[i].SSOURL = CheckSSO(orgs[i].Login, token, client)
+	}
+
+	return orgs, nil
+}
+
+// CheckSSO returns true, with the authorisation URL, when the org rejects the
+// token because it enforces SAML SSO
+func CheckSSO(org string, token string, client issues.HttpClient) (bool, string) {
+	req, err := newRequest("GET", fmt.Sprintf("%s/orgs/%s/repos?per_page=1", baseURL, org), token)
+	if err != nil {
+		return false, ""
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		logging.Error(fmt.Sprintf("Error checking SSO for org %s: %v", org, err))
+		return false, ""
+	}
+	defer resp.Body.Close()
+
+	return parseSSOHeader(resp)
+}
+
+// parseSSOHeader reads `X-GitHub-SSO: required; url=...` from a 403 response
+func parseSSOHeader(resp *http.Response) (bool, string) {
+	if resp.StatusCode != http.StatusForbidden {
+		return false, ""
+	}
+
+	header := resp.Header.Get("X-GitHub-SSO")
+	if !strings.HasPrefix(header, "required") {
+		return false, ""
+	}
+
+	for _, part := range strings.Split(header, ";") {
+		if url, ok := strings.CutPrefix(strings.TrimSpace(part), "url="); ok {
+			return true, url
+		}
+	}
+	return true, ""
+}
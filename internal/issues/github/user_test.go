@@ -0,0 +1,116 @@
+package github
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/shaunmolloy/bugbox/internal/issues"
+)
+
+func TestVerifyToken(t *testing.T) {
+	t.Run("returns user, scopes and expiry", func(t *testing.T) {
+		client := &issues.ClientMock{
+			DoFunc: func(req *http.Request) (*http.Response, error) {
+				header := http.Header{}
+				header.Set("X-OAuth-Scopes", "repo, read:org")
+				header.Set("GitHub-Authentication-Token-Expiration", "2030-06-07 16:34:51 UTC")
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Header:     header,
+					Body:       io.NopCloser(strings.NewReader(`{"login": "example"}`)),
+				}, nil
+			},
+		}
+
+		info, err := VerifyToken("token", client)
+		if err != nil {
+			t.Fatal("expected nil, got error")
+		}
+
+		if info.User.Login != "example" {
+			t.Errorf("got %q, want %q", info.User.Login, "example")
+		}
+		if len(info.Scopes) != 2 || info.Scopes[1] != "read:org" {
+			t.Errorf("got %v, want [repo read:org]", info.Scopes)
+		}
+		if info.ExpiresAt.Year() != 2030 {
+			t.Errorf("got %v, want 2030 expiry", info.ExpiresAt)
+		}
+	})
+
+	t.Run("returns error for rejected token", func(t *testing.T) {
+		client := &issues.ClientMock{
+			DoFunc: func(req *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: http.StatusUnauthorized,
+					Status:     "401 Unauthorized",
+					Body:       io.NopCloser(strings.NewReader(`{}`)),
+				}, nil
+			},
+		}
+
+		if _, err := VerifyToken("token", client); err == nil {
+			t.Fatal("expected error, got nil")
+		}
+	})
+}
+
+func TestFetchOrgs(t *testing.T) {
+	t.Run("returns orgs flagging SSO enforced ones", func(t *testing.T) {
+		client := &issues.ClientMock{
+			DoFunc: func(req *http.Request) (*http.Response, error) {
+				if req.URL.Path == "/user/orgs" {
+					return &http.Response{
+						StatusCode: http.StatusOK,
+						Body:       io.NopCloser(strings.NewReader(`[{"login": "open"}, {"login": "sso"}]`)),
+					}, nil
+				}
+
+				if req.URL.Path == "/orgs/sso/repos" {
+					header := http.Header{}
+					header.Set("X-GitHub-SSO", "required; url=https://github.com/orgs/sso/sso")
+					return &http.Response{
+						StatusCode: http.StatusForbidden,
+						Header:     header,
+						Body:       io.NopCloser(strings.NewReader(`{}`)),
+					}, nil
+				}
+
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(strings.NewReader(`[]`)),
+				}, nil
+			},
+		}
+
+		orgs, err := FetchOrgs("token", client)
+		if err != nil {
+			t.Fatal("expected nil, got error")
+		}
+
+		if len(orgs) != 2 {
+			t.Fatalf("expected 2 orgs, got %d", len(orgs))
+		}
+		if orgs[0].SSORequired {
+			t.Errorf("expected %s to not require SSO", orgs[0].Login)
+		}
+		if !orgs[1].SSORequired || orgs[1].SSOURL != "https://github.com/orgs/sso/sso" {
+			t.Errorf("expected %s to require SSO, got %+v", orgs[1].Login, orgs[1])
+		}
+	})
+
+	t.Run("returns error for failed request", func(t *testing.T) {
+		client := &issues.ClientMock{
+			DoFunc: func(req *http.Request) (*http.Response, error) {
+				return nil, fmt.Errorf("example")
+			},
+		}
+
+		if _, err := FetchOrgs("token", client); err == nil {
+			t.Fatal("expected error, got nil")
+		}
+	})
+}
@@ -1,7 +1,9 @@
 package github
 
 import (
+	"context"
 	"strings"
+	"sync"
 )
 
 // parseRepo extracts the repository name from html_url.
@@ -15,3 +17,40 @@ func parseRepo(url string) string {
 	}
 	return ""
 }
+
+// forEach calls fn for 0..n-1 with at most workers calls running at once
+func forEach(n int, workers int, fn func(i int)) {
+	sem := make(chan struct{}, max(workers, 1))
+	var wg sync.WaitGroup
+
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			fn(i)
+		}(i)
+	}
+
+	wg.Wait()
+}
+
+// requestLimit caps how many API requests run at once, shared by every org
+// and page of a fetch so nested worker pools don't multiply
+type requestLimit chan struct{}
+
+func newRequestLimit(n int) requestLimit {
+	return make(requestLimit, max(n, 1))
+}
+
+// do runs fn once a request slot is free, or returns ctx's error
+func (l requestLimit) do(ctx context.Context, fn func() error) error {
+	select {
+	case l <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	defer func() { <-l }()
+	return fn()
+}
@@ -1,6 +1,10 @@
 package github
 
-import "testing"
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
 
 func TestParseRepo(t *testing.T) {
 	t.Run("returns empty string for invalid URL", func(t *testing.T) {
@@ -21,3 +25,32 @@ func TestParseRepo(t *testing.T) {
 		}
 	})
 }
+
+func TestForEach(t *testing.T) {
+	t.Run("calls every index with bounded concurrency", func(t *testing.T) {
+		var running, peak atomic.Int32
+		seen := make([]bool, 10)
+
+		forEach(len(seen), 3, func(i int) {
+			n := running.Add(1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			seen[i] = true
+			running.Add(-1)
+		})
+
+		for i, ok := range seen {
+			if !ok {
+				t.Errorf("expected index %d to be called", i)
+			}
+		}
+		if peak.Load() > 3 {
+			t.Errorf("expected at most 3 concurrent calls, got %d", peak.Load())
+		}
+	})
+}
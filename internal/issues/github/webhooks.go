@@ -0,0 +1,147 @@
+package github
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/shaunmolloy/bugbox/internal/logging"
+	"github.com/shaunmolloy/bugbox/internal/storage/config"
+	"github.com/shaunmolloy/bugbox/internal/types"
+)
+
+// maxPayload is the largest delivery GitHub sends
+const maxPayload = 25 << 20
+
+// ErrSignature is returned when a delivery isn't signed with the secret
+var ErrSignature = errors.New("invalid webhook signature")
+
+// WebhookHandler applies GitHub issues, issue_comment and pull_request
+// deliveries to the issue store
+type WebhookHandler struct {
+	Secret string
+	// OnDelivery is called with the org and repo of each issues delivery for
+	// a tracked org. Other events don't show the issue webhook is working.
+	OnDelivery func(org string, repo string)
+}
+
+// webhookPayload is the part of a delivery bugbox uses
+type webhookPayload struct {
+	Action string `json:"action"`
+	Issue  *struct {
+		types.Issue
+		// PullRequest is set when a comment is on a pull request
+		PullRequest *json.RawMessage `json:"pull_request"`
+	} `json:"issue"`
+	Repository struct {
+		Name  string `json:"name"`
+		Owner User   `json:"owner"`
+	} `json:"repository"`
+}
+
+func (h *WebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxPayload))
+	if err != nil {
+		http.Error(w, "invalid body", http.StatusBadRequest)
+		return
+	}
+
+	if err := VerifySignature(h.Secret, body, r.Header.Get("X-Hub-Signature-256")); err != nil {
+		logging.Error(fmt.Sprintf("Rejected webhook delivery %s: %v", r.Header.Get("X-GitHub-Delivery"), err))
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	event := r.Header.Get("X-GitHub-Event")
+	logging.Debug(fmt.Sprintf("Webhook delivery %s: %s", r.Header.Get("X-GitHub-Delivery"), event))
+
+	switch event {
+	case "ping":
+		w.WriteHeader(http.StatusNoContent)
+		return
+	case "issues", "issue_comment", "pull_request":
+	default:
+		// Acknowledge so GitHub doesn't report failures for events we don't use
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	var payload webhookPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+
+	org := payload.Repository.Owner.Login
+	conf, _ := config.LoadConfig()
+	if !slices.Contains(conf.Orgs, org) {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	if issue, ok := issueFromPayload(event, payload); ok {
+		if err := ApplyIssues([]types.Issue{issue}); err != nil {
+			http.Error(w, "saving issue failed", http.StatusInternalServerError)
+			return
+		}
+		logging.Info(fmt.Sprintf("Applied %s %s for %s/%s#%d", event, payload.Action, issue.Org, issue.Repo, issue.ID))
+	}
+
+	if event == "issues" && h.OnDelivery != nil {
+		h.OnDelivery(org, payload.Repository.Name)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// issueFromPayload returns the issue a delivery changed. Pull requests
+// aren't stored, so their deliveries only show the webhook is working.
+func issueFromPayload(event string, payload webhookPayload) (types.Issue, bool) {
+	if event == "pull_request" || payload.Issue == nil || payload.Issue.PullRequest != nil {
+		return types.Issue{}, false
+	}
+
+	issue := payload.Issue.Issue
+	issue.Org = payload.Repository.Owner.Login
+	issue.Repo = payload.Repository.Name
+
+	// Issues moved elsewhere are no longer in the org, so drop them like closed ones
+	if event == "issues" && (payload.Action == "deleted" || payload.Action == "transferred") {
+		issue.State = types.StateClosed
+	}
+	return issue, true
+}
+
+// VerifySignature checks an X-Hub-Signature-256 header against body
+func VerifySignature(secret string, body []byte, signature string) error {
+	if secret == "" {
+		return errors.New("no webhook secret configured")
+	}
+
+	sig, ok := strings.CutPrefix(signature, "sha256=")
+	if !ok {
+		return ErrSignature
+	}
+	got, err := hex.DecodeString(sig)
+	if err != nil {
+		return ErrSignature
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	if !hmac.Equal(got, mac.Sum(nil)) {
+		return ErrSignature
+	}
+	return nil
+}
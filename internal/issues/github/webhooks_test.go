@@ -0,0 +1,151 @@
+package github
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/shaunmolloy/bugbox/internal/storage/config"
+)
+
+func sign(secret string, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestVerifySignature(t *testing.T) {
+	t.Run("returns nil for a valid signature", func(t *testing.T) {
+		if err := VerifySignature("secret", []byte("body"), sign("secret", "body")); err != nil {
+			t.Fatalf("expected nil, got error: %v", err)
+		}
+	})
+
+	t.Run("returns ErrSignature for a wrong or malformed signature", func(t *testing.T) {
+		for _, signature := range []string{sign("other", "body"), "sha256=zz", "sha1=abc", ""} {
+			if err := VerifySignature("secret", []byte("body"), signature); !errors.Is(err, ErrSignature) {
+				t.Errorf("got %v for %q, want ErrSignature", err, signature)
+			}
+		}
+	})
+
+	t.Run("returns an error without a secret", func(t *testing.T) {
+		if err := VerifySignature("", []byte("body"), sign("", "body")); err == nil {
+			t.Fatal("expected error, got nil")
+		}
+	})
+}
+
+func TestWebhookHandler(t *testing.T) {
+	setup := func(t *testing.T) (*WebhookHandler, *[]string) {
+		dir := t.TempDir()
+		config.ConfigPath = filepath.Join(dir, "config.json")
+		config.IssuesPath = filepath.Join(dir, "issues.json")
+		config.SaveConfig(config.Config{Orgs: []string{"example"}})
+		config.SaveIssues(config.Issues{"example": {}})
+
+		var delivered []string
+		return &WebhookHandler{
+			Secret:     "secret",
+			OnDelivery: func(org string, repo string) { delivered = append(delivered, org+"/"+repo) },
+		}, &delivered
+	}
+
+	deliver := func(h http.Handler, event string, body string, secret string) int {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		req.Header.Set("X-GitHub-Event", event)
+		req.Header.Set("X-Hub-Signature-256", sign(secret, body))
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	payload := func(action string, state string, extra string) string {
+		return `{"action": "` + action + `", "issue": {"number": 1, "title": "Crash", "state": "` + state + `",
+			"html_url": "https://github.com/example/api/issues/1"` + extra + `},
+			"repository": {"name": "api", "owner": {"login": "example"}}}`
+	}
+
+	t.Run("stores opened issues and removes closed ones", func(t *testing.T) {
+		h, delivered := setup(t)
+
+		if code := deliver(h, "issues", payload("opened", "open", ""), "secret"); code != http.StatusNoContent {
+			t.Fatalf("got %d, want %d", code, http.StatusNoContent)
+		}
+		issues, _ := config.LoadIssues()
+		if issues["example"]["api"][1].Title != "Crash" {
+			t.Fatalf("expected issue to be stored, got %+v", issues)
+		}
+
+		deliver(h, "issues", payload("closed", "closed", ""), "secret")
+		issues, _ = config.LoadIssues()
+		if _, ok := issues["example"]["api"][1]; ok {
+			t.Error("expected closed issue to be removed")
+		}
+
+		if len(*delivered) != 2 || (*delivered)[0] != "example/api" {
+			t.Errorf("got deliveries %v, want 2 for example/api", *delivered)
+		}
+	})
+
+	t.Run("ignores comments on pull requests", func(t *testing.T) {
+		h, delivered := setup(t)
+
+		body := payload("created", "open", `, "pull_request": {"url": "https://api.github.com/repos/example/api/pulls/1"}`)
+		if code := deliver(h, "issue_comment", body, "secret"); code != http.StatusNoContent {
+			t.Fatalf("got %d, want %d", code, http.StatusNoContent)
+		}
+		issues, _ := config.LoadIssues()
+		if len(issues["example"]) != 0 {
+			t.Errorf("expected no issues, got %+v", issues)
+		}
+		if len(*delivered) != 0 {
+			t.Errorf("expected only issues deliveries to count, got %v", *delivered)
+		}
+	})
+
+	t.Run("skips deliveries older than the stored issue", func(t *testing.T) {
+		h, _ := setup(t)
+
+		deliver(h, "issues", payload("edited", "open", `, "updated_at": "2030-01-02T00:00:00Z"`), "secret")
+		stale := strings.Replace(payload("edited", "open", `, "updated_at": "2030-01-01T00:00:00Z"`), "Crash", "Old title", 1)
+		if code := deliver(h, "issues", stale, "secret"); code != http.StatusNoContent {
+			t.Fatalf("got %d, want %d", code, http.StatusNoContent)
+		}
+
+		issues, _ := config.LoadIssues()
+		if got := issues["example"]["api"][1].Title; got != "Crash" {
+			t.Errorf("expected the newer issue to be kept, got %q", got)
+		}
+	})
+
+	t.Run("rejects deliveries with a bad signature", func(t *testing.T) {
+		h, delivered := setup(t)
+
+		if code := deliver(h, "issues", payload("opened", "open", ""), "wrong"); code != http.StatusUnauthorized {
+			t.Fatalf("got %d, want %d", code, http.StatusUnauthorized)
+		}
+		if len(*delivered) != 0 {
+			t.Errorf("expected no deliveries, got %v", *delivered)
+		}
+	})
+
+	t.Run("accepts but ignores untracked orgs", func(t *testing.T) {
+		h, delivered := setup(t)
+
+		body := strings.ReplaceAll(payload("opened", "open", ""), `"login": "example"`, `"login": "other"`)
+		if code := deliver(h, "issues", body, "secret"); code != http.StatusAccepted {
+			t.Fatalf("got %d, want %d", code, http.StatusAccepted)
+		}
+		issues, _ := config.LoadIssues()
+		if _, ok := issues["other"]; ok || len(*delivered) != 0 {
+			t.Errorf("expected nothing stored, got %+v", issues)
+		}
+	})
+}
@@ -8,7 +8,8 @@ import (
 
 var Logger *log.Logger
 
-var LogPath = filepath.Join(os.Getenv("HOME"), ".local", "share", "bugbox", "bugbox.log")
+// LogPath is the location of bugbox.log, set at startup before SetupLogger
+var LogPath string
 
 // SetupLogger sets up loggers to write to bugbox.log
 func SetupLogger() error {
@@ -40,6 +41,13 @@ func Debug(message string) {
 	}
 }
 
+// Warn logs a warning-level message
+func Warn(message string) {
+	if Logger != nil {
+		Logger.Println("[WARN] " + message)
+	}
+}
+
 // Error logs an error-level message
 func Error(message string) {
 	if Logger != nil {
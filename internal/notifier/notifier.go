@@ -0,0 +1,174 @@
+package notifier
+
+import (
+	"cmp"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/shaunmolloy/bugbox/internal/events"
+	"github.com/shaunmolloy/bugbox/internal/issues"
+	"github.com/shaunmolloy/bugbox/internal/logging"
+	"github.com/shaunmolloy/bugbox/internal/status"
+	"github.com/shaunmolloy/bugbox/internal/storage/config"
+	"github.com/shaunmolloy/bugbox/internal/types"
+)
+
+// DefaultTemplate is used for notifiers without their own template
+const DefaultTemplate = `{{if eq .Event "new"}}New{{else if eq .Issue.State.String "closed"}}Closed{{else}}Updated{{end}} issue in {{.Issue.Org}}/{{.Issue.Repo}}#{{.Issue.ID}}: {{.Issue.Title}} {{.Issue.URL}}`
+
+// Message is what a sink delivers, one per issue
+type Message struct {
+	// ID is unique per message, and kept across retries so receivers can dedupe
+	ID    string      `json:"id"`
+	Event string      `json:"event"` // new or changed
+	Issue types.Issue `json:"issue"`
+	Text  string      `json:"text"`
+}
+
+// Sink delivers messages to one destination
+type Sink interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// StatusError is returned by sinks when the receiver responds with an error
+type StatusError struct {
+	Code int
+}
+
+func (e StatusError) Error() string {
+	return fmt.Sprintf("unexpected response: %d %s", e.Code, http.StatusText(e.Code))
+}
+
+// Notifier sends new and changed issues to the configured sinks
+type Notifier struct {
+	client issues.HttpClient
+	// sleep waits between retries, swapped out in tests
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+func New(client issues.HttpClient) *Notifier {
+	return &Notifier{client: client, sleep: sleep}
+}
+
+// Start notifies about issues published on bus until ctx is cancelled
+func (n *Notifier) Start(ctx context.Context, bus *events.Bus) {
+	// Queued rather than subscribed, so events published while a send is
+	// retrying wait their turn instead of being dropped
+	updates := bus.Queue(ctx, events.NewIssues, events.ChangedIssues)
+	go func() {
+		for event := range updates {
+			name := config.NotifyNew
+			if event.Type == events.ChangedIssues {
+				name = config.NotifyChanged
+			}
+			// Reload so notifier changes apply without a restart
+			conf, _ := config.LoadConfig()
+			n.Notify(ctx, conf.Notifiers, name, event.Issues)
+		}
+	}()
+}
+
+// Notify sends each matching issue to every notifier that wants event
+func (n *Notifier) Notify(ctx context.Context, notifiers []config.NotifierConfig, event string, issues []types.Issue) {
+	for i, conf := range notifiers {
+		name := cmp.Or(conf.Name, fmt.Sprintf("notifier %d", i+1))
+		if !conf.Wants(event) {
+			continue
+		}
+
+		sink, err := NewSink(conf, n.client)
+		if err != nil {
+			report(name, err)
+			continue
+		}
+		tmpl, err := template.New(name).Parse(cmp.Or(conf.Template, DefaultTemplate))
+		if err != nil {
+			report(name, fmt.Errorf("invalid template: %w", err))
+			continue
+		}
+
+		for _, issue := range issues {
+			if !conf.Filter.Match(issue) {
+				continue
+			}
+
+			msg := Message{ID: newID(), Event: event, Issue: issue}
+			var text strings.Builder
+			if err := tmpl.Execute(&text, msg); err != nil {
+				report(name, fmt.Errorf("rendering template: %w", err))
+				break
+			}
+			msg.Text = text.String()
+
+			logging.Info(fmt.Sprintf("Sending %s/%s#%d to %s", issue.Org, issue.Repo, issue.ID, name))
+			if err := n.send(ctx, sink, msg, conf.RetryCount()); err != nil {
+				report(name, err)
+			}
+		}
+	}
+}
+
+// send delivers msg, retrying network errors, rate limits and server errors
+// with exponential backoff
+func (n *Notifier) send(ctx context.Context, sink Sink, msg Message, retries int) error {
+	var err error
+	for attempt := 0; ; attempt++ {
+		if err = sink.Send(ctx, msg); err == nil || !retryable(err) || attempt >= retries {
+			return err
+		}
+
+		delay := time.Second << attempt
+		logging.Info(fmt.Sprintf("Send failed, retrying in %s: %v", delay, err))
+		if err := n.sleep(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+// retryable returns true for rate limits, server errors and network
+// failures. Others, e.g. a missing token or an invalid URL, won't succeed
+// on retry.
+func retryable(err error) bool {
+	var statusErr StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.Code == http.StatusTooManyRequests || statusErr.Code >= 500
+	}
+
+	var opErr *net.OpError
+	var dnsErr *net.DNSError
+	var netErr net.Error
+	return errors.As(err, &opErr) ||
+		errors.As(err, &dnsErr) ||
+		errors.As(err, &netErr) && netErr.Timeout() ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// report logs a failed notifier and shows it in the TUI's error panel
+func report(name string, err error) {
+	logging.Error(fmt.Sprintf("Notifier %s failed: %v", name, err))
+	status.Sync.Error("", fmt.Errorf("%s: %w", name, err))
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(d):
+		return nil
+	}
+}
+
+func newID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
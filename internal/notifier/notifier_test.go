@@ -0,0 +1,299 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shaunmolloy/bugbox/internal/credentials"
+	"github.com/shaunmolloy/bugbox/internal/events"
+	"github.com/shaunmolloy/bugbox/internal/storage/config"
+	"github.com/shaunmolloy/bugbox/internal/types"
+)
+
+// received is a request captured by a test receiver
+type received struct {
+	Method string
+	Path   string
+	Header http.Header
+	Body   map[string]any
+}
+
+// newReceiver starts an httptest server recording requests, replying with
+// each status in turn, then 200
+func newReceiver(t *testing.T, statuses ...int) (*httptest.Server, func() []received) {
+	var mu sync.Mutex
+	var requests []received
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		var body map[string]any
+		json.Unmarshal(data, &body)
+
+		mu.Lock()
+		defer mu.Unlock()
+		requests = append(requests, received{r.Method, r.URL.EscapedPath(), r.Header, body})
+
+		status := http.StatusOK
+		if len(requests) <= len(statuses) {
+			status = statuses[len(requests)-1]
+		}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, func() []received {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]received(nil), requests...)
+	}
+}
+
+// newTestNotifier records sleeps rather than waiting
+func newTestNotifier(client *http.Client) (*Notifier, *[]time.Duration) {
+	var sleeps []time.Duration
+	n := New(client)
+	n.sleep = func(ctx context.Context, d time.Duration) error {
+		sleeps = append(sleeps, d)
+		return nil
+	}
+	return n, &sleeps
+}
+
+var bugIssue = types.Issue{
+	ID:     7,
+	Org:    "example",
+	Repo:   "api",
+	Title:  "Crash on login",
+	URL:    "https://github.com/example/api/issues/7",
+	Labels: []types.Label{{Name: "bug"}},
+}
+
+func TestNotify(t *testing.T) {
+	t.Run("posts the message as JSON to a webhook", func(t *testing.T) {
+		server, requests := newReceiver(t)
+		n, _ := newTestNotifier(server.Client())
+
+		n.Notify(context.Background(), []config.NotifierConfig{{Type: config.NotifierWebhook, URL: server.URL}}, config.NotifyNew, []types.Issue{bugIssue})
+
+		got := requests()
+		if len(got) != 1 {
+			t.Fatalf("got %d requests, want 1", len(got))
+		}
+		if got[0].Body["event"] != "new" || got[0].Body["id"] == "" {
+			t.Errorf("unexpected body %v", got[0].Body)
+		}
+		want := "New issue in example/api#7: Crash on login https://github.com/example/api/issues/7"
+		if got[0].Body["text"] != want {
+			t.Errorf("got %q, want %q", got[0].Body["text"], want)
+		}
+	})
+
+	t.Run("posts rendered templates to slack", func(t *testing.T) {
+		server, requests := newReceiver(t)
+		n, _ := newTestNotifier(server.Client())
+
+		n.Notify(context.Background(), []config.NotifierConfig{{
+			Type:     config.NotifierSlack,
+			URL:      server.URL,
+			Template: ":bug: <{{.Issue.URL}}|{{.Issue.Title}}>",
+		}}, config.NotifyNew, []types.Issue{bugIssue})
+
+		got := requests()
+		want := ":bug: <https://github.com/example/api/issues/7|Crash on login>"
+		if len(got) != 1 || got[0].Body["text"] != want {
+			t.Errorf("got %+v, want text %q", got, want)
+		}
+	})
+
+	t.Run("sends to a matrix room with the access token", func(t *testing.T) {
+		server, requests := newReceiver(t)
+		n, _ := newTestNotifier(server.Client())
+		t.Setenv("MATRIX_TOKEN", "secret")
+
+		n.Notify(context.Background(), []config.NotifierConfig{{
+			Type:  config.NotifierMatrix,
+			URL:   server.URL,
+			Room:  "!room:example.org",
+			Token: config.TokenRef{Source: credentials.SourceEnv, Value: "MATRIX_TOKEN"},
+		}}, config.NotifyNew, []types.Issue{bugIssue})
+
+		got := requests()
+		if len(got) != 1 {
+			t.Fatalf("got %d requests, want 1", len(got))
+		}
+		if got[0].Method != http.MethodPut || got[0].Header.Get("Authorization") != "Bearer secret" {
+			t.Errorf("unexpected request %+v", got[0])
+		}
+		if got[0].Body["msgtype"] != "m.text" {
+			t.Errorf("got %v, want m.text", got[0].Body["msgtype"])
+		}
+		prefix := "/_matrix/client/v3/rooms/%21room:example.org/send/m.room.message/"
+		if len(got[0].Path) <= len(prefix) || got[0].Path[:len(prefix)] != prefix {
+			t.Errorf("got path %q, want prefix %q", got[0].Path, prefix)
+		}
+	})
+
+	t.Run("only sends matching issues and events", func(t *testing.T) {
+		server, requests := newReceiver(t)
+		n, _ := newTestNotifier(server.Client())
+		notifiers := []config.NotifierConfig{{
+			Type:   config.NotifierWebhook,
+			URL:    server.URL,
+			Filter: config.IssueFilter{Labels: []string{"bug"}},
+		}}
+		other := types.Issue{ID: 8, Org: "example", Repo: "api", Title: "Docs"}
+
+		n.Notify(context.Background(), notifiers, config.NotifyNew, []types.Issue{bugIssue, other})
+		n.Notify(context.Background(), notifiers, config.NotifyChanged, []types.Issue{bugIssue})
+
+		if got := requests(); len(got) != 1 {
+			t.Errorf("got %d requests, want 1", len(got))
+		}
+	})
+
+	t.Run("retries server errors with backoff, keeping the message ID", func(t *testing.T) {
+		server, requests := newReceiver(t, http.StatusInternalServerError, http.StatusTooManyRequests)
+		n, sleeps := newTestNotifier(server.Client())
+
+		n.Notify(context.Background(), []config.NotifierConfig{{Type: config.NotifierWebhook, URL: server.URL}}, config.NotifyNew, []types.Issue{bugIssue})
+
+		got := requests()
+		if len(got) != 3 {
+			t.Fatalf("got %d requests, want 3", len(got))
+		}
+		if got[0].Body["id"] != got[2].Body["id"] {
+			t.Errorf("expected the same ID on retry, got %v and %v", got[0].Body["id"], got[2].Body["id"])
+		}
+		if len(*sleeps) != 2 || (*sleeps)[0] != time.Second || (*sleeps)[1] != 2*time.Second {
+			t.Errorf("got sleeps %v, want [1s 2s]", *sleeps)
+		}
+	})
+
+	t.Run("gives up on client errors and after the retry limit", func(t *testing.T) {
+		server, requests := newReceiver(t, http.StatusBadRequest)
+		n, _ := newTestNotifier(server.Client())
+		n.Notify(context.Background(), []config.NotifierConfig{{Type: config.NotifierWebhook, URL: server.URL}}, config.NotifyNew, []types.Issue{bugIssue})
+		if got := requests(); len(got) != 1 {
+			t.Errorf("got %d requests, want 1", len(got))
+		}
+
+		n, sleeps := newTestNotifier(server.Client())
+		n.Notify(context.Background(), []config.NotifierConfig{{Type: config.NotifierWebhook, URL: "ftp://example.com"}}, config.NotifyNew, []types.Issue{bugIssue})
+		if len(*sleeps) != 0 {
+			t.Errorf("expected no retries for an invalid URL, got sleeps %v", *sleeps)
+		}
+
+		retries := 1
+		server, requests = newReceiver(t, 500, 500, 500)
+		n, _ = newTestNotifier(server.Client())
+		n.Notify(context.Background(), []config.NotifierConfig{{Type: config.NotifierWebhook, URL: server.URL, Retries: &retries}}, config.NotifyNew, []types.Issue{bugIssue})
+		if got := requests(); len(got) != 2 {
+			t.Errorf("got %d requests, want 2", len(got))
+		}
+	})
+}
+
+func TestRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"server errors", StatusError{Code: http.StatusBadGateway}, true},
+		{"rate limits", StatusError{Code: http.StatusTooManyRequests}, true},
+		{"client errors", StatusError{Code: http.StatusNotFound}, false},
+		{"refused connections", &url.Error{Op: "Post", Err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}}, true},
+		{"timeouts", &url.Error{Op: "Post", Err: context.DeadlineExceeded}, true},
+		{"missing tokens", fmt.Errorf("matrix token: %w", errors.New("not set")), false},
+		{"cancelled sends", &url.Error{Op: "Post", Err: context.Canceled}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("returns %t for %s", test.want, test.name), func(t *testing.T) {
+			if got := retryable(test.err); got != test.want {
+				t.Errorf("got %t, want %t", got, test.want)
+			}
+		})
+	}
+}
+
+func TestNotifierStart(t *testing.T) {
+	t.Run("sends every event published while a send is slow", func(t *testing.T) {
+		var mu sync.Mutex
+		sent := 0
+		release := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-release
+			mu.Lock()
+			sent++
+			mu.Unlock()
+		}))
+		defer server.Close()
+
+		config.ConfigPath = filepath.Join(t.TempDir(), "config.json")
+		config.SaveConfig(config.Config{Notifiers: []config.NotifierConfig{{Type: config.NotifierWebhook, URL: server.URL}}})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		bus := events.NewBus()
+		n, _ := newTestNotifier(server.Client())
+		n.Start(ctx, bus)
+
+		total := 50
+		for i := 1; i <= total; i++ {
+			bus.Publish(events.Event{Type: events.NewIssues, Issues: []types.Issue{{ID: i, Org: "example", Repo: "api"}}})
+		}
+		close(release)
+
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			mu.Lock()
+			got := sent
+			mu.Unlock()
+			if got == total {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("got %d messages, want %d", got, total)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	})
+}
+
+func TestNewSink(t *testing.T) {
+	t.Run("returns an error for unknown types", func(t *testing.T) {
+		if _, err := NewSink(config.NotifierConfig{Type: "pager", URL: "https://example.com"}, http.DefaultClient); err == nil {
+			t.Fatal("expected error, got nil")
+		}
+	})
+
+	t.Run("reads a keyring matrix token from the matrix account", func(t *testing.T) {
+		got := matrixToken(config.TokenRef{Source: credentials.SourceKeyring})
+		if got.Value != MatrixKeyringAccount {
+			t.Errorf("got %q, want %q", got.Value, MatrixKeyringAccount)
+		}
+
+		got = matrixToken(config.TokenRef{Source: credentials.SourceKeyring, Value: "bot"})
+		if got.Value != "bot" {
+			t.Errorf("got %q, want bot", got.Value)
+		}
+	})
+
+	t.Run("returns an error for matrix without a room", func(t *testing.T) {
+		if _, err := NewSink(config.NotifierConfig{Type: config.NotifierMatrix, URL: "https://example.com"}, http.DefaultClient); err == nil {
+			t.Fatal("expected error, got nil")
+		}
+	})
+}
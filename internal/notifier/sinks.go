@@ -0,0 +1,125 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/shaunmolloy/bugbox/internal/credentials"
+	"github.com/shaunmolloy/bugbox/internal/issues"
+	"github.com/shaunmolloy/bugbox/internal/storage/config"
+)
+
+// MatrixKeyringAccount is the keyring account read for a Matrix token that
+// doesn't name one
+const MatrixKeyringAccount = "matrix"
+
+// NewSink returns the sink for a notifier's type
+func NewSink(conf config.NotifierConfig, client issues.HttpClient) (Sink, error) {
+	if conf.URL == "" {
+		return nil, fmt.Errorf("%s notifier needs a url", conf.Type)
+	}
+
+	switch conf.Type {
+	case config.NotifierWebhook:
+		return &webhookSink{url: conf.URL, client: client}, nil
+	case config.NotifierSlack:
+		return &slackSink{url: conf.URL, client: client}, nil
+	case config.NotifierMatrix:
+		if conf.Room == "" {
+			return nil, fmt.Errorf("matrix notifier needs a room")
+		}
+		store, err := credentials.New(matrixToken(conf.Token))
+		if err != nil {
+			return nil, fmt.Errorf("matrix token: %w", err)
+		}
+		return &matrixSink{homeserver: conf.URL, room: conf.Room, token: store, client: client}, nil
+	default:
+		return nil, fmt.Errorf("unknown notifier type %q", conf.Type)
+	}
+}
+
+// matrixToken defaults a keyring token to the Matrix account, rather than the
+// keyring's default account holding the GitHub token
+func matrixToken(ref config.TokenRef) config.TokenRef {
+	if ref.Source == credentials.SourceKeyring && ref.Value == "" {
+		ref.Value = MatrixKeyringAccount
+	}
+	return ref
+}
+
+// webhookSink posts the whole message as JSON
+type webhookSink struct {
+	url    string
+	client issues.HttpClient
+}
+
+func (s *webhookSink) Send(ctx context.Context, msg Message) error {
+	return postJSON(ctx, s.client, http.MethodPost, s.url, msg, nil)
+}
+
+// slackSink posts to a Slack-compatible incoming webhook
+type slackSink struct {
+	url    string
+	client issues.HttpClient
+}
+
+func (s *slackSink) Send(ctx context.Context, msg Message) error {
+	return postJSON(ctx, s.client, http.MethodPost, s.url, map[string]string{"text": msg.Text}, nil)
+}
+
+// matrixSink sends a text message to a Matrix room
+type matrixSink struct {
+	homeserver string
+	room       string
+	token      credentials.Store
+	client     issues.HttpClient
+}
+
+func (s *matrixSink) Send(ctx context.Context, msg Message) error {
+	token, err := s.token.Get()
+	if err != nil {
+		return fmt.Errorf("matrix token: %w", err)
+	}
+
+	// The message ID is the transaction ID, so retries aren't posted twice
+	api := fmt.Sprintf("%s/_matrix/client/v3/rooms/%s/send/m.room.message/%s",
+		strings.TrimSuffix(s.homeserver, "/"), url.PathEscape(s.room), url.PathEscape(msg.ID))
+	body := map[string]string{"msgtype": "m.text", "body": msg.Text}
+	return postJSON(ctx, s.client, http.MethodPut, api, body, map[string]string{
+		"Authorization": "Bearer " + token,
+	})
+}
+
+// postJSON sends body as JSON, returning a StatusError for non-2xx responses
+func postJSON(ctx context.Context, client issues.HttpClient, method string, api string, body any, headers map[string]string) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, api, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "bugbox")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return StatusError{Code: resp.StatusCode}
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+	"time"
+
+	"github.com/shaunmolloy/bugbox/internal/types"
+	"github.com/shaunmolloy/bugbox/internal/utils"
+)
+
+// Formats supported by Write
+const (
+	Table  = "table"
+	JSON   = "json"
+	NDJSON = "ndjson"
+	CSV    = "csv"
+)
+
+// Formats lists the formats for help text
+var Formats = []string{Table, JSON, NDJSON, CSV}
+
+// Write prints issues in format. A non-empty tmpl is a Go template run for
+// each issue instead, e.g. '{{.URL}}'.
+func Write(w io.Writer, format string, tmpl string, issues []types.Issue) error {
+	if tmpl != "" {
+		return writeTemplate(w, tmpl, issues)
+	}
+
+	switch format {
+	case Table, "":
+		return writeTable(w, issues)
+	case JSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if issues == nil {
+			issues = []types.Issue{} // print [] rather than null
+		}
+		return enc.Encode(issues)
+	case NDJSON:
+		enc := json.NewEncoder(w)
+		for _, issue := range issues {
+			if err := enc.Encode(issue); err != nil {
+				return err
+			}
+		}
+		return nil
+	case CSV:
+		return writeCSV(w, issues)
+	default:
+		return fmt.Errorf("unknown format %q, use one of %s", format, strings.Join(Formats, ", "))
+	}
+}
+
+func writeTable(w io.Writer, issues []types.Issue) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ISSUE\tTITLE\tLABELS\tCREATED")
+	for _, issue := range issues {
+		ref := fmt.Sprintf("%s/%s#%d", issue.Org, issue.Repo, issue.ID)
+		if !issue.Read {
+			ref = "* " + ref
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", ref, issue.Title, labelNames(issue, ","), utils.RelativeTime(issue.CreatedAt))
+	}
+	return tw.Flush()
+}
+
+func writeCSV(w io.Writer, issues []types.Issue) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"org", "repo", "number", "title", "labels", "read", "state", "created_at", "url"})
+	for _, issue := range issues {
+		cw.Write([]string{
+			issue.Org,
+			issue.Repo,
+			strconv.Itoa(issue.ID),
+			issue.Title,
+			labelNames(issue, ";"),
+			strconv.FormatBool(issue.Read),
+			issue.State.String(),
+			issue.CreatedAt.Format(time.RFC3339),
+			issue.URL,
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func writeTemplate(w io.Writer, tmpl string, issues []types.Issue) error {
+	t, err := template.New("issue").Funcs(template.FuncMap{
+		"join": strings.Join,
+		"ago":  utils.RelativeTime,
+	}).Parse(tmpl)
+	if err != nil {
+		return fmt.Errorf("invalid template: %w", err)
+	}
+
+	for _, issue := range issues {
+		if err := t.Execute(w, issue); err != nil {
+			return err
+		}
+		fmt.Fprintln(w)
+	}
+	return nil
+}
+
+func labelNames(issue types.Issue, sep string) string {
+	names := make([]string, len(issue.Labels))
+	for i, label := range issue.Labels {
+		names[i] = label.Name
+	}
+	return strings.Join(names, sep)
+}
@@ -0,0 +1,96 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shaunmolloy/bugbox/internal/types"
+)
+
+var testIssues = []types.Issue{
+	{
+		ID:        1,
+		Org:       "example",
+		Repo:      "api",
+		Title:     "Crash, on login",
+		URL:       "https://github.com/example/api/issues/1",
+		Labels:    []types.Label{{Name: "bug"}, {Name: "P0"}},
+		CreatedAt: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC),
+	},
+	{ID: 2, Org: "example", Repo: "web", Title: "Typo", Read: true},
+}
+
+func TestWrite(t *testing.T) {
+	t.Run("returns a table marking unread issues", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := Write(&buf, Table, "", testIssues); err != nil {
+			t.Fatalf("expected nil, got error: %v", err)
+		}
+
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		if len(lines) != 3 || !strings.HasPrefix(lines[0], "ISSUE") {
+			t.Fatalf("unexpected table %q", buf.String())
+		}
+		if !strings.HasPrefix(lines[1], "* example/api#1") || !strings.HasPrefix(lines[2], "example/web#2") {
+			t.Errorf("unexpected rows %q", lines[1:])
+		}
+	})
+
+	t.Run("returns one JSON object per line for ndjson", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := Write(&buf, NDJSON, "", testIssues); err != nil {
+			t.Fatalf("expected nil, got error: %v", err)
+		}
+
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		if len(lines) != 2 || !strings.Contains(lines[1], `"number":2`) {
+			t.Errorf("unexpected ndjson %q", buf.String())
+		}
+	})
+
+	t.Run("returns an empty JSON array without issues", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := Write(&buf, JSON, "", nil); err != nil {
+			t.Fatalf("expected nil, got error: %v", err)
+		}
+		if strings.TrimSpace(buf.String()) != "[]" {
+			t.Errorf("got %q, want []", buf.String())
+		}
+	})
+
+	t.Run("returns quoted CSV", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := Write(&buf, CSV, "", testIssues[:1]); err != nil {
+			t.Fatalf("expected nil, got error: %v", err)
+		}
+
+		want := "org,repo,number,title,labels,read,state,created_at,url\n" +
+			`example,api,1,"Crash, on login",bug;P0,false,open,2030-01-01T00:00:00Z,https://github.com/example/api/issues/1` + "\n"
+		if buf.String() != want {
+			t.Errorf("got %q, want %q", buf.String(), want)
+		}
+	})
+
+	t.Run("runs a template for each issue", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := Write(&buf, Table, "{{.Org}}/{{.Repo}}#{{.ID}} {{.Title}}", testIssues); err != nil {
+			t.Fatalf("expected nil, got error: %v", err)
+		}
+
+		want := "example/api#1 Crash, on login\nexample/web#2 Typo\n"
+		if buf.String() != want {
+			t.Errorf("got %q, want %q", buf.String(), want)
+		}
+	})
+
+	t.Run("returns an error for unknown formats and bad templates", func(t *testing.T) {
+		if err := Write(&bytes.Buffer{}, "xml", "", testIssues); err == nil {
+			t.Error("expected error for unknown format, got nil")
+		}
+		if err := Write(&bytes.Buffer{}, Table, "{{.Nope", testIssues); err == nil {
+			t.Error("expected error for bad template, got nil")
+		}
+	})
+}
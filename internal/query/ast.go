@@ -0,0 +1,189 @@
+package query
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/shaunmolloy/bugbox/internal/types"
+)
+
+// Node is part of a parsed query, matching issues
+type Node interface {
+	Match(issue types.Issue, now time.Time) bool
+	String() string
+}
+
+// And matches issues matching every node
+type And struct {
+	Nodes []Node
+}
+
+func (n And) Match(issue types.Issue, now time.Time) bool {
+	for _, node := range n.Nodes {
+		if !node.Match(issue, now) {
+			return false
+		}
+	}
+	return true
+}
+
+func (n And) String() string {
+	return "(and " + join(n.Nodes) + ")"
+}
+
+// Or matches issues matching any node
+type Or struct {
+	Nodes []Node
+}
+
+func (n Or) Match(issue types.Issue, now time.Time) bool {
+	for _, node := range n.Nodes {
+		if node.Match(issue, now) {
+			return true
+		}
+	}
+	return false
+}
+
+func (n Or) String() string {
+	return "(or " + join(n.Nodes) + ")"
+}
+
+// Not matches issues not matching its node
+type Not struct {
+	Node Node
+}
+
+func (n Not) Match(issue types.Issue, now time.Time) bool {
+	return !n.Node.Match(issue, now)
+}
+
+func (n Not) String() string {
+	return "-" + n.Node.String()
+}
+
+// Documents searches text stored apart from issues, e.g. bodies and comments
+type Documents interface {
+	Contains(issue types.Issue, value string) bool
+}
+
+// Text matches a word in the title, org or repo, or a quoted phrase in the
+// title, ignoring case. With Docs, bodies and comments are searched too.
+type Text struct {
+	Value  string
+	Phrase bool
+	Docs   Documents
+}
+
+func (n Text) Match(issue types.Issue, now time.Time) bool {
+	value := strings.ToLower(n.Value)
+	if strings.Contains(strings.ToLower(issue.Title), value) {
+		return true
+	}
+	if !n.Phrase && (strings.Contains(strings.ToLower(issue.Org), value) ||
+		strings.Contains(strings.ToLower(issue.Repo), value)) {
+		return true
+	}
+	return n.Docs != nil && n.Docs.Contains(issue, n.Value)
+}
+
+func (n Text) String() string {
+	if n.Phrase {
+		return fmt.Sprintf("%q", n.Value)
+	}
+	return n.Value
+}
+
+// Field matches a qualifier like org:acme or is:unread
+type Field struct {
+	Key   string
+	Value string
+}
+
+func (n Field) Match(issue types.Issue, now time.Time) bool {
+	switch n.Key {
+	case "org":
+		return strings.EqualFold(issue.Org, n.Value)
+	case "repo":
+		return strings.EqualFold(issue.Repo, n.Value) ||
+			strings.EqualFold(issue.Org+"/"+issue.Repo, n.Value)
+	case "label":
+		return slices.ContainsFunc(issue.Labels, func(label types.Label) bool {
+			return strings.EqualFold(label.Name, n.Value)
+		})
+	case "title":
+		return strings.Contains(strings.ToLower(issue.Title), strings.ToLower(n.Value))
+	case "is":
+		switch n.Value {
+		case "read":
+			return issue.Read
+		case "unread":
+			return !issue.Read
+		case "open":
+			return issue.State == types.StateOpen
+		case "closed":
+			return issue.State == types.StateClosed
+		}
+	}
+	return false
+}
+
+func (n Field) String() string {
+	return n.Key + ":" + n.Value
+}
+
+// Created matches when an issue was created, either relative to now by Age
+// or against a Date
+type Created struct {
+	Op   string // <, <=, > or >=, or = for a date
+	Age  time.Duration
+	Date time.Time
+}
+
+func (n Created) Match(issue types.Issue, now time.Time) bool {
+	if n.Date.IsZero() {
+		// created:<7d reads as "less than 7 days old", i.e. created after now-7d
+		cutoff := now.Add(-n.Age)
+		switch n.Op {
+		case "<":
+			return issue.CreatedAt.After(cutoff)
+		case "<=":
+			return !issue.CreatedAt.Before(cutoff)
+		case ">":
+			return issue.CreatedAt.Before(cutoff)
+		default:
+			return !issue.CreatedAt.After(cutoff)
+		}
+	}
+
+	nextDay := n.Date.AddDate(0, 0, 1)
+	switch n.Op {
+	case "<":
+		return issue.CreatedAt.Before(n.Date)
+	case "<=":
+		return issue.CreatedAt.Before(nextDay)
+	case ">":
+		return !issue.CreatedAt.Before(nextDay)
+	case ">=":
+		return !issue.CreatedAt.Before(n.Date)
+	default:
+		return !issue.CreatedAt.Before(n.Date) && issue.CreatedAt.Before(nextDay)
+	}
+}
+
+func (n Created) String() string {
+	if n.Date.IsZero() {
+		return "created:" + n.Op + n.Age.String()
+	}
+	return "created:" + strings.TrimPrefix(n.Op, "=") + n.Date.Format(time.DateOnly)
+}
+
+func join(nodes []Node) string {
+	parts := make([]string, len(nodes))
+	for i, node := range nodes {
+		parts[i] = node.String()
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,91 @@
+package query
+
+import (
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokenWord   tokenKind = iota // foo, or key:value
+	tokenPhrase                  // "foo bar"
+	tokenNot                     // - before a term
+	tokenOr                      // OR
+	tokenLParen
+	tokenRParen
+	tokenEOF
+)
+
+// token is a lexed piece of a query, Pos is its offset for error messages
+type token struct {
+	kind  tokenKind
+	value string
+	pos   int
+}
+
+// lex splits a query into tokens
+func lex(input string) ([]token, error) {
+	var tokens []token
+	runes := []rune(input)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{tokenLParen, "(", i})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{tokenRParen, ")", i})
+			i++
+		case r == '-' && i+1 < len(runes) && !unicode.IsSpace(runes[i+1]):
+			tokens = append(tokens, token{tokenNot, "-", i})
+			i++
+		case r == '"':
+			value, end, err := lexQuoted(runes, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{tokenPhrase, value, i})
+			i = end
+		default:
+			start := i
+			var word strings.Builder
+			for i < len(runes) && !unicode.IsSpace(runes[i]) && runes[i] != '(' && runes[i] != ')' {
+				// Allow quoted values, e.g. label:"good first issue"
+				if runes[i] == '"' {
+					value, end, err := lexQuoted(runes, i)
+					if err != nil {
+						return nil, err
+					}
+					word.WriteString(value)
+					i = end
+					continue
+				}
+				word.WriteRune(runes[i])
+				i++
+			}
+
+			kind := tokenWord
+			if word.String() == "OR" {
+				kind = tokenOr
+			}
+			tokens = append(tokens, token{kind, word.String(), start})
+		}
+	}
+
+	return append(tokens, token{tokenEOF, "", len(runes)}), nil
+}
+
+// lexQuoted reads a quoted string starting at runes[start], returning its
+// value and the offset after the closing quote
+func lexQuoted(runes []rune, start int) (string, int, error) {
+	for i := start + 1; i < len(runes); i++ {
+		if runes[i] == '"' {
+			return string(runes[start+1 : i]), i + 1, nil
+		}
+	}
+	return "", 0, &Error{Pos: start, Message: "missing closing quote"}
+}
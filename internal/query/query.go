@@ -0,0 +1,292 @@
+package query
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/shaunmolloy/bugbox/internal/types"
+	"github.com/shaunmolloy/bugbox/internal/utils"
+)
+
+// Keys are the supported qualifiers, e.g. org:acme
+var Keys = []string{"org", "repo", "label", "title", "is", "created"}
+
+// isValues are the supported values of is:
+var isValues = []string{"read", "unread", "open", "closed"}
+
+// Error is a problem with a query, at Pos runes into it
+type Error struct {
+	Pos     int
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("col %d: %s", e.Pos+1, e.Message)
+}
+
+// Query is a parsed query. The zero Query matches every issue.
+type Query struct {
+	Root Node
+}
+
+// Match returns true if issue matches the query at now
+func (q Query) Match(issue types.Issue, now time.Time) bool {
+	return q.Root == nil || q.Root.Match(issue, now)
+}
+
+// Filter returns the issues matching the query
+func (q Query) Filter(issues []types.Issue, now time.Time) []types.Issue {
+	if q.Root == nil {
+		return issues
+	}
+
+	var matched []types.Issue
+	for _, issue := range issues {
+		if q.Root.Match(issue, now) {
+			matched = append(matched, issue)
+		}
+	}
+	return matched
+}
+
+func (q Query) String() string {
+	if q.Root == nil {
+		return ""
+	}
+	return q.Root.String()
+}
+
+// WithDocuments returns the query with its text terms also searching docs
+func (q Query) WithDocuments(docs Documents) Query {
+	if q.Root == nil {
+		return q
+	}
+	return Query{Root: withDocuments(q.Root, docs)}
+}
+
+func withDocuments(node Node, docs Documents) Node {
+	switch n := node.(type) {
+	case And:
+		nodes := make([]Node, len(n.Nodes))
+		for i, child := range n.Nodes {
+			nodes[i] = withDocuments(child, docs)
+		}
+		return And{Nodes: nodes}
+	case Or:
+		nodes := make([]Node, len(n.Nodes))
+		for i, child := range n.Nodes {
+			nodes[i] = withDocuments(child, docs)
+		}
+		return Or{Nodes: nodes}
+	case Not:
+		return Not{Node: withDocuments(n.Node, docs)}
+	case Text:
+		n.Docs = docs
+		return n
+	}
+	return node
+}
+
+// Text returns the words and phrases searched for, including negated ones
+func (q Query) Text() []string {
+	var values []string
+	var walk func(node Node)
+	walk = func(node Node) {
+		switch n := node.(type) {
+		case And:
+			for _, child := range n.Nodes {
+				walk(child)
+			}
+		case Or:
+			for _, child := range n.Nodes {
+				walk(child)
+			}
+		case Not:
+			walk(n.Node)
+		case Text:
+			values = append(values, n.Value)
+		}
+	}
+	if q.Root != nil {
+		walk(q.Root)
+	}
+	return values
+}
+
+// Parse parses a query like `org:acme label:bug -label:wontfix is:unread
+// created:<7d "exact phrase"`. Terms are ANDed, OR has lower precedence, and
+// parentheses group.
+func Parse(input string) (Query, error) {
+	tokens, err := lex(input)
+	if err != nil {
+		return Query{}, err
+	}
+
+	p := &parser{tokens: tokens}
+	if p.peek().kind == tokenEOF {
+		return Query{}, nil
+	}
+
+	root, err := p.parseOr()
+	if err != nil {
+		return Query{}, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return Query{}, &Error{Pos: tok.pos, Message: fmt.Sprintf("unexpected %q", tok.value)}
+	}
+	return Query{Root: root}, nil
+}
+
+// parser is a recursive descent parser over tokens:
+//
+//	or      = and { "OR" and }
+//	and     = unary { unary }
+//	unary   = "-" unary | primary
+//	primary = "(" or ")" | phrase | word
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) parseOr() (Node, error) {
+	first, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := []Node{first}
+	for p.peek().kind == tokenOr {
+		p.next()
+		node, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+
+	if len(nodes) == 1 {
+		return first, nil
+	}
+	return Or{Nodes: nodes}, nil
+}
+
+func (p *parser) parseAnd() (Node, error) {
+	var nodes []Node
+	for {
+		switch p.peek().kind {
+		case tokenEOF, tokenOr, tokenRParen:
+			if len(nodes) == 0 {
+				tok := p.peek()
+				return nil, &Error{Pos: tok.pos, Message: "expected a search term"}
+			}
+			if len(nodes) == 1 {
+				return nodes[0], nil
+			}
+			return And{Nodes: nodes}, nil
+		}
+
+		node, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+}
+
+func (p *parser) parseUnary() (Node, error) {
+	if p.peek().kind == tokenNot {
+		p.next()
+		node, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return Not{Node: node}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (Node, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokenLParen:
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokenRParen {
+			return nil, &Error{Pos: tok.pos, Message: "missing closing parenthesis"}
+		}
+		return node, nil
+	case tokenPhrase:
+		return Text{Value: tok.value, Phrase: true}, nil
+	case tokenWord:
+		return parseWord(tok)
+	default:
+		return nil, &Error{Pos: tok.pos, Message: fmt.Sprintf("unexpected %q", tok.value)}
+	}
+}
+
+// parseWord parses a bare word or a key:value qualifier
+func parseWord(tok token) (Node, error) {
+	// Words like "TypeError:" or URLs are text, not qualifiers
+	key, value, ok := strings.Cut(tok.value, ":")
+	key = strings.ToLower(key)
+	if !ok || !slices.Contains(Keys, key) {
+		return Text{Value: tok.value}, nil
+	}
+	if value == "" {
+		return nil, &Error{Pos: tok.pos, Message: fmt.Sprintf("%s: needs a value", key)}
+	}
+
+	switch key {
+	case "is":
+		value = strings.ToLower(value)
+		if !slices.Contains(isValues, value) {
+			return nil, &Error{Pos: tok.pos, Message: fmt.Sprintf("is:%s is not supported, use one of %s", value, strings.Join(isValues, ", "))}
+		}
+	case "created":
+		return parseCreated(tok, value)
+	}
+	return Field{Key: key, Value: value}, nil
+}
+
+// parseCreated parses created:<7d, created:>=2024-01-01 and similar. Without
+// an operator, an age means within it and a date means on that day.
+func parseCreated(tok token, value string) (Node, error) {
+	var node Created
+	for _, op := range []string{"<=", ">=", "<", ">"} {
+		if rest, ok := strings.CutPrefix(value, op); ok {
+			node.Op, value = op, rest
+			break
+		}
+	}
+
+	if date, err := time.ParseInLocation(time.DateOnly, value, time.Local); err == nil {
+		node.Date = date
+		node.Op = cmp.Or(node.Op, "=")
+		return node, nil
+	}
+
+	age, err := utils.ParseAge(value)
+	if err != nil {
+		return nil, &Error{Pos: tok.pos, Message: fmt.Sprintf("created: needs an age like 7d or a date like 2024-01-31, got %q", value)}
+	}
+	node.Age = age
+	node.Op = cmp.Or(node.Op, "<=")
+	return node, nil
+}
@@ -0,0 +1,217 @@
+package query
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shaunmolloy/bugbox/internal/types"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"", ""},
+		{"   ", ""},
+		{"crash", "crash"},
+		{"Crash Login", "(and Crash Login)"},
+		{`"exact phrase"`, `"exact phrase"`},
+		{"org:acme", "org:acme"},
+		{"ORG:acme", "org:acme"},
+		{`label:"good first issue"`, "label:good first issue"},
+		{"-label:wontfix", "-label:wontfix"},
+		{"--label:wontfix", "--label:wontfix"},
+		{"is:UNREAD", "is:unread"},
+		{"created:<7d", "created:<168h0m0s"},
+		{"created:7d", "created:<=168h0m0s"},
+		{"created:>=2024-01-31", "created:>=2024-01-31"},
+		{"created:2024-01-31", "created:2024-01-31"},
+		{"a OR b c", "(or a (and b c))"},
+		{"(a OR b) c", "(and (or a b) c)"},
+		{"-(a OR b)", "-(or a b)"},
+		{"a-b", "a-b"},
+		{"TypeError: undefined", "(and TypeError: undefined)"},
+		{"https://example.com/a", "https://example.com/a"},
+		{"a - b", "(and a - b)"},
+		{
+			`org:acme repo:api label:bug -label:wontfix is:unread created:<7d "exact phrase"`,
+			`(and org:acme repo:api label:bug -label:wontfix is:unread created:<168h0m0s "exact phrase")`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run("returns the AST for "+test.input, func(t *testing.T) {
+			q, err := Parse(test.input)
+			if err != nil {
+				t.Fatalf("expected nil, got error: %v", err)
+			}
+			if got := q.String(); got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		pos   int
+		want  string
+	}{
+		{`"unterminated`, 0, "col 1: missing closing quote"},
+		{`label:"unterminated`, 6, "col 7: missing closing quote"},
+		{"crash org:", 6, "col 7: org: needs a value"},
+		{"is:pinned", 0, "col 1: is:pinned is not supported, use one of read, unread, open, closed"},
+		{"created:soon", 0, `col 1: created: needs an age like 7d or a date like 2024-01-31, got "soon"`},
+		{"(a b", 0, "col 1: missing closing parenthesis"},
+		{"a )", 2, `col 3: unexpected ")"`},
+		{"a OR", 4, "col 5: expected a search term"},
+		{"OR a", 0, "col 1: expected a search term"},
+		{"()", 1, "col 2: expected a search term"},
+	}
+
+	for _, test := range tests {
+		t.Run("returns an error for "+test.input, func(t *testing.T) {
+			_, err := Parse(test.input)
+
+			var queryErr *Error
+			if !errors.As(err, &queryErr) {
+				t.Fatalf("expected *Error, got %v", err)
+			}
+			if queryErr.Pos != test.pos || err.Error() != test.want {
+				t.Errorf("got %q at %d, want %q at %d", err.Error(), queryErr.Pos, test.want, test.pos)
+			}
+		})
+	}
+}
+
+func TestMatch(t *testing.T) {
+	now := time.Date(2030, 1, 15, 12, 0, 0, 0, time.Local)
+	issue := types.Issue{
+		Org:       "acme",
+		Repo:      "api",
+		Title:     "Crash on login page",
+		Labels:    []types.Label{{Name: "bug"}, {Name: "good first issue"}},
+		CreatedAt: now.Add(-3 * 24 * time.Hour),
+	}
+	read := issue
+	read.Read = true
+
+	tests := []struct {
+		query string
+		issue types.Issue
+		want  bool
+	}{
+		{"", issue, true},
+		{"crash", issue, true},
+		{"CRASH", issue, true},
+		{"acme", issue, true},
+		{"api", issue, true},
+		{"missing", issue, false},
+		{`"login page"`, issue, true},
+		{`"page login"`, issue, false},
+		{"org:acme", issue, true},
+		{"org:ACME", issue, true},
+		{"org:other", issue, false},
+		{"repo:api", issue, true},
+		{"repo:acme/api", issue, true},
+		{"repo:other/api", issue, false},
+		{"label:BUG", issue, true},
+		{`label:"good first issue"`, issue, true},
+		{"-label:wontfix", issue, true},
+		{"-label:bug", issue, false},
+		{"title:login", issue, true},
+		{"is:unread", issue, true},
+		{"is:unread", read, false},
+		{"is:read", read, true},
+		{"is:open", issue, true},
+		{"is:closed", issue, false},
+		{"created:<7d", issue, true},
+		{"created:<2d", issue, false},
+		{"created:>2d", issue, true},
+		{"created:7d", issue, true},
+		{"created:2030-01-12", issue, true},
+		{"created:2030-01-13", issue, false},
+		{"created:>=2030-01-12", issue, true},
+		{"created:<2030-01-12", issue, false},
+		{"created:<=2030-01-12", issue, true},
+		{"created:>2030-01-12", issue, false},
+		{"org:other OR label:bug", issue, true},
+		{"org:acme (label:wontfix OR is:read)", issue, false},
+		{`org:acme repo:api label:bug -label:wontfix is:unread created:<7d "login page"`, issue, true},
+	}
+
+	for _, test := range tests {
+		t.Run("returns "+map[bool]string{true: "a match", false: "no match"}[test.want]+" for "+test.query, func(t *testing.T) {
+			q, err := Parse(test.query)
+			if err != nil {
+				t.Fatalf("expected nil, got error: %v", err)
+			}
+			if got := q.Match(test.issue, now); got != test.want {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestFilter(t *testing.T) {
+	t.Run("returns matching issues in order", func(t *testing.T) {
+		issues := []types.Issue{
+			{ID: 1, Title: "Crash"},
+			{ID: 2, Title: "Typo"},
+			{ID: 3, Title: "Crash again"},
+		}
+
+		q, _ := Parse("crash")
+		got := q.Filter(issues, time.Now())
+		if len(got) != 2 || got[0].ID != 1 || got[1].ID != 3 {
+			t.Errorf("got %+v, want issues 1 and 3", got)
+		}
+	})
+}
+
+// bodies is Documents searching a map of issue IDs to body text
+type bodies map[int]string
+
+func (b bodies) Contains(issue types.Issue, value string) bool {
+	return strings.Contains(strings.ToLower(b[issue.ID]), strings.ToLower(value))
+}
+
+func TestWithDocuments(t *testing.T) {
+	issue := types.Issue{ID: 1, Title: "Crash on start"}
+	docs := bodies{1: "Panics in the importer"}
+
+	t.Run("returns matches in documents for words and phrases", func(t *testing.T) {
+		for _, input := range []string{"importer", `"panics in"`, "crash importer", "label:bug OR importer"} {
+			q, _ := Parse(input)
+			if q.Match(issue, time.Now()) {
+				t.Errorf("expected no match for %s without documents", input)
+			}
+			if !q.WithDocuments(docs).Match(issue, time.Now()) {
+				t.Errorf("expected a match for %s", input)
+			}
+		}
+	})
+
+	t.Run("returns no match for negated words in documents", func(t *testing.T) {
+		q, _ := Parse("-importer")
+		if q.WithDocuments(docs).Match(issue, time.Now()) {
+			t.Error("expected no match")
+		}
+	})
+}
+
+func TestText(t *testing.T) {
+	t.Run("returns words and phrases", func(t *testing.T) {
+		q, _ := Parse(`crash "login page" -typo org:acme (panic OR label:bug)`)
+		got := q.Text()
+		want := []string{"crash", "login page", "typo", "panic"}
+		if !slices.Equal(got, want) {
+			t.Errorf("got %q, want %q", got, want)
+		}
+	})
+}
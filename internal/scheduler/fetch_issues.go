@@ -1,45 +1,198 @@
 package scheduler
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"sync"
 	"time"
 
 	"github.com/shaunmolloy/bugbox/internal/issues"
 	"github.com/shaunmolloy/bugbox/internal/issues/github"
 	"github.com/shaunmolloy/bugbox/internal/logging"
+	"github.com/shaunmolloy/bugbox/internal/status"
 	"github.com/shaunmolloy/bugbox/internal/storage/config"
-	"github.com/shaunmolloy/bugbox/internal/tui"
+	"github.com/shaunmolloy/bugbox/internal/types"
 )
 
-// FetchIssues starts a goroutine that polls for GitHub issues every minute
-func FetchIssues(client issues.HttpClient) {
-	go func() {
-		config.PruneInvalidOrgs()
-		handleGitHub(true, client)
+// FetchFunc fetches issues for orgs, doing a full fetch when fetchAll is set
+type FetchFunc func(ctx context.Context, orgs []string, fetchAll bool, progress github.Progress) error
+
+// Scheduler polls for GitHub issues on each org's schedule until its context is cancelled
+type Scheduler struct {
+	clock   Clock
+	fetch   FetchFunc
+	plan    *planner
+	status  *status.Tracker
+	refresh chan struct{}
+	wg      sync.WaitGroup
+
+	mu sync.Mutex
+	// delivered is when each org's repos last received an issues delivery
+	delivered map[string]map[string]time.Time
+}
+
+// New creates a scheduler fetching from GitHub with client
+func New(client issues.HttpClient) *Scheduler {
+	return newScheduler(realClock{}, status.Sync, func(ctx context.Context, orgs []string, fetchAll bool, progress github.Progress) error {
+		return github.FetchIssuesForOrgs(ctx, orgs, fetchAll, client, progress)
+	})
+}
+
+func newScheduler(clock Clock, tracker *status.Tracker, fetch FetchFunc) *Scheduler {
+	return &Scheduler{
+		clock:     clock,
+		fetch:     fetch,
+		plan:      newPlanner(github.Provider),
+		status:    tracker,
+		refresh:   make(chan struct{}, 1),
+		delivered: make(map[string]map[string]time.Time),
+	}
+}
+
+// Delivered records an issues delivery for org/repo. Incremental polls of an
+// org are skipped while every repo with stored issues has had one within the
+// configured idle period, while full resyncs still run to catch any missed
+// deliveries.
+func (s *Scheduler) Delivered(org string, repo string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.delivered[org] == nil {
+		s.delivered[org] = make(map[string]time.Time)
+	}
+	s.delivered[org][repo] = s.clock.Now()
+}
+
+// polling drops orgs covered by recent webhook deliveries from fetch, unless
+// due a full resync, returning the rest and those skipped
+func (s *Scheduler) polling(fetch []string, full []string, now time.Time, idleAfter time.Duration) (poll []string, skipped []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-		// Then fetch every minute
-		ticker := time.NewTicker(1 * time.Minute)
-		for range ticker.C {
-			handleGitHub(false, client)
+	var stored config.Issues
+	for _, org := range fetch {
+		if len(s.delivered[org]) > 0 && !slices.Contains(full, org) {
+			if stored == nil {
+				stored, _ = config.LoadIssues()
+			}
+			if s.covered(org, stored[org], now, idleAfter) {
+				skipped = append(skipped, org)
+				continue
+			}
 		}
-	}()
+		poll = append(poll, org)
+	}
+	return poll, skipped
 }
 
-func handleGitHub(fetchAll bool, client issues.HttpClient) {
-	logging.Info("Fetching GitHub issues...")
-	err := github.FetchAllIssues(fetchAll, client)
-	if err != nil {
-		logging.Error(fmt.Sprintf("Fetching error: %v", err))
-		return
+// covered returns true if every repo in repos had a delivery within idleAfter.
+// A quiet repo can't be told apart from one without a webhook, so isn't covered.
+func (s *Scheduler) covered(org string, repos map[string]map[int]types.Issue, now time.Time, idleAfter time.Duration) bool {
+	if len(repos) == 0 {
+		return false
+	}
+	for repo := range repos {
+		last, ok := s.delivered[org][repo]
+		if !ok || now.Sub(last) >= idleAfter {
+			return false
+		}
 	}
-	logging.Info("Fetched GitHub issues successfully")
+	return true
+}
 
-	// Signal the TUI to refresh with the updated issues
-	// Use non-blocking send to prevent hanging if channel is full
+// Refresh fetches every org straight away, or once a sync in progress
+// finishes, as it may have fetched some orgs before the change
+func (s *Scheduler) Refresh() {
 	select {
-	case tui.RefreshChan <- struct{}{}:
-		logging.Info("Sent refresh signal to TUI")
+	case s.refresh <- struct{}{}:
+		logging.Info("Refresh requested")
 	default:
-		// Channel is full, no need to send another signal
+		// A refresh is already pending
+	}
+}
+
+// Start polls in a goroutine until ctx is cancelled
+func (s *Scheduler) Start(ctx context.Context) {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.run(ctx)
+	}()
+}
+
+// Wait blocks until the scheduler has stopped, including any in-flight save
+func (s *Scheduler) Wait() {
+	s.wg.Wait()
+}
+
+func (s *Scheduler) run(ctx context.Context) {
+	config.PruneInvalidOrgs()
+
+	for {
+		conf, _ := config.LoadConfig()
+		now := s.clock.Now()
+
+		fetch, full := s.plan.due(conf.Orgs, now)
+		fetch, skipped := s.polling(fetch, full, now, conf.Webhooks.IdleThreshold())
+		for _, org := range skipped {
+			s.plan.done(conf, org, false, now)
+		}
+		if len(fetch) > 0 {
+			s.handleGitHub(ctx, fetch, full)
+			for _, org := range fetch {
+				s.plan.done(conf, org, slices.Contains(full, org), now)
+			}
+		}
+
+		// Sleep until the next org is due, a refresh, or we're told to stop
+		select {
+		case <-ctx.Done():
+			logging.Info("Scheduler stopped")
+			return
+		case <-s.refresh:
+			s.plan.dueNow(conf.Orgs)
+		case <-s.clock.After(s.plan.next(conf.Orgs, s.clock.Now()).Sub(s.clock.Now())):
+		}
+	}
+}
+
+// handleGitHub fetches orgs, doing a full fetch of those in full
+func (s *Scheduler) handleGitHub(ctx context.Context, orgs []string, full []string) {
+	logging.Info(fmt.Sprintf("Fetching GitHub issues for %d org(s)...", len(orgs)))
+
+	s.status.Start(len(orgs))
+	defer s.status.Finish()
+
+	var partial []string
+	for _, org := range orgs {
+		if !slices.Contains(full, org) {
+			partial = append(partial, org)
+		}
+	}
+
+	for _, batch := range []struct {
+		orgs     []string
+		fetchAll bool
+	}{{full, true}, {partial, false}} {
+		if len(batch.orgs) == 0 {
+			continue
+		}
+		if err := s.fetch(ctx, batch.orgs, batch.fetchAll, s.status); err != nil {
+			if ctx.Err() != nil {
+				logging.Info("Fetching cancelled")
+				return
+			}
+			logging.Error(fmt.Sprintf("Fetching error: %v", err))
+
+			// Other orgs were still saved when only some failed, and
+			// their errors were already reported through the tracker
+			var fetchErrs github.FetchErrors
+			if !errors.As(err, &fetchErrs) {
+				s.status.Error("", err)
+				return
+			}
+		}
 	}
+	logging.Info("Fetched GitHub issues")
 }
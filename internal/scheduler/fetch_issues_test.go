@@ -0,0 +1,262 @@
+package scheduler
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shaunmolloy/bugbox/internal/issues/github"
+	"github.com/shaunmolloy/bugbox/internal/status"
+	"github.com/shaunmolloy/bugbox/internal/storage/config"
+)
+
+// fakeClock only moves when Advance is called
+type fakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []fakeWaiter
+	// waiting receives each time something waits on After
+	waiting chan struct{}
+}
+
+type fakeWaiter struct {
+	at time.Time
+	ch chan time.Time
+}
+
+func newFakeClock(now time.Time) *fakeClock {
+	return &fakeClock{now: now, waiting: make(chan struct{}, 10)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ch := make(chan time.Time, 1)
+	c.waiters = append(c.waiters, fakeWaiter{at: c.now.Add(d), ch: ch})
+	c.waiting <- struct{}{}
+	return ch
+}
+
+// Advance moves time forward, firing any waiters now due
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+	var pending []fakeWaiter
+	for _, w := range c.waiters {
+		if c.now.Before(w.at) {
+			pending = append(pending, w)
+			continue
+		}
+		w.ch <- c.now
+	}
+	c.waiters = pending
+}
+
+type fetchCall struct {
+	orgs     []string
+	fetchAll bool
+}
+
+func setupConfig(t *testing.T, conf config.Config) {
+	dir := t.TempDir()
+	config.ConfigPath = filepath.Join(dir, "config.json")
+	config.IssuesPath = filepath.Join(dir, "issues.json")
+	if err := config.SaveConfig(conf); err != nil {
+		t.Fatal("expected nil, got error")
+	}
+	config.SaveIssues(config.Issues{})
+}
+
+func waitFor[T any](t *testing.T, ch <-chan T) T {
+	t.Helper()
+	select {
+	case v := <-ch:
+		return v
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting")
+	}
+	var zero T
+	return zero
+}
+
+func TestScheduler(t *testing.T) {
+	t.Run("fetches all at startup then on each interval", func(t *testing.T) {
+		setupConfig(t, config.Config{Orgs: []string{"example"}})
+		clock := newFakeClock(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC))
+
+		calls := make(chan fetchCall, 10)
+		s := newScheduler(clock, status.NewTracker(), func(ctx context.Context, orgs []string, fetchAll bool, progress github.Progress) error {
+			calls <- fetchCall{orgs, fetchAll}
+			return nil
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		s.Start(ctx)
+
+		if call := waitFor(t, calls); !call.fetchAll {
+			t.Errorf("expected full fetch at startup, got %+v", call)
+		}
+		waitFor(t, clock.waiting)
+
+		clock.Advance(30 * time.Second)
+		select {
+		case call := <-calls:
+			t.Fatalf("expected no fetch before the interval, got %+v", call)
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		clock.Advance(30 * time.Second)
+		if call := waitFor(t, calls); call.fetchAll || call.orgs[0] != "example" {
+			t.Errorf("expected incremental fetch of example, got %+v", call)
+		}
+		waitFor(t, clock.waiting)
+
+		cancel()
+		s.Wait()
+	})
+
+	t.Run("fetches straight away on refresh", func(t *testing.T) {
+		setupConfig(t, config.Config{Orgs: []string{"example"}})
+		clock := newFakeClock(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC))
+
+		calls := make(chan fetchCall, 10)
+		s := newScheduler(clock, status.NewTracker(), func(ctx context.Context, orgs []string, fetchAll bool, progress github.Progress) error {
+			calls <- fetchCall{orgs, fetchAll}
+			return nil
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		s.Start(ctx)
+
+		waitFor(t, calls)
+		waitFor(t, clock.waiting)
+
+		s.Refresh()
+		if call := waitFor(t, calls); call.fetchAll {
+			t.Errorf("expected incremental fetch on refresh, got %+v", call)
+		}
+		waitFor(t, clock.waiting)
+
+		cancel()
+		s.Wait()
+	})
+
+	t.Run("queues a refresh requested while syncing", func(t *testing.T) {
+		setupConfig(t, config.Config{Orgs: []string{"example"}})
+		clock := newFakeClock(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC))
+		tracker := status.NewTracker()
+
+		release := make(chan struct{})
+		calls := make(chan fetchCall, 10)
+		s := newScheduler(clock, tracker, func(ctx context.Context, orgs []string, fetchAll bool, progress github.Progress) error {
+			calls <- fetchCall{orgs, fetchAll}
+			<-release
+			return nil
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		s.Start(ctx)
+
+		waitFor(t, calls)
+		s.Refresh()
+		s.Refresh() // already pending, so deduplicated
+		close(release)
+
+		if call := waitFor(t, calls); call.fetchAll || call.orgs[0] != "example" {
+			t.Errorf("expected a fetch after the sync, got %+v", call)
+		}
+		waitFor(t, clock.waiting)
+
+		select {
+		case call := <-calls:
+			t.Fatalf("expected one refresh, got %+v", call)
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		cancel()
+		s.Wait()
+	})
+
+	t.Run("skips polling orgs whose repos all have recent webhook deliveries", func(t *testing.T) {
+		setupConfig(t, config.Config{Orgs: []string{"example"}})
+		config.SaveIssues(config.Issues{"example": {
+			"api": {1: {ID: 1, Org: "example", Repo: "api"}},
+			"web": {2: {ID: 2, Org: "example", Repo: "web"}},
+		}})
+		clock := newFakeClock(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC))
+
+		calls := make(chan fetchCall, 10)
+		s := newScheduler(clock, status.NewTracker(), func(ctx context.Context, orgs []string, fetchAll bool, progress github.Progress) error {
+			calls <- fetchCall{orgs, fetchAll}
+			return nil
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		s.Start(ctx)
+
+		waitFor(t, calls)
+		waitFor(t, clock.waiting)
+
+		// One repo's deliveries don't say anything about the other
+		s.Delivered("example", "api")
+		clock.Advance(time.Minute)
+		waitFor(t, calls)
+		waitFor(t, clock.waiting)
+
+		s.Delivered("example", "web")
+		clock.Advance(time.Minute)
+		waitFor(t, clock.waiting)
+		select {
+		case call := <-calls:
+			t.Fatalf("expected no poll while webhooks are active, got %+v", call)
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		// Polling resumes once the org has been idle
+		clock.Advance(config.DefaultWebhooksIdleAfter)
+		if call := waitFor(t, calls); call.fetchAll {
+			t.Errorf("expected incremental fetch, got %+v", call)
+		}
+		waitFor(t, clock.waiting)
+
+		cancel()
+		s.Wait()
+	})
+
+	t.Run("cancels in-flight fetches and waits for them on stop", func(t *testing.T) {
+		setupConfig(t, config.Config{Orgs: []string{"example"}})
+		clock := newFakeClock(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC))
+
+		started := make(chan struct{})
+		var finished bool
+		s := newScheduler(clock, status.NewTracker(), func(ctx context.Context, orgs []string, fetchAll bool, progress github.Progress) error {
+			close(started)
+			<-ctx.Done()
+			time.Sleep(10 * time.Millisecond) // simulate finishing a save
+			finished = true
+			return ctx.Err()
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		s.Start(ctx)
+
+		waitFor(t, started)
+		cancel()
+		s.Wait()
+
+		if !finished {
+			t.Fatal("expected Wait to block until the fetch finished")
+		}
+	})
+}
@@ -0,0 +1,101 @@
+package scheduler
+
+import (
+	"math/rand/v2"
+	"time"
+
+	"github.com/shaunmolloy/bugbox/internal/storage/config"
+)
+
+// planner tracks when each org is next due a fetch
+type planner struct {
+	provider  string
+	nextFetch map[string]time.Time
+	nextFull  map[string]time.Time
+	// jitter returns a random delay up to max, swapped out in tests
+	jitter func(max time.Duration) time.Duration
+}
+
+func newPlanner(provider string) *planner {
+	return &planner{
+		provider:  provider,
+		nextFetch: make(map[string]time.Time),
+		nextFull:  make(map[string]time.Time),
+		jitter: func(max time.Duration) time.Duration {
+			if max <= 0 {
+				return 0
+			}
+			return rand.N(max)
+		},
+	}
+}
+
+// due returns the orgs to fetch at now, and which of those need a full fetch.
+// Orgs not seen before are due a full fetch straight away.
+func (p *planner) due(orgs []string, now time.Time) (fetch []string, full []string) {
+	for _, org := range orgs {
+		next, seen := p.nextFetch[org]
+		if !seen {
+			fetch = append(fetch, org)
+			full = append(full, org)
+			continue
+		}
+
+		nextFull, hasFull := p.nextFull[org]
+		switch {
+		case hasFull && !now.Before(nextFull):
+			fetch = append(fetch, org)
+			full = append(full, org)
+		case !now.Before(next):
+			fetch = append(fetch, org)
+		}
+	}
+	return fetch, full
+}
+
+// done records a fetch of org at now, scheduling the next one
+func (p *planner) done(conf config.Config, org string, full bool, now time.Time) {
+	schedule := conf.Polling.For(p.provider, org)
+
+	p.nextFetch[org] = now.Add(time.Duration(schedule.Interval) + p.jitter(time.Duration(schedule.Jitter)))
+
+	if full || p.nextFull[org].IsZero() {
+		if schedule.FullResync > 0 {
+			p.nextFull[org] = now.Add(time.Duration(schedule.FullResync))
+		} else {
+			delete(p.nextFull, org)
+		}
+	}
+}
+
+// dueNow makes every known org due an incremental fetch, e.g. on manual refresh
+func (p *planner) dueNow(orgs []string) {
+	for _, org := range orgs {
+		if _, seen := p.nextFetch[org]; seen {
+			p.nextFetch[org] = time.Time{}
+		}
+	}
+}
+
+// next returns when the earliest org is due, or now when an org hasn't been seen
+func (p *planner) next(orgs []string, now time.Time) time.Time {
+	var earliest time.Time
+	for _, org := range orgs {
+		next, seen := p.nextFetch[org]
+		if !seen {
+			return now
+		}
+		if nextFull, ok := p.nextFull[org]; ok && nextFull.Before(next) {
+			next = nextFull
+		}
+		if earliest.IsZero() || next.Before(earliest) {
+			earliest = next
+		}
+	}
+
+	if earliest.IsZero() {
+		// No orgs configured, check again once config may have changed
+		return now.Add(config.DefaultInterval)
+	}
+	return earliest
+}
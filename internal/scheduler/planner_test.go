@@ -0,0 +1,85 @@
+package scheduler
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/shaunmolloy/bugbox/internal/storage/config"
+)
+
+func TestPlanner(t *testing.T) {
+	start := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	conf := config.Config{
+		Orgs: []string{"noisy", "archive"},
+		Polling: config.PollingConfig{
+			Orgs: map[string]config.Schedule{
+				"archive": {Interval: config.Duration(time.Hour), FullResync: config.Duration(24 * time.Hour)},
+			},
+		},
+	}
+
+	newPlan := func() *planner {
+		plan := newPlanner("github")
+		plan.jitter = func(max time.Duration) time.Duration { return max }
+		return plan
+	}
+
+	t.Run("returns unseen orgs as due a full fetch", func(t *testing.T) {
+		plan := newPlan()
+
+		fetch, full := plan.due(conf.Orgs, start)
+		if !slices.Equal(fetch, conf.Orgs) || !slices.Equal(full, conf.Orgs) {
+			t.Errorf("got %v and %v, want all orgs", fetch, full)
+		}
+	})
+
+	t.Run("returns orgs due on their own interval", func(t *testing.T) {
+		plan := newPlan()
+		for _, org := range conf.Orgs {
+			plan.done(conf, org, true, start)
+		}
+
+		fetch, _ := plan.due(conf.Orgs, start.Add(30*time.Second))
+		if len(fetch) != 0 {
+			t.Errorf("got %v, want none", fetch)
+		}
+
+		fetch, full := plan.due(conf.Orgs, start.Add(time.Minute))
+		if !slices.Equal(fetch, []string{"noisy"}) || len(full) != 0 {
+			t.Errorf("got %v and %v, want [noisy] and none", fetch, full)
+		}
+
+		if next := plan.next(conf.Orgs, start); !next.Equal(start.Add(time.Minute)) {
+			t.Errorf("got %v, want %v", next, start.Add(time.Minute))
+		}
+	})
+
+	t.Run("returns orgs due a full resync", func(t *testing.T) {
+		plan := newPlan()
+		for _, org := range conf.Orgs {
+			plan.done(conf, org, true, start)
+		}
+
+		// Keep archive's incremental fetches going until the resync is due
+		for now := start.Add(time.Hour); now.Before(start.Add(24 * time.Hour)); now = now.Add(time.Hour) {
+			plan.done(conf, "archive", false, now)
+		}
+
+		_, full := plan.due([]string{"archive"}, start.Add(24*time.Hour))
+		if !slices.Equal(full, []string{"archive"}) {
+			t.Errorf("got %v, want [archive]", full)
+		}
+	})
+
+	t.Run("adds jitter to the interval", func(t *testing.T) {
+		plan := newPlan()
+		jittered := conf
+		jittered.Polling.Jitter = config.Duration(10 * time.Second)
+
+		plan.done(jittered, "noisy", true, start)
+		if got := plan.nextFetch["noisy"]; !got.Equal(start.Add(70 * time.Second)) {
+			t.Errorf("got %v, want %v", got, start.Add(70*time.Second))
+		}
+	})
+}
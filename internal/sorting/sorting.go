@@ -0,0 +1,172 @@
+package sorting
+
+import (
+	"cmp"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/shaunmolloy/bugbox/internal/types"
+)
+
+// Field is what issues are sorted by
+type Field string
+
+const (
+	Created   Field = "created"
+	Updated   Field = "updated"
+	Comments  Field = "comments"
+	Reactions Field = "reactions"
+	Repo      Field = "repo"
+	Title     Field = "title"
+	Priority  Field = "priority"
+)
+
+// Fields lists every sort field, in the order they're cycled through
+var Fields = []Field{Created, Updated, Comments, Reactions, Repo, Title, Priority}
+
+// Order is a sort field and direction
+type Order struct {
+	Field Field `json:"field"`
+	Desc  bool  `json:"desc,omitempty"`
+}
+
+// Default is newest first
+var Default = Order{Field: Created, Desc: true}
+
+// Parse reads an order such as "updated", "title-asc" or "comments-desc".
+// Without a direction, dates and counts sort descending and text ascending.
+func Parse(text string) (Order, error) {
+	name, dir, _ := strings.Cut(strings.ToLower(strings.TrimSpace(text)), "-")
+	field := Field(name)
+	if !slices.Contains(Fields, field) {
+		return Order{}, fmt.Errorf("unknown sort field %q", name)
+	}
+
+	order := By(field)
+	switch dir {
+	case "":
+	case "asc":
+		order.Desc = false
+	case "desc":
+		order.Desc = true
+	default:
+		return Order{}, fmt.Errorf("unknown sort direction %q", dir)
+	}
+	return order, nil
+}
+
+// String returns the order in the form Parse reads, e.g. "title-asc"
+func (o Order) String() string {
+	if o.Desc {
+		return string(o.Field) + "-desc"
+	}
+	return string(o.Field) + "-asc"
+}
+
+// Valid reports whether the order has a known field
+func (o Order) Valid() bool {
+	return slices.Contains(Fields, o.Field)
+}
+
+// By returns the order by field in its default direction
+func By(field Field) Order {
+	return Order{Field: field, Desc: field.descByDefault()}
+}
+
+// Next returns the order by the following field
+func (o Order) Next() Order {
+	return By(Fields[(slices.Index(Fields, o.Field)+1)%len(Fields)])
+}
+
+// Reverse returns the order in the other direction
+func (o Order) Reverse() Order {
+	o.Desc = !o.Desc
+	return o
+}
+
+// descByDefault is whether biggest, newest or most urgent comes first
+func (f Field) descByDefault() bool {
+	return f != Repo && f != Title
+}
+
+// Sort sorts issues in order, breaking ties newest first, then by org and repo
+func Sort(issues []types.Issue, order Order) {
+	slices.SortStableFunc(issues, func(a, b types.Issue) int {
+		c := compare(a, b, order.Field)
+		if order.Desc {
+			c = -c
+		}
+		if c != 0 {
+			return c
+		}
+		return cmp.Or(
+			b.CreatedAt.Compare(a.CreatedAt),
+			cmp.Compare(a.Org, b.Org),
+			cmp.Compare(a.Repo, b.Repo),
+		)
+	})
+}
+
+// compare orders two issues ascending by field
+func compare(a, b types.Issue, field Field) int {
+	switch field {
+	case Created:
+		return a.CreatedAt.Compare(b.CreatedAt)
+	case Updated:
+		return a.UpdatedAt.Compare(b.UpdatedAt)
+	case Comments:
+		return cmp.Compare(a.Comments, b.Comments)
+	case Reactions:
+		return cmp.Compare(a.Reactions.TotalCount, b.Reactions.TotalCount)
+	case Repo:
+		return cmp.Or(
+			cmp.Compare(strings.ToLower(a.Org), strings.ToLower(b.Org)),
+			cmp.Compare(strings.ToLower(a.Repo), strings.ToLower(b.Repo)),
+		)
+	case Title:
+		return cmp.Compare(strings.ToLower(a.Title), strings.ToLower(b.Title))
+	case Priority:
+		// Ascending is least urgent first, so a lower level is greater
+		return cmp.Compare(urgency(a), urgency(b))
+	}
+	return 0
+}
+
+// noPriority is the level of issues without a priority label
+const noPriority = 100
+
+// urgency orders priority levels, with unprioritised issues least urgent
+func urgency(issue types.Issue) int {
+	return noPriority - priorityLevel(issue)
+}
+
+var (
+	priorityNumber = regexp.MustCompile(`^(?:priority[\s:/_-]*)?p-?(\d)$|^priority[\s:/_-]*(\d)$`)
+	priorityWord   = regexp.MustCompile(`^priority[\s:/_-]*(critical|urgent|highest|high|medium|normal|low|lowest)$`)
+	priorityWords  = map[string]int{
+		"critical": 0, "urgent": 0, "highest": 0,
+		"high":   1,
+		"medium": 2, "normal": 2,
+		"low": 3, "lowest": 4,
+	}
+)
+
+// priorityLevel returns the most urgent priority label on the issue, where
+// 0 is most urgent, e.g. "P0", "priority: high" or "priority/2", or
+// noPriority without one
+func priorityLevel(issue types.Issue) int {
+	level := noPriority
+	for _, label := range issue.Labels {
+		name := strings.ToLower(strings.TrimSpace(label.Name))
+		if match := priorityNumber.FindStringSubmatch(name); match != nil {
+			n, _ := strconv.Atoi(match[1] + match[2])
+			level = min(level, n)
+		} else if match := priorityWord.FindStringSubmatch(name); match != nil {
+			level = min(level, priorityWords[match[1]])
+		}
+	}
+	return level
+}
@@ -0,0 +1,144 @@
+package sorting
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/shaunmolloy/bugbox/internal/types"
+)
+
+func ids(issues []types.Issue) []int {
+	out := make([]int, len(issues))
+	for i, issue := range issues {
+		out[i] = issue.ID
+	}
+	return out
+}
+
+func labels(names ...string) []types.Label {
+	out := make([]types.Label, len(names))
+	for i, name := range names {
+		out[i] = types.Label{Name: name}
+	}
+	return out
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input string
+		want  Order
+	}{
+		{"created", Order{Field: Created, Desc: true}},
+		{"Updated-ASC", Order{Field: Updated}},
+		{"title", Order{Field: Title}},
+		{"title-desc", Order{Field: Title, Desc: true}},
+		{"priority", Order{Field: Priority, Desc: true}},
+	}
+
+	for _, test := range tests {
+		t.Run("returns the order for "+test.input, func(t *testing.T) {
+			got, err := Parse(test.input)
+			if err != nil {
+				t.Fatalf("expected nil, got error: %v", err)
+			}
+			if got != test.want {
+				t.Errorf("expected %v, got %v", test.want, got)
+			}
+		})
+	}
+
+	t.Run("returns an error for an unknown field or direction", func(t *testing.T) {
+		for _, input := range []string{"", "stars", "title-up"} {
+			if _, err := Parse(input); err == nil {
+				t.Errorf("expected error for %q, got nil", input)
+			}
+		}
+	})
+
+	t.Run("reads what String writes", func(t *testing.T) {
+		for _, field := range Fields {
+			for _, order := range []Order{{Field: field}, {Field: field, Desc: true}} {
+				got, err := Parse(order.String())
+				if err != nil || got != order {
+					t.Errorf("expected %v, got %v (%v)", order, got, err)
+				}
+			}
+		}
+	})
+}
+
+func TestNext(t *testing.T) {
+	t.Run("cycles through every field", func(t *testing.T) {
+		order := Default
+		for range Fields {
+			order = order.Next()
+		}
+		if order != Default {
+			t.Errorf("expected %v, got %v", Default, order)
+		}
+	})
+}
+
+func TestSort(t *testing.T) {
+	now := time.Now()
+	issues := []types.Issue{
+		{ID: 1, Org: "acme", Repo: "web", Title: "beta", CreatedAt: now.Add(-3 * time.Hour), UpdatedAt: now, Comments: 5, Labels: labels("P2")},
+		{ID: 2, Org: "acme", Repo: "api", Title: "Alpha", CreatedAt: now.Add(-1 * time.Hour), UpdatedAt: now.Add(-2 * time.Hour), Reactions: types.Reactions{TotalCount: 3}},
+		{ID: 3, Org: "zeta", Repo: "api", Title: "gamma", CreatedAt: now.Add(-2 * time.Hour), UpdatedAt: now.Add(-1 * time.Hour), Comments: 1, Labels: labels("bug", "priority: critical")},
+	}
+
+	tests := []struct {
+		order string
+		want  []int
+	}{
+		{"created", []int{2, 3, 1}},
+		{"created-asc", []int{1, 3, 2}},
+		{"updated", []int{1, 3, 2}},
+		{"comments", []int{1, 3, 2}},
+		{"reactions", []int{2, 3, 1}}, // ties newest first
+		{"repo", []int{2, 1, 3}},
+		{"title", []int{2, 1, 3}},
+		{"priority", []int{3, 1, 2}},
+		{"priority-asc", []int{2, 1, 3}},
+	}
+
+	for _, test := range tests {
+		t.Run("sorts by "+test.order, func(t *testing.T) {
+			order, err := Parse(test.order)
+			if err != nil {
+				t.Fatalf("expected nil, got error: %v", err)
+			}
+			sorted := slices.Clone(issues)
+			Sort(sorted, order)
+			if got := ids(sorted); !slices.Equal(got, test.want) {
+				t.Errorf("expected %v, got %v", test.want, got)
+			}
+		})
+	}
+}
+
+func TestPriorityLevel(t *testing.T) {
+	tests := []struct {
+		labels []types.Label
+		want   int
+	}{
+		{labels("P0"), 0},
+		{labels("p3"), 3},
+		{labels("P-1"), 1},
+		{labels("priority: high"), 1},
+		{labels("Priority/Low"), 3},
+		{labels("priority-2"), 2},
+		{labels("bug", "P3", "P1"), 1},
+		{labels("bug", "pizza", "p10"), noPriority},
+		{nil, noPriority},
+	}
+
+	for _, test := range tests {
+		t.Run("returns the level for labels", func(t *testing.T) {
+			if got := priorityLevel(types.Issue{Labels: test.labels}); got != test.want {
+				t.Errorf("expected %d for %v, got %d", test.want, test.labels, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package status
+
+import (
+	"sync"
+	"time"
+)
+
+// OrgStatus is the sync state of one org
+type OrgStatus struct {
+	LastSync    time.Time `json:"last_sync"`
+	LastError   string    `json:"last_error,omitempty"`
+	LastErrorAt time.Time `json:"last_error_at"`
+}
+
+// Error is a failure shown in the TUI's error panel
+type Error struct {
+	Org     string    `json:"org,omitempty"` // empty when not specific to an org
+	Message string    `json:"message"`
+	At      time.Time `json:"at"`
+}
+
+// maxErrors limits how many recent errors are kept
+const maxErrors = 50
+
+// Snapshot is a copy of the sync state, safe to read without locking
+type Snapshot struct {
+	Syncing  bool                 `json:"syncing"`
+	Current  string               `json:"current,omitempty"` // org most recently started
+	Done     int                  `json:"done"`
+	Total    int                  `json:"total"`
+	LastSync time.Time            `json:"last_sync"`
+	Orgs     map[string]OrgStatus `json:"orgs"`
+	// LastError is the most recent failure across all orgs
+	LastError   string    `json:"last_error,omitempty"`
+	LastErrorAt time.Time `json:"last_error_at"`
+	// Errors are recent failures not yet dismissed, newest first
+	Errors []Error `json:"errors"`
+	// Started is when tracking began, the baseline for orgs never synced
+	Started time.Time `json:"started"`
+}
+
+// Stale returns the orgs that haven't synced successfully for longer than
+// their threshold from after
+func (s Snapshot) Stale(orgs []string, after func(org string) time.Duration, now time.Time) []string {
+	var stale []string
+	for _, org := range orgs {
+		last := s.Orgs[org].LastSync
+		if last.IsZero() {
+			last = s.Started
+		}
+		if now.Sub(last) > after(org) {
+			stale = append(stale, org)
+		}
+	}
+	return stale
+}
+
+// Tracker records sync progress as the scheduler fetches orgs
+type Tracker struct {
+	mu    sync.Mutex
+	state Snapshot
+	now   func() time.Time
+	// failed is set when an error is recorded during the current sync
+	failed bool
+	// Changed receives a signal whenever the state changes
+	Changed chan struct{}
+}
+
+// Sync tracks the scheduler's fetches for the TUI
+var Sync = NewTracker()
+
+func NewTracker() *Tracker {
+	return &Tracker{
+		state:   Snapshot{Orgs: make(map[string]OrgStatus), Started: time.Now()},
+		now:     time.Now,
+		Changed: make(chan struct{}, 1),
+	}
+}
+
+// Start marks the beginning of a sync of total orgs
+func (t *Tracker) Start(total int) {
+	t.update(func(s *Snapshot) {
+		s.Syncing = true
+		s.Current = ""
+		s.Done = 0
+		s.Total = total
+		t.failed = false
+	})
+}
+
+// OrgStarted records that org is being fetched
+func (t *Tracker) OrgStarted(org string) {
+	t.update(func(s *Snapshot) {
+		s.Current = org
+	})
+}
+
+// OrgFinished records the progress of fetching org, and any error
+func (t *Tracker) OrgFinished(org string, err error) {
+	t.update(func(s *Snapshot) {
+		s.Done++
+		if err != nil {
+			orgStatus := s.Orgs[org]
+			orgStatus.LastError = err.Error()
+			orgStatus.LastErrorAt = t.now()
+			s.Orgs[org] = orgStatus
+			t.addError(s, org, err)
+		}
+	})
+}
+
+// OrgSynced records that org's fetched issues were saved
+func (t *Tracker) OrgSynced(org string) {
+	t.update(func(s *Snapshot) {
+		orgStatus := s.Orgs[org]
+		orgStatus.LastSync = t.now()
+		orgStatus.LastError = ""
+		s.Orgs[org] = orgStatus
+	})
+}
+
+// Error records a failure outside of fetching an org, e.g. saving issues
+func (t *Tracker) Error(org string, err error) {
+	t.update(func(s *Snapshot) {
+		t.addError(s, org, err)
+	})
+}
+
+// DismissErrors clears the recent errors once they've been seen
+func (t *Tracker) DismissErrors() {
+	t.update(func(s *Snapshot) {
+		s.Errors = nil
+		s.LastError = ""
+		s.LastErrorAt = time.Time{}
+	})
+}
+
+func (t *Tracker) addError(s *Snapshot, org string, err error) {
+	entry := Error{Org: org, Message: err.Error(), At: t.now()}
+	t.failed = true
+
+	s.LastError = entry.Message
+	if org != "" {
+		s.LastError = org + ": " + entry.Message
+	}
+	s.LastErrorAt = entry.At
+
+	s.Errors = append([]Error{entry}, s.Errors...)
+	if len(s.Errors) > maxErrors {
+		s.Errors = s.Errors[:maxErrors]
+	}
+}
+
+// Finish marks the end of a sync. A sync without errors is recorded as the
+// last successful one and clears the last error. Recent errors are kept
+// until dismissed.
+func (t *Tracker) Finish() {
+	t.update(func(s *Snapshot) {
+		s.Syncing = false
+		s.Current = ""
+		if !t.failed {
+			s.LastSync = t.now()
+			s.LastError = ""
+			s.LastErrorAt = time.Time{}
+		}
+	})
+}
+
+// Syncing returns true while a sync is in progress
+func (t *Tracker) Syncing() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.state.Syncing
+}
+
+// Get returns a copy of the current state
+func (t *Tracker) Get() Snapshot {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	snapshot := t.state
+	snapshot.Orgs = make(map[string]OrgStatus, len(t.state.Orgs))
+	for org, orgStatus := range t.state.Orgs {
+		snapshot.Orgs[org] = orgStatus
+	}
+	snapshot.Errors = append([]Error(nil), t.state.Errors...)
+	return snapshot
+}
+
+func (t *Tracker) update(fn func(s *Snapshot)) {
+	t.mu.Lock()
+	fn(&t.state)
+	t.mu.Unlock()
+
+	// Non-blocking, one pending signal is enough
+	select {
+	case t.Changed <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,193 @@
+package status
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestTracker(t *testing.T) {
+	now := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	newTracker := func() *Tracker {
+		tracker := NewTracker()
+		tracker.now = func() time.Time { return now }
+		return tracker
+	}
+
+	t.Run("tracks progress through a sync", func(t *testing.T) {
+		tracker := newTracker()
+
+		tracker.Start(2)
+		tracker.OrgStarted("one")
+		tracker.OrgFinished("one", nil)
+		tracker.OrgSynced("one")
+		tracker.OrgStarted("two")
+
+		got := tracker.Get()
+		if !got.Syncing || got.Current != "two" || got.Done != 1 || got.Total != 2 {
+			t.Errorf("unexpected progress %+v", got)
+		}
+		if !got.Orgs["one"].LastSync.Equal(now) {
+			t.Errorf("got %v, want %v", got.Orgs["one"].LastSync, now)
+		}
+
+		tracker.OrgFinished("two", nil)
+		tracker.Finish()
+		if tracker.Syncing() {
+			t.Fatal("expected sync to be finished")
+		}
+	})
+
+	t.Run("records the last error per org and overall", func(t *testing.T) {
+		tracker := newTracker()
+
+		tracker.Start(1)
+		tracker.OrgFinished("one", fmt.Errorf("401 Unauthorized"))
+		tracker.Finish()
+
+		got := tracker.Get()
+		if got.LastError != "one: 401 Unauthorized" {
+			t.Errorf("got %q, want %q", got.LastError, "one: 401 Unauthorized")
+		}
+		if got.Orgs["one"].LastError != "401 Unauthorized" || !got.Orgs["one"].LastSync.IsZero() {
+			t.Errorf("unexpected org status %+v", got.Orgs["one"])
+		}
+	})
+
+	t.Run("only records syncs without errors as successful", func(t *testing.T) {
+		tracker := newTracker()
+
+		tracker.Start(1)
+		tracker.OrgFinished("one", nil)
+		tracker.Error("", fmt.Errorf("disk full"))
+		tracker.Finish()
+
+		got := tracker.Get()
+		if !got.LastSync.IsZero() || !got.Orgs["one"].LastSync.IsZero() {
+			t.Errorf("expected no successful sync, got %+v", got)
+		}
+
+		tracker.Start(1)
+		tracker.OrgFinished("one", nil)
+		tracker.OrgSynced("one")
+		tracker.Finish()
+
+		got = tracker.Get()
+		if !got.LastSync.Equal(now) || !got.Orgs["one"].LastSync.Equal(now) {
+			t.Errorf("expected a successful sync at %v, got %+v", now, got)
+		}
+	})
+
+	t.Run("clears the last error after a sync without errors", func(t *testing.T) {
+		tracker := newTracker()
+
+		tracker.Start(1)
+		tracker.OrgFinished("one", fmt.Errorf("401 Unauthorized"))
+		tracker.Finish()
+		tracker.Start(1)
+		tracker.OrgFinished("one", nil)
+		tracker.Finish()
+
+		got := tracker.Get()
+		if got.LastError != "" || !got.LastErrorAt.IsZero() {
+			t.Errorf("expected no last error, got %q", got.LastError)
+		}
+		if len(got.Errors) != 1 {
+			t.Errorf("expected recent errors to be kept, got %+v", got.Errors)
+		}
+	})
+
+	t.Run("signals changes without blocking", func(t *testing.T) {
+		tracker := newTracker()
+
+		tracker.Start(1)
+		tracker.Finish()
+
+		select {
+		case <-tracker.Changed:
+		default:
+			t.Fatal("expected a change signal")
+		}
+	})
+
+	t.Run("returns a copy of org statuses", func(t *testing.T) {
+		tracker := newTracker()
+		tracker.OrgSynced("one")
+
+		got := tracker.Get()
+		got.Orgs["one"] = OrgStatus{}
+
+		if tracker.Get().Orgs["one"].LastSync.IsZero() {
+			t.Fatal("expected tracker state to be unchanged")
+		}
+	})
+
+	t.Run("keeps recent errors newest first until dismissed", func(t *testing.T) {
+		tracker := newTracker()
+
+		tracker.OrgFinished("one", fmt.Errorf("401 Unauthorized"))
+		tracker.Error("", fmt.Errorf("disk full"))
+
+		got := tracker.Get().Errors
+		if len(got) != 2 || got[0].Message != "disk full" || got[1].Org != "one" {
+			t.Fatalf("unexpected errors %+v", got)
+		}
+
+		tracker.DismissErrors()
+		if got := tracker.Get(); len(got.Errors) != 0 || got.LastError != "" {
+			t.Errorf("expected errors to be dismissed, got %+v", got)
+		}
+	})
+
+	t.Run("limits how many errors are kept", func(t *testing.T) {
+		tracker := newTracker()
+		for i := 0; i < maxErrors+5; i++ {
+			tracker.Error("", fmt.Errorf("error %d", i))
+		}
+
+		if got := len(tracker.Get().Errors); got != maxErrors {
+			t.Errorf("got %d errors, want %d", got, maxErrors)
+		}
+	})
+}
+
+func TestSnapshotStale(t *testing.T) {
+	now := time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC)
+	snapshot := Snapshot{
+		Started: now.Add(-2 * time.Hour),
+		Orgs: map[string]OrgStatus{
+			"fresh": {LastSync: now.Add(-10 * time.Minute)},
+			"old":   {LastSync: now.Add(-90 * time.Minute)},
+		},
+	}
+
+	hour := func(string) time.Duration { return time.Hour }
+
+	t.Run("returns orgs not synced within the threshold", func(t *testing.T) {
+		got := snapshot.Stale([]string{"fresh", "old", "never"}, hour, now)
+		if !slices.Equal(got, []string{"old", "never"}) {
+			t.Errorf("got %v, want [old never]", got)
+		}
+	})
+
+	t.Run("returns none for orgs never synced since a recent start", func(t *testing.T) {
+		recent := Snapshot{Started: now.Add(-time.Minute)}
+		if got := recent.Stale([]string{"never"}, hour, now); len(got) != 0 {
+			t.Errorf("expected no stale orgs, got %v", got)
+		}
+	})
+
+	t.Run("uses each org's threshold", func(t *testing.T) {
+		after := func(org string) time.Duration {
+			if org == "old" {
+				return 2 * time.Hour
+			}
+			return time.Hour
+		}
+		if got := snapshot.Stale([]string{"fresh", "old"}, after, now); len(got) != 0 {
+			t.Errorf("expected no stale orgs, got %v", got)
+		}
+	})
+}
@@ -0,0 +1,92 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+)
+
+// ColumnNames lists the columns the TUI issues table can show
+var ColumnNames = []string{
+	"title", "number", "labels", "org", "repo", "assignee", "author",
+	"comments", "reactions", "created", "updated", "state", "milestone",
+}
+
+// Column is a column of the TUI issues table
+type Column struct {
+	Name string `json:"name"`
+	// Width fixes the column to this many characters, otherwise the
+	// column shares the spare width by its Expand ratio
+	Width  int `json:"width,omitempty"`
+	Expand int `json:"expand,omitempty"`
+	// HideBelow and HideAbove hide the column when the terminal is narrower
+	// or wider than this many characters
+	HideBelow int `json:"hide_below,omitempty"`
+	HideAbove int `json:"hide_above,omitempty"`
+}
+
+// DefaultColumns are shown when Columns isn't set
+var DefaultColumns = []Column{
+	{Name: "title", Expand: 8},
+	{Name: "org", Expand: 1},
+	{Name: "repo", Expand: 1, HideBelow: 130},
+	{Name: "created", Expand: 1},
+}
+
+// TableColumns returns the configured columns, or the defaults when unset
+// or invalid
+func (c Config) TableColumns() []Column {
+	if len(c.Columns) > 0 && len(c.ColumnErrors()) == 0 {
+		return c.Columns
+	}
+	return DefaultColumns
+}
+
+// ColumnErrors returns problems with the configured columns, which are
+// replaced by the defaults
+func (c Config) ColumnErrors() ValidationErrors {
+	var errs ValidationErrors
+	for i, column := range c.Columns {
+		field := fmt.Sprintf("columns[%d]", i)
+		if !slices.Contains(ColumnNames, column.Name) {
+			errs = append(errs, ValidationError{Field: field + ".name", Message: fmt.Sprintf("Unknown column %q", column.Name)})
+		}
+		if column.Width < 0 || column.Expand < 0 || column.HideBelow < 0 || column.HideAbove < 0 {
+			errs = append(errs, ValidationError{Field: field, Message: "Negative width"})
+		}
+	}
+
+	// Columns only appear or disappear at their breakpoints, so a terminal
+	// width with nothing shown starts at 1 or just past a hide_above, or
+	// ends just before a hide_below
+	if len(c.Columns) > 0 {
+		widths := []int{1}
+		for _, column := range c.Columns {
+			widths = append(widths, column.HideAbove+1, column.HideBelow-1)
+		}
+		for _, width := range widths {
+			if width >= 1 && !slices.ContainsFunc(c.Columns, func(column Column) bool { return column.Visible(width) }) {
+				errs = append(errs, ValidationError{Field: "columns", Message: fmt.Sprintf("No columns shown %d characters wide", width)})
+				break
+			}
+		}
+	}
+	return errs
+}
+
+// Visible returns true if the column is shown on a terminal this wide
+func (c Column) Visible(screenWidth int) bool {
+	return (c.HideBelow == 0 || screenWidth >= c.HideBelow) &&
+		(c.HideAbove == 0 || screenWidth <= c.HideAbove)
+}
+
+// Expansion returns the column's share of the spare width, none when it has
+// a fixed width
+func (c Column) Expansion() int {
+	switch {
+	case c.Width > 0:
+		return 0
+	case c.Expand > 0:
+		return c.Expand
+	}
+	return 1
+}
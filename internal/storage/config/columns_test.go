@@ -0,0 +1,65 @@
+package config
+
+import "testing"
+
+func TestTableColumns(t *testing.T) {
+	t.Run("returns the defaults when unset", func(t *testing.T) {
+		if got := (Config{}).TableColumns(); len(got) != len(DefaultColumns) {
+			t.Errorf("expected %d columns, got %d", len(DefaultColumns), len(got))
+		}
+	})
+
+	t.Run("returns the defaults when invalid", func(t *testing.T) {
+		conf := Config{Columns: []Column{{Name: "votes"}}}
+		if got := conf.TableColumns(); len(got) != len(DefaultColumns) {
+			t.Errorf("expected %d columns, got %d", len(DefaultColumns), len(got))
+		}
+	})
+
+	t.Run("returns the configured columns", func(t *testing.T) {
+		conf := Config{Columns: []Column{{Name: "number"}, {Name: "title"}}}
+		if got := conf.TableColumns(); len(got) != 2 || got[0].Name != "number" {
+			t.Errorf("expected configured columns, got %+v", got)
+		}
+	})
+}
+
+func TestColumnVisible(t *testing.T) {
+	tests := []struct {
+		name   string
+		column Column
+		width  int
+		want   bool
+	}{
+		{"always shown without breakpoints", Column{}, 40, true},
+		{"hidden below the breakpoint", Column{HideBelow: 100}, 99, false},
+		{"shown at the breakpoint", Column{HideBelow: 100}, 100, true},
+		{"hidden above the breakpoint", Column{HideAbove: 100}, 101, false},
+		{"shown between breakpoints", Column{HideBelow: 80, HideAbove: 120}, 100, true},
+	}
+
+	for _, test := range tests {
+		t.Run("returns "+test.name, func(t *testing.T) {
+			if got := test.column.Visible(test.width); got != test.want {
+				t.Errorf("expected %t, got %t", test.want, got)
+			}
+		})
+	}
+}
+
+func TestColumnExpansion(t *testing.T) {
+	t.Run("returns 0 for fixed widths", func(t *testing.T) {
+		if got := (Column{Width: 10, Expand: 3}).Expansion(); got != 0 {
+			t.Errorf("expected 0, got %d", got)
+		}
+	})
+
+	t.Run("returns the ratio, or 1 by default", func(t *testing.T) {
+		if got := (Column{Expand: 3}).Expansion(); got != 3 {
+			t.Errorf("expected 3, got %d", got)
+		}
+		if got := (Column{}).Expansion(); got != 1 {
+			t.Errorf("expected 1, got %d", got)
+		}
+	})
+}
@@ -4,33 +4,73 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
-	"path/filepath"
+
+	"github.com/shaunmolloy/bugbox/internal/query"
+	"github.com/shaunmolloy/bugbox/internal/storage/paths"
 )
 
-var ConfigPath = filepath.Join(os.Getenv("HOME"), ".config", "bugbox", "config.json")
+// ConfigPath is the location of config.json, set by Init at startup
+var ConfigPath string
+
+// Init points the config package at the resolved paths
+func Init(p paths.Paths) error {
+	ConfigPath = p.ConfigFile
+	IssuesPath = p.IssuesFile()
+	return migrateIssues(p.LegacyIssuesFile(), IssuesPath)
+}
 
 // Validate checks config.json exists with expected structure
 func Validate() error {
 	file, err := os.Open(ConfigPath)
 	if err != nil {
-		return fmt.Errorf("File not found")
+		return ValidationErrors{{Field: "config", Message: "File not found"}}
 	}
 	defer file.Close()
 
 	var config Config
 	if err := json.NewDecoder(file).Decode(&config); err != nil {
-		return fmt.Errorf("Invalid JSON format")
+		return ValidationErrors{{Field: "config", Message: "Invalid JSON format"}}
 	}
 
-	if config.GitHubToken == "" {
-		return fmt.Errorf("Missing github_token")
+	if errs := config.Validate(); len(errs) > 0 {
+		return errs
 	}
+	return nil
+}
+
+// Validate returns the problems that stop bugbox running, such as a missing
+// token, empty when setup is complete. Other settings are checked by Check.
+func (c Config) Validate() ValidationErrors {
+	var errs ValidationErrors
 
-	if len(config.Orgs) == 0 {
-		return fmt.Errorf("Missing orgs")
+	if c.GitHubToken == "" && c.Token.Source == "" {
+		errs = append(errs, ValidationError{Field: "token", Message: "Missing token"})
 	}
 
-	return nil
+	if len(c.Orgs) == 0 {
+		errs = append(errs, ValidationError{Field: "orgs", Message: "Missing orgs"})
+	}
+
+	return errs
+}
+
+// Check returns problems with settings that bugbox works around, such as a
+// saved search that doesn't parse, empty when there are none
+func (c Config) Check() ValidationErrors {
+	var errs ValidationErrors
+
+	for i, search := range c.Searches {
+		field := fmt.Sprintf("searches[%d]", i)
+		if search.Name == "" {
+			errs = append(errs, ValidationError{Field: field + ".name", Message: "Missing name"})
+		}
+		if _, err := query.Parse(search.Query); err != nil {
+			errs = append(errs, ValidationError{Field: field + ".query", Message: err.Error()})
+		}
+	}
+
+	errs = append(errs, c.ColumnErrors()...)
+	return errs
 }
 
 // SaveConfig saves the config to config.json
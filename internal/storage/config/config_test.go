@@ -2,9 +2,34 @@ package config
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
+
+	"github.com/shaunmolloy/bugbox/internal/storage/paths"
 )
 
+func TestInit(t *testing.T) {
+	t.Run("sets config and issues paths", func(t *testing.T) {
+		dir := t.TempDir()
+		p := paths.Paths{
+			ConfigFile: filepath.Join(dir, "config", "config.json"),
+			ConfigDir:  filepath.Join(dir, "config"),
+			DataDir:    filepath.Join(dir, "data"),
+		}
+
+		if err := Init(p); err != nil {
+			t.Fatal("expected nil, got error")
+		}
+
+		if ConfigPath != p.ConfigFile {
+			t.Errorf("got %q, want %q", ConfigPath, p.ConfigFile)
+		}
+		if IssuesPath != p.IssuesFile() {
+			t.Errorf("got %q, want %q", IssuesPath, p.IssuesFile())
+		}
+	})
+}
+
 func TestValidate(t *testing.T) {
 	t.Run("returns error for missing config", func(t *testing.T) {
 		ConfigPath = "./config.json"
@@ -55,6 +80,38 @@ func TestValidate(t *testing.T) {
 		}
 	})
 
+	t.Run("returns nil for invalid saved searches, so they don't block startup", func(t *testing.T) {
+		content := `{
+			"github_token": "example",
+			"orgs": ["example"],
+			"searches": [{"name": "Broken", "query": "label:"}]
+		}`
+
+		tmpFile := createTmpFile(t, content)
+		defer os.Remove(tmpFile.Name())
+
+		ConfigPath = tmpFile.Name()
+		if err := Validate(); err != nil {
+			t.Fatalf("expected nil, got error: %v", err)
+		}
+	})
+
+	t.Run("returns nil for invalid columns, so they don't block startup", func(t *testing.T) {
+		content := `{
+			"github_token": "example",
+			"orgs": ["example"],
+			"columns": [{"name": "votes"}]
+		}`
+
+		tmpFile := createTmpFile(t, content)
+		defer os.Remove(tmpFile.Name())
+
+		ConfigPath = tmpFile.Name()
+		if err := Validate(); err != nil {
+			t.Fatalf("expected nil, got error: %v", err)
+		}
+	})
+
 	t.Run("returns nil for valid config", func(t *testing.T) {
 		content := `{
 			"github_token": "example",
@@ -71,6 +128,57 @@ func TestValidate(t *testing.T) {
 	})
 }
 
+func TestCheck(t *testing.T) {
+	t.Run("returns errors for invalid saved searches", func(t *testing.T) {
+		conf := Config{Searches: []SavedSearch{{Name: "", Query: "label:bug"}, {Name: "Broken", Query: "label:"}}}
+
+		errs := conf.Check()
+		if len(errs) != 2 {
+			t.Fatalf("expected 2 errors, got %v", errs)
+		}
+		if errs[0].Field != "searches[0].name" || errs[1].Field != "searches[1].query" {
+			t.Errorf("unexpected fields %+v", errs)
+		}
+	})
+
+	t.Run("returns errors for invalid columns", func(t *testing.T) {
+		conf := Config{Columns: []Column{{Name: "title"}, {Name: "votes"}, {Name: "repo", Width: -1}}}
+
+		errs := conf.Check()
+		if len(errs) != 2 {
+			t.Fatalf("expected 2 errors, got %v", errs)
+		}
+		if errs[0].Field != "columns[1].name" || errs[1].Field != "columns[2]" {
+			t.Errorf("unexpected fields %+v", errs)
+		}
+	})
+
+	t.Run("returns an error when some widths show no columns", func(t *testing.T) {
+		tests := [][]Column{
+			{{Name: "title", HideBelow: 100}},
+			{{Name: "title", HideAbove: 100}},
+			{{Name: "title", HideBelow: 150}, {Name: "org", HideAbove: 100}},
+		}
+		for _, columns := range tests {
+			if errs := (Config{Columns: columns}).Check(); len(errs) != 1 || errs[0].Field != "columns" {
+				t.Errorf("expected a columns error for %+v, got %v", columns, errs)
+			}
+		}
+
+		covered := []Column{{Name: "title", HideBelow: 100}, {Name: "org", HideAbove: 99}}
+		if errs := (Config{Columns: covered}).Check(); len(errs) != 0 {
+			t.Errorf("expected no errors, got %v", errs)
+		}
+	})
+
+	t.Run("returns nil for valid saved searches", func(t *testing.T) {
+		conf := Config{Searches: []SavedSearch{{Name: "Bugs", Query: "label:bug"}}}
+		if errs := conf.Check(); len(errs) != 0 {
+			t.Errorf("expected no errors, got %v", errs)
+		}
+	})
+}
+
 func TestSaveConfig(t *testing.T) {
 	t.Run("returns nil when saving config", func(t *testing.T) {
 		content := `{
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/shaunmolloy/bugbox/internal/types"
+)
+
+// Hook defaults, used when a hook doesn't set its own rate limit
+const (
+	DefaultHookRateLimit  = 5
+	DefaultHookRatePeriod = time.Minute
+)
+
+// IssueFilter matches issues by org, repo and label. Empty fields match
+// every issue, and an issue matches when it has any of the labels.
+type IssueFilter struct {
+	Orgs   []string `json:"orgs,omitempty"`
+	Repos  []string `json:"repos,omitempty"` // "repo" or "org/repo"
+	Labels []string `json:"labels,omitempty"`
+}
+
+// Match returns true if issue passes the filter
+func (f IssueFilter) Match(issue types.Issue) bool {
+	if len(f.Orgs) > 0 && !slices.Contains(f.Orgs, issue.Org) {
+		return false
+	}
+
+	if len(f.Repos) > 0 &&
+		!slices.Contains(f.Repos, issue.Repo) &&
+		!slices.Contains(f.Repos, issue.Org+"/"+issue.Repo) {
+		return false
+	}
+
+	if len(f.Labels) > 0 && !slices.ContainsFunc(issue.Labels, func(label types.Label) bool {
+		return slices.ContainsFunc(f.Labels, func(name string) bool {
+			return strings.EqualFold(name, label.Name)
+		})
+	}) {
+		return false
+	}
+
+	return true
+}
+
+// HookConfig runs a command or shows a notification when new issues match
+type HookConfig struct {
+	Name   string      `json:"name,omitempty"`
+	Filter IssueFilter `json:"filter"`
+	// Command is run with sh -c for each issue, given the issue as JSON on stdin
+	Command string `json:"command,omitempty"`
+	// Notify shows a desktop notification for each issue
+	Notify bool `json:"notify,omitempty"`
+	// RateLimit caps how many issues fire the hook per RatePeriod
+	RateLimit  int      `json:"rate_limit,omitempty"`
+	RatePeriod Duration `json:"rate_period,omitempty"`
+}
+
+// Limit returns the hook's rate limit, or the defaults
+func (h HookConfig) Limit() (int, time.Duration) {
+	limit, period := h.RateLimit, time.Duration(h.RatePeriod)
+	if limit <= 0 {
+		limit = DefaultHookRateLimit
+	}
+	if period <= 0 {
+		period = DefaultHookRatePeriod
+	}
+	return limit, period
+}
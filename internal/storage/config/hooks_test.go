@@ -0,0 +1,39 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/shaunmolloy/bugbox/internal/types"
+)
+
+func TestIssueFilterMatch(t *testing.T) {
+	issue := types.Issue{
+		Org:    "example",
+		Repo:   "api",
+		Labels: []types.Label{{Name: "bug"}, {Name: "P0"}},
+	}
+
+	tests := []struct {
+		name   string
+		filter IssueFilter
+		want   bool
+	}{
+		{"empty filter", IssueFilter{}, true},
+		{"matching org", IssueFilter{Orgs: []string{"other", "example"}}, true},
+		{"other org", IssueFilter{Orgs: []string{"other"}}, false},
+		{"matching repo", IssueFilter{Repos: []string{"api"}}, true},
+		{"matching org/repo", IssueFilter{Repos: []string{"example/api"}}, true},
+		{"other repo", IssueFilter{Repos: []string{"other/api"}}, false},
+		{"any label, ignoring case", IssueFilter{Labels: []string{"p0", "security"}}, true},
+		{"missing label", IssueFilter{Labels: []string{"security"}}, false},
+		{"org and label", IssueFilter{Orgs: []string{"example"}, Labels: []string{"P0"}}, true},
+	}
+
+	for _, test := range tests {
+		t.Run("returns "+test.name, func(t *testing.T) {
+			if got := test.filter.Match(issue); got != test.want {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
@@ -1,17 +1,38 @@
 package config
 
 import (
-	"os"
-	"path/filepath"
+	"sync"
+
+	"github.com/shaunmolloy/bugbox/internal/storage/paths"
 )
 
-var IssuesPath = filepath.Join(os.Getenv("HOME"), ".config", "bugbox", "issues.json")
+// IssuesPath is the location of issues.json, set by Init at startup
+var IssuesPath string
+
+var issuesMu sync.Mutex
 
 // SaveIssues saves issues to a file
 func SaveIssues(cfg Issues) error {
 	return SaveToFile(IssuesPath, cfg)
 }
 
+// UpdateIssues loads, changes and saves issues while holding a lock, so
+// concurrent updates, e.g. a fetch and marking an issue read, aren't lost
+func UpdateIssues(update func(Issues) error) error {
+	issuesMu.Lock()
+	defer issuesMu.Unlock()
+
+	issues, err := LoadIssues()
+	if err != nil || issues == nil {
+		issues = Issues{} // Start afresh when missing or unreadable
+	}
+
+	if err := update(issues); err != nil {
+		return err
+	}
+	return SaveIssues(issues)
+}
+
 // LoadIssues loads issues from a file
 func LoadIssues() (Issues, error) {
 	var cfg Issues
@@ -47,3 +68,8 @@ func PruneInvalidOrgs() error {
 
 	return SaveIssues(issuesConf)
 }
+
+// migrateIssues moves issues.json from its legacy location if needed
+func migrateIssues(legacyPath string, path string) error {
+	return paths.Move(legacyPath, path)
+}
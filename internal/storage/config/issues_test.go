@@ -2,7 +2,11 @@ package config
 
 import (
 	"os"
+	"path/filepath"
+	"sync"
 	"testing"
+
+	"github.com/shaunmolloy/bugbox/internal/types"
 )
 
 func TestSaveIssues(t *testing.T) {
@@ -46,3 +50,91 @@ func TestLoadIssues(t *testing.T) {
 		}
 	})
 }
+
+func TestUpdateIssues(t *testing.T) {
+	t.Run("applies concurrent updates without losing any", func(t *testing.T) {
+		IssuesPath = filepath.Join(t.TempDir(), "issues.json")
+
+		var wg sync.WaitGroup
+		for i := 1; i <= 10; i++ {
+			wg.Add(1)
+			go func(id int) {
+				defer wg.Done()
+				UpdateIssues(func(issues Issues) error {
+					if issues["org"] == nil {
+						issues["org"] = map[string]map[int]types.Issue{"repo": {}}
+					}
+					issues["org"]["repo"][id] = types.Issue{ID: id}
+					return nil
+				})
+			}(i)
+		}
+		wg.Wait()
+
+		issues, err := LoadIssues()
+		if err != nil {
+			t.Fatal("expected nil, got error")
+		}
+		if len(issues["org"]["repo"]) != 10 {
+			t.Errorf("expected 10 issues, got %d", len(issues["org"]["repo"]))
+		}
+	})
+
+	t.Run("does not save when update fails", func(t *testing.T) {
+		IssuesPath = filepath.Join(t.TempDir(), "issues.json")
+
+		err := UpdateIssues(func(issues Issues) error {
+			return os.ErrInvalid
+		})
+		if err == nil {
+			t.Fatal("expected error, got nil")
+		}
+		if exists, _ := IsExist(IssuesPath); exists {
+			t.Fatal("expected no issues file")
+		}
+	})
+}
+
+func TestMigrateIssues(t *testing.T) {
+	t.Run("moves legacy issues to new path", func(t *testing.T) {
+		dir := t.TempDir()
+		legacyPath := filepath.Join(dir, "config", "issues.json")
+		path := filepath.Join(dir, "data", "issues.json")
+
+		if err := os.MkdirAll(filepath.Dir(legacyPath), os.ModePerm); err != nil {
+			t.Fatal("expected nil, got error")
+		}
+		if err := os.WriteFile(legacyPath, []byte("{}"), 0644); err != nil {
+			t.Fatal("expected nil, got error")
+		}
+
+		if err := migrateIssues(legacyPath, path); err != nil {
+			t.Fatal("expected nil, got error")
+		}
+
+		if exists, _ := IsExist(path); !exists {
+			t.Fatal("expected issues at new path")
+		}
+		if exists, _ := IsExist(legacyPath); exists {
+			t.Fatal("expected legacy issues to be removed")
+		}
+	})
+
+	t.Run("keeps existing issues at new path", func(t *testing.T) {
+		dir := t.TempDir()
+		legacyPath := filepath.Join(dir, "legacy.json")
+		path := filepath.Join(dir, "issues.json")
+
+		os.WriteFile(legacyPath, []byte(`{"legacy": {}}`), 0644)
+		os.WriteFile(path, []byte("{}"), 0644)
+
+		if err := migrateIssues(legacyPath, path); err != nil {
+			t.Fatal("expected nil, got error")
+		}
+
+		data, _ := os.ReadFile(path)
+		if string(data) != "{}" {
+			t.Errorf("got %q, want %q", string(data), "{}")
+		}
+	})
+}
@@ -0,0 +1,169 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// field reads and writes one config key for `bugbox config`
+type field struct {
+	get func(c *Config) string
+	set func(c *Config, values []string) error
+}
+
+var fields = map[string]field{
+	"orgs": {
+		get: func(c *Config) string { return strings.Join(c.Orgs, " ") },
+		set: func(c *Config, values []string) error {
+			c.Orgs = SplitList(values)
+			return nil
+		},
+	},
+	"oauth.base_url": {
+		get: func(c *Config) string { return c.OAuth.BaseURL },
+		set: func(c *Config, values []string) error {
+			c.OAuth.BaseURL = strings.Join(values, " ")
+			return nil
+		},
+	},
+	"oauth.client_id": {
+		get: func(c *Config) string { return c.OAuth.ClientID },
+		set: func(c *Config, values []string) error {
+			c.OAuth.ClientID = strings.Join(values, " ")
+			return nil
+		},
+	},
+	"polling.interval":    durationField(func(c *Config) *Duration { return &c.Polling.Interval }),
+	"polling.jitter":      durationField(func(c *Config) *Duration { return &c.Polling.Jitter }),
+	"polling.full_resync": durationField(func(c *Config) *Duration { return &c.Polling.FullResync }),
+	"stale_after":         durationField(func(c *Config) *Duration { return &c.StaleAfter }),
+	"webhooks.addr": {
+		get: func(c *Config) string { return c.Webhooks.Addr },
+		set: func(c *Config, values []string) error {
+			c.Webhooks.Addr = strings.Join(values, " ")
+			return nil
+		},
+	},
+	"webhooks.idle_after": durationField(func(c *Config) *Duration { return &c.Webhooks.IdleAfter }),
+	"webhooks.secret.source": {
+		get: func(c *Config) string { return c.Webhooks.Secret.Source },
+		set: func(c *Config, values []string) error {
+			c.Webhooks.Secret.Source = strings.Join(values, " ")
+			return nil
+		},
+	},
+	"webhooks.secret.value": {
+		get: func(c *Config) string { return c.Webhooks.Secret.Value },
+		set: func(c *Config, values []string) error {
+			c.Webhooks.Secret.Value = strings.Join(values, " ")
+			return nil
+		},
+	},
+	"workers": {
+		get: func(c *Config) string {
+			if c.Workers == 0 {
+				return ""
+			}
+			return strconv.Itoa(c.Workers)
+		},
+		set: func(c *Config, values []string) error {
+			if len(values) == 0 {
+				c.Workers = 0
+				return nil
+			}
+			n, err := strconv.Atoi(strings.Join(values, ""))
+			if err != nil || n < 1 {
+				return fmt.Errorf("workers must be a positive number")
+			}
+			c.Workers = n
+			return nil
+		},
+	},
+	"token.source": {
+		get: func(c *Config) string { return c.Token.Source },
+		set: func(c *Config, values []string) error {
+			c.Token.Source = strings.Join(values, " ")
+			return nil
+		},
+	},
+	"token.value": {
+		get: func(c *Config) string { return c.Token.Value },
+		set: func(c *Config, values []string) error {
+			c.Token.Value = strings.Join(values, " ")
+			return nil
+		},
+	},
+}
+
+// durationField reads and writes a Duration, "" when unset
+func durationField(ptr func(c *Config) *Duration) field {
+	return field{
+		get: func(c *Config) string {
+			if *ptr(c) == 0 {
+				return ""
+			}
+			return time.Duration(*ptr(c)).String()
+		},
+		set: func(c *Config, values []string) error {
+			if len(values) == 0 {
+				*ptr(c) = 0
+				return nil
+			}
+			d, err := time.ParseDuration(strings.Join(values, ""))
+			if err != nil {
+				return err
+			}
+			*ptr(c) = Duration(d)
+			return nil
+		},
+	}
+}
+
+// Keys returns the keys supported by Get, Set and Unset
+func Keys() []string {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Get returns the value of a config key
+func (c *Config) Get(key string) (string, error) {
+	f, ok := fields[key]
+	if !ok {
+		return "", fmt.Errorf("unknown key %q", key)
+	}
+	return f.get(c), nil
+}
+
+// Set updates a config key from one or more values
+func (c *Config) Set(key string, values ...string) error {
+	f, ok := fields[key]
+	if !ok {
+		return fmt.Errorf("unknown key %q", key)
+	}
+	return f.set(c, values)
+}
+
+// Unset clears a config key
+func (c *Config) Unset(key string) error {
+	return c.Set(key)
+}
+
+// SplitList splits space or comma separated values, dropping empty entries
+func SplitList(values []string) []string {
+	var list []string
+	for _, value := range values {
+		for _, item := range strings.FieldsFunc(value, func(r rune) bool {
+			return r == ',' || r == ' '
+		}) {
+			list = append(list, item)
+		}
+	}
+	return list
+}
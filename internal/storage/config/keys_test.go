@@ -0,0 +1,86 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGet(t *testing.T) {
+	t.Run("returns orgs space-separated", func(t *testing.T) {
+		conf := Config{Orgs: []string{"one", "two"}}
+
+		got, err := conf.Get("orgs")
+		if err != nil {
+			t.Fatal("expected nil, got error")
+		}
+		if got != "one two" {
+			t.Errorf("got %q, want %q", got, "one two")
+		}
+	})
+
+	t.Run("returns error for unknown key", func(t *testing.T) {
+		conf := Config{}
+		if _, err := conf.Get("example"); err == nil {
+			t.Fatal("expected error, got nil")
+		}
+	})
+}
+
+func TestSet(t *testing.T) {
+	t.Run("sets orgs from mixed separators", func(t *testing.T) {
+		conf := Config{}
+
+		if err := conf.Set("orgs", "one,two", "three"); err != nil {
+			t.Fatal("expected nil, got error")
+		}
+
+		want := []string{"one", "two", "three"}
+		if !reflect.DeepEqual(conf.Orgs, want) {
+			t.Errorf("got %v, want %v", conf.Orgs, want)
+		}
+	})
+
+	t.Run("sets token source", func(t *testing.T) {
+		conf := Config{}
+
+		if err := conf.Set("token.source", "env"); err != nil {
+			t.Fatal("expected nil, got error")
+		}
+		if conf.Token.Source != "env" {
+			t.Errorf("got %q, want %q", conf.Token.Source, "env")
+		}
+	})
+}
+
+func TestUnset(t *testing.T) {
+	t.Run("clears orgs", func(t *testing.T) {
+		conf := Config{Orgs: []string{"one"}}
+
+		if err := conf.Unset("orgs"); err != nil {
+			t.Fatal("expected nil, got error")
+		}
+		if len(conf.Orgs) != 0 {
+			t.Errorf("got %v, want empty", conf.Orgs)
+		}
+	})
+}
+
+func TestConfigValidate(t *testing.T) {
+	t.Run("returns every invalid field", func(t *testing.T) {
+		errs := Config{}.Validate()
+
+		if len(errs) != 2 {
+			t.Fatalf("expected 2 errors, got %d", len(errs))
+		}
+		if errs[0].Field != "token" || errs[1].Field != "orgs" {
+			t.Errorf("unexpected fields %v", errs)
+		}
+	})
+
+	t.Run("returns no errors for valid config", func(t *testing.T) {
+		conf := Config{Token: TokenRef{Source: "env"}, Orgs: []string{"example"}}
+		if errs := conf.Validate(); len(errs) != 0 {
+			t.Errorf("expected no errors, got %v", errs)
+		}
+	})
+}
@@ -0,0 +1,56 @@
+package config
+
+import "slices"
+
+// Notifier types
+const (
+	NotifierWebhook = "webhook"
+	NotifierSlack   = "slack"
+	NotifierMatrix  = "matrix"
+)
+
+// Notifier events
+const (
+	NotifyNew     = "new"
+	NotifyChanged = "changed"
+)
+
+// DefaultNotifierRetries is how many times a failed send is retried when not configured
+const DefaultNotifierRetries = 3
+
+// NotifierConfig forwards new or changed issues matching Filter to a chat
+// channel or webhook
+type NotifierConfig struct {
+	Name string `json:"name,omitempty"`
+	// Type is webhook, slack or matrix
+	Type string `json:"type"`
+	// URL is the webhook URL, or the Matrix homeserver URL
+	URL string `json:"url"`
+	// Room is the Matrix room ID to post to
+	Room string `json:"room,omitempty"`
+	// Token points at the Matrix access token
+	Token  TokenRef    `json:"token,omitempty"`
+	Filter IssueFilter `json:"filter"`
+	// Events to send, new and/or changed, defaults to new
+	Events []string `json:"events,omitempty"`
+	// Template is a text/template for the message, given .Event and .Issue
+	Template string `json:"template,omitempty"`
+	// Retries is how many times a failed send is retried
+	Retries *int `json:"retries,omitempty"`
+}
+
+// RetryCount returns the configured retries, or the default
+func (n NotifierConfig) RetryCount() int {
+	if n.Retries != nil {
+		return max(*n.Retries, 0)
+	}
+	return DefaultNotifierRetries
+}
+
+// Wants returns true if the notifier sends event, new or changed
+func (n NotifierConfig) Wants(event string) bool {
+	if len(n.Events) == 0 {
+		return event == NotifyNew
+	}
+	return slices.Contains(n.Events, event)
+}
@@ -0,0 +1,96 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// DefaultInterval is how often issues are fetched when not configured
+const DefaultInterval = time.Minute
+
+// Duration is a time.Duration stored in JSON as a string, e.g. "5m"
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil {
+		return err
+	}
+
+	parsed, err := time.ParseDuration(str)
+	if err != nil {
+		return fmt.Errorf("invalid duration %q: %w", str, err)
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// Schedule controls how often issues are fetched
+type Schedule struct {
+	// Interval between fetches of recently created issues
+	Interval Duration `json:"interval,omitempty"`
+	// Jitter adds a random delay up to this long, spreading out requests
+	Jitter Duration `json:"jitter,omitempty"`
+	// FullResync is how often every page is fetched, 0 fetches all only at startup
+	FullResync Duration `json:"full_resync,omitempty"`
+}
+
+// PollingConfig is the global schedule with per-provider and per-org overrides
+type PollingConfig struct {
+	Schedule
+	Providers map[string]Schedule `json:"providers,omitempty"`
+	Orgs      map[string]Schedule `json:"orgs,omitempty"`
+}
+
+// For resolves the schedule of an org, preferring org over provider over
+// global settings, field by field
+func (p PollingConfig) For(provider string, org string) Schedule {
+	schedule := Schedule{Interval: Duration(DefaultInterval)}
+	schedule = schedule.merge(p.Schedule)
+	schedule = schedule.merge(p.Providers[provider])
+	schedule = schedule.merge(p.Orgs[org])
+	return schedule
+}
+
+// merge overrides fields set in other
+func (s Schedule) merge(other Schedule) Schedule {
+	if other.Interval > 0 {
+		s.Interval = other.Interval
+	}
+	if other.Jitter > 0 {
+		s.Jitter = other.Jitter
+	}
+	if other.FullResync > 0 {
+		s.FullResync = other.FullResync
+	}
+	return s
+}
+
+// String describes the schedule, e.g. "1m, full 1h"
+func (s Schedule) String() string {
+	str := shortDuration(time.Duration(s.Interval))
+	if s.Jitter > 0 {
+		str += " ±" + shortDuration(time.Duration(s.Jitter))
+	}
+	if s.FullResync > 0 {
+		str += ", full " + shortDuration(time.Duration(s.FullResync))
+	}
+	return str
+}
+
+// shortDuration drops zero units, e.g. "1h" rather than "1h0m0s"
+func shortDuration(d time.Duration) string {
+	switch {
+	case d >= time.Hour && d%time.Hour == 0:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	case d >= time.Minute && d%time.Minute == 0:
+		return fmt.Sprintf("%dm", d/time.Minute)
+	default:
+		return d.String()
+	}
+}
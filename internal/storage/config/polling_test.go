@@ -0,0 +1,112 @@
+package config
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestDuration(t *testing.T) {
+	t.Run("round trips JSON strings", func(t *testing.T) {
+		d := Duration(90 * time.Second)
+
+		data, err := json.Marshal(d)
+		if err != nil {
+			t.Fatal("expected nil, got error")
+		}
+		if string(data) != `"1m30s"` {
+			t.Errorf("got %s, want %s", data, `"1m30s"`)
+		}
+
+		var got Duration
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatal("expected nil, got error")
+		}
+		if got != d {
+			t.Errorf("got %v, want %v", got, d)
+		}
+	})
+
+	t.Run("returns error for invalid duration", func(t *testing.T) {
+		var got Duration
+		if err := json.Unmarshal([]byte(`"often"`), &got); err == nil {
+			t.Fatal("expected error, got nil")
+		}
+	})
+}
+
+func TestPollingFor(t *testing.T) {
+	content := `{
+		"interval": "5m",
+		"jitter": "10s",
+		"providers": {"github": {"full_resync": "6h"}},
+		"orgs": {"noisy": {"interval": "1m"}, "archive": {"interval": "1h", "full_resync": "24h"}}
+	}`
+
+	var polling PollingConfig
+	if err := json.Unmarshal([]byte(content), &polling); err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+
+	tests := []struct {
+		org      string
+		expected Schedule
+	}{
+		{"other", Schedule{Interval: Duration(5 * time.Minute), Jitter: Duration(10 * time.Second), FullResync: Duration(6 * time.Hour)}},
+		{"noisy", Schedule{Interval: Duration(time.Minute), Jitter: Duration(10 * time.Second), FullResync: Duration(6 * time.Hour)}},
+		{"archive", Schedule{Interval: Duration(time.Hour), Jitter: Duration(10 * time.Second), FullResync: Duration(24 * time.Hour)}},
+	}
+
+	for _, test := range tests {
+		result := polling.For("github", test.org)
+		if result != test.expected {
+			t.Errorf("expected %+v, got %+v for %s", test.expected, result, test.org)
+		}
+	}
+
+	t.Run("returns default interval when unset", func(t *testing.T) {
+		got := PollingConfig{}.For("github", "example")
+		if got.Interval != Duration(DefaultInterval) {
+			t.Errorf("got %v, want %v", got.Interval, DefaultInterval)
+		}
+	})
+}
+
+func TestScheduleString(t *testing.T) {
+	tests := []struct {
+		schedule Schedule
+		expected string
+	}{
+		{Schedule{Interval: Duration(time.Minute)}, "1m"},
+		{Schedule{Interval: Duration(time.Hour), FullResync: Duration(24 * time.Hour)}, "1h, full 24h"},
+		{Schedule{Interval: Duration(90 * time.Second), Jitter: Duration(10 * time.Second)}, "1m30s ±10s"},
+	}
+
+	for _, test := range tests {
+		result := test.schedule.String()
+		if result != test.expected {
+			t.Errorf("expected %q, got %q", test.expected, result)
+		}
+	}
+}
+
+func TestStaleThreshold(t *testing.T) {
+	tests := []struct {
+		name     string
+		conf     Config
+		schedule Schedule
+		want     time.Duration
+	}{
+		{"returns the default", Config{}, Schedule{Interval: Duration(time.Minute)}, DefaultStaleAfter},
+		{"returns the configured threshold", Config{StaleAfter: Duration(10 * time.Minute)}, Schedule{Interval: Duration(time.Minute)}, 10 * time.Minute},
+		{"returns two polls for slow schedules", Config{}, Schedule{Interval: Duration(time.Hour), Jitter: Duration(5 * time.Minute)}, 130 * time.Minute},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.conf.StaleThreshold(test.schedule); got != test.want {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
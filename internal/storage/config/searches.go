@@ -0,0 +1,46 @@
+package config
+
+import (
+	"slices"
+	"strings"
+)
+
+// SavedSearch is a named query, shown in the TUI side panel
+type SavedSearch struct {
+	Name  string `json:"name"`
+	Query string `json:"query"`
+}
+
+// Search returns the saved search called name, ignoring case
+func (c Config) Search(name string) (SavedSearch, bool) {
+	i := c.searchIndex(name)
+	if i < 0 {
+		return SavedSearch{}, false
+	}
+	return c.Searches[i], true
+}
+
+// SaveSearch adds a saved search, replacing any with the same name
+func (c *Config) SaveSearch(search SavedSearch) {
+	if i := c.searchIndex(search.Name); i >= 0 {
+		c.Searches[i] = search
+		return
+	}
+	c.Searches = append(c.Searches, search)
+}
+
+// DeleteSearch removes the saved search called name, returning false if there isn't one
+func (c *Config) DeleteSearch(name string) bool {
+	i := c.searchIndex(name)
+	if i < 0 {
+		return false
+	}
+	c.Searches = slices.Delete(c.Searches, i, i+1)
+	return true
+}
+
+func (c Config) searchIndex(name string) int {
+	return slices.IndexFunc(c.Searches, func(search SavedSearch) bool {
+		return strings.EqualFold(search.Name, name)
+	})
+}
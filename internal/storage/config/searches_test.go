@@ -0,0 +1,39 @@
+package config
+
+import "testing"
+
+func TestSavedSearches(t *testing.T) {
+	t.Run("returns a saved search by name, ignoring case", func(t *testing.T) {
+		conf := Config{Searches: []SavedSearch{{Name: "My P1s", Query: "label:P1"}}}
+
+		search, ok := conf.Search("my p1s")
+		if !ok || search.Query != "label:P1" {
+			t.Errorf("got %+v, %t", search, ok)
+		}
+		if _, ok := conf.Search("missing"); ok {
+			t.Error("expected no search")
+		}
+	})
+
+	t.Run("replaces a saved search with the same name", func(t *testing.T) {
+		conf := Config{}
+		conf.SaveSearch(SavedSearch{Name: "Bugs", Query: "label:bug"})
+		conf.SaveSearch(SavedSearch{Name: "Stale", Query: "created:>30d"})
+		conf.SaveSearch(SavedSearch{Name: "bugs", Query: "label:bug is:unread"})
+
+		if len(conf.Searches) != 2 || conf.Searches[0].Query != "label:bug is:unread" {
+			t.Errorf("got %+v", conf.Searches)
+		}
+	})
+
+	t.Run("deletes a saved search", func(t *testing.T) {
+		conf := Config{Searches: []SavedSearch{{Name: "Bugs"}, {Name: "Stale"}}}
+
+		if !conf.DeleteSearch("Bugs") || len(conf.Searches) != 1 || conf.Searches[0].Name != "Stale" {
+			t.Errorf("got %+v", conf.Searches)
+		}
+		if conf.DeleteSearch("Bugs") {
+			t.Error("expected false for a missing search")
+		}
+	})
+}
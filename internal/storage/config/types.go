@@ -1,11 +1,91 @@
 package config
 
-import "github.com/shaunmolloy/bugbox/internal/types"
+import (
+	"strings"
+	"time"
+
+	"github.com/shaunmolloy/bugbox/internal/types"
+)
 
 type Config struct {
-	GitHubToken string   `json:"github_token"`
-	Orgs        []string `json:"orgs"`
+	// GitHubToken is a plaintext token kept for older configs, prefer Token
+	GitHubToken string        `json:"github_token,omitempty"`
+	Token       TokenRef      `json:"token"`
+	OAuth       OAuthConfig   `json:"oauth"`
+	Orgs        []string      `json:"orgs"`
+	Polling     PollingConfig `json:"polling"`
+	// Workers limits how many orgs and pages are fetched at once
+	Workers int `json:"workers,omitempty"`
+	// StaleAfter warns when an org hasn't synced successfully for this long
+	StaleAfter Duration `json:"stale_after,omitempty"`
+	// Hooks run when new issues match their filter
+	Hooks []HookConfig `json:"hooks,omitempty"`
+	// Notifiers forward new or changed issues to webhooks and chat
+	Notifiers []NotifierConfig `json:"notifiers,omitempty"`
+	Webhooks  WebhooksConfig   `json:"webhooks,omitempty"`
+	// Searches are named queries shown in the TUI side panel
+	Searches []SavedSearch `json:"searches,omitempty"`
+	// Columns are shown in the TUI issues table, in order
+	Columns []Column `json:"columns,omitempty"`
+}
+
+// DefaultWorkers is used when Workers isn't set
+const DefaultWorkers = 4
+
+// FetchWorkers returns the configured worker limit, or the default
+func (c Config) FetchWorkers() int {
+	if c.Workers > 0 {
+		return c.Workers
+	}
+	return DefaultWorkers
+}
+
+// DefaultStaleAfter is used when StaleAfter isn't set
+const DefaultStaleAfter = time.Hour
+
+// StaleThreshold returns how long an org on schedule can go without syncing
+// before it's stale: the configured threshold or default, but at least two
+// polls, so slowly polled orgs aren't always stale
+func (c Config) StaleThreshold(schedule Schedule) time.Duration {
+	threshold := DefaultStaleAfter
+	if c.StaleAfter > 0 {
+		threshold = time.Duration(c.StaleAfter)
+	}
+	return max(threshold, 2*time.Duration(schedule.Interval+schedule.Jitter))
+}
+
+// TokenRef points at where the GitHub token is stored, rather than the token itself
+type TokenRef struct {
+	Source string `json:"source,omitempty"` // env, command, keyring or file
+	Value  string `json:"value,omitempty"`  // env var, command, keyring account or file path
 }
 
 // Issues as hierarchical structure of issues organized by org, repo, and id
 type Issues map[string]map[string]map[int]types.Issue
+
+// OAuthConfig configures the device flow used by `bugbox login`
+type OAuthConfig struct {
+	ClientID string `json:"client_id,omitempty"`
+	BaseURL  string `json:"base_url,omitempty"` // defaults to https://github.com
+}
+
+// ValidationError describes a single invalid config field
+type ValidationError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e ValidationError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+// ValidationErrors collects every invalid field so they can be reported at once
+type ValidationErrors []ValidationError
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
@@ -26,22 +26,31 @@ func LoadFromFile(path string, conf any) error {
 	return decoder.Decode(conf)
 }
 
-// SaveToFile saves config to a file
+// SaveToFile saves config to a file. It writes to a temporary file first so
+// an interrupted save never leaves a truncated file behind.
 func SaveToFile(path string, data any) error {
 	dir := filepath.Dir(path)
 	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
 		return err
 	}
 
-	file, err := os.Create(path)
+	file, err := os.CreateTemp(dir, filepath.Base(path)+".*.tmp")
 	if err != nil {
 		return err
 	}
-	defer file.Close()
+	defer os.Remove(file.Name()) // no-op once renamed
 
 	encoder := json.NewEncoder(file)
 	encoder.SetIndent("", "  ")
-	return encoder.Encode(data)
+	if err := encoder.Encode(data); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(file.Name(), path)
 }
 
 // FlattenIssues converts the hierarchical issue structure to a flat slice
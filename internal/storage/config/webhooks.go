@@ -0,0 +1,36 @@
+package config
+
+import "time"
+
+// Webhook defaults, used when not configured
+const (
+	DefaultWebhooksAddr      = "localhost:8787"
+	DefaultWebhooksIdleAfter = 10 * time.Minute
+)
+
+// WebhooksConfig configures `bugbox serve-webhooks`
+type WebhooksConfig struct {
+	// Addr is the address to listen on for deliveries
+	Addr string `json:"addr,omitempty"`
+	// Secret points at the webhook secret used to sign deliveries
+	Secret TokenRef `json:"secret,omitempty"`
+	// IdleAfter is how long an org can go without deliveries before
+	// polling resumes, in case its webhook stopped
+	IdleAfter Duration `json:"idle_after,omitempty"`
+}
+
+// ListenAddr returns the configured address, or the default
+func (w WebhooksConfig) ListenAddr() string {
+	if w.Addr != "" {
+		return w.Addr
+	}
+	return DefaultWebhooksAddr
+}
+
+// IdleThreshold returns the configured idle period, or the default
+func (w WebhooksConfig) IdleThreshold() time.Duration {
+	if w.IdleAfter > 0 {
+		return time.Duration(w.IdleAfter)
+	}
+	return DefaultWebhooksIdleAfter
+}
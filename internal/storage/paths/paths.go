@@ -0,0 +1,141 @@
+package paths
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const appName = "bugbox"
+
+// Paths holds the files and directories bugbox reads from and writes to
+type Paths struct {
+	ConfigFile string
+	ConfigDir  string
+	DataDir    string
+	StateDir   string
+	CacheDir   string
+	RuntimeDir string
+}
+
+// Resolve builds Paths from BUGBOX_* overrides, XDG base directories and HOME.
+// A non-empty configFile (e.g. from --config) takes precedence over everything.
+func Resolve(configFile string) (Paths, error) {
+	home := os.Getenv("HOME")
+	if home == "" {
+		home, _ = os.UserHomeDir()
+	}
+
+	var p Paths
+	var err error
+
+	if p.ConfigDir, err = resolveDir("BUGBOX_CONFIG_DIR", "XDG_CONFIG_HOME", home, ".config"); err != nil {
+		return Paths{}, err
+	}
+	if p.DataDir, err = resolveDir("BUGBOX_DATA_DIR", "XDG_DATA_HOME", home, ".local", "share"); err != nil {
+		return Paths{}, err
+	}
+	if p.StateDir, err = resolveDir("BUGBOX_STATE_DIR", "XDG_STATE_HOME", home, ".local", "state"); err != nil {
+		return Paths{}, err
+	}
+	if p.CacheDir, err = resolveDir("BUGBOX_CACHE_DIR", "XDG_CACHE_HOME", home, ".cache"); err != nil {
+		return Paths{}, err
+	}
+
+	// The runtime dir is for sockets, falling back to state when there's no XDG_RUNTIME_DIR
+	p.RuntimeDir = os.Getenv("BUGBOX_RUNTIME_DIR")
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); p.RuntimeDir == "" && filepath.IsAbs(dir) {
+		p.RuntimeDir = filepath.Join(dir, appName)
+	}
+	p.RuntimeDir = cmp.Or(p.RuntimeDir, p.StateDir)
+
+	p.ConfigFile = cmp.Or(configFile, os.Getenv("BUGBOX_CONFIG"))
+	if p.ConfigFile == "" {
+		p.ConfigFile = filepath.Join(p.ConfigDir, "config.json")
+	}
+
+	return p, nil
+}
+
+// IssuesFile returns the path of the issue store
+func (p Paths) IssuesFile() string {
+	return filepath.Join(p.DataDir, "issues.json")
+}
+
+// LegacyIssuesFile returns where older versions kept the issue store
+func (p Paths) LegacyIssuesFile() string {
+	return filepath.Join(p.ConfigDir, "issues.json")
+}
+
+// LogFile returns the path of the log file
+func (p Paths) LogFile() string {
+	return filepath.Join(p.StateDir, "bugbox.log")
+}
+
+// LegacyLogFile returns where older versions kept the log file
+func (p Paths) LegacyLogFile() string {
+	return filepath.Join(p.DataDir, "bugbox.log")
+}
+
+// IndexFile returns the path of the search index over bodies and comments
+func (p Paths) IndexFile() string {
+	return filepath.Join(p.CacheDir, "index.json")
+}
+
+// StateFile returns the path of the TUI's saved state, e.g. expanded panel nodes
+func (p Paths) StateFile() string {
+	return filepath.Join(p.StateDir, "tui.json")
+}
+
+// SocketFile returns the path of the daemon's socket
+func (p Paths) SocketFile() string {
+	return filepath.Join(p.RuntimeDir, "bugbox.sock")
+}
+
+// Move moves a file from where older versions kept it to path, unless there's
+// already one at path or nothing to move
+func Move(legacyPath string, path string) error {
+	if legacyPath == path {
+		return nil
+	}
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+	info, err := os.Stat(legacyPath)
+	if err != nil {
+		return nil
+	}
+
+	// Copy rather than rename, the directories may be on different devices
+	data, err := os.ReadFile(legacyPath)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, info.Mode().Perm()); err != nil {
+		return err
+	}
+	return os.Remove(legacyPath)
+}
+
+// resolveDir picks the override, then the XDG base directory, then HOME/defaults
+func resolveDir(override string, xdg string, home string, defaults ...string) (string, error) {
+	if dir := os.Getenv(override); dir != "" {
+		return dir, nil
+	}
+
+	// XDG spec: relative paths are invalid and should be ignored
+	if dir := os.Getenv(xdg); filepath.IsAbs(dir) {
+		return filepath.Join(dir, appName), nil
+	}
+
+	if home == "" {
+		return "", fmt.Errorf("cannot resolve %s: HOME is not set", override)
+	}
+
+	parts := append([]string{home}, defaults...)
+	return filepath.Join(append(parts, appName)...), nil
+}
@@ -0,0 +1,169 @@
+package paths
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	clearEnv := func(t *testing.T) {
+		for _, key := range []string{
+			"BUGBOX_CONFIG", "BUGBOX_CONFIG_DIR", "BUGBOX_DATA_DIR", "BUGBOX_STATE_DIR", "BUGBOX_CACHE_DIR", "BUGBOX_RUNTIME_DIR",
+			"XDG_CONFIG_HOME", "XDG_DATA_HOME", "XDG_STATE_HOME", "XDG_CACHE_HOME", "XDG_RUNTIME_DIR",
+		} {
+			t.Setenv(key, "")
+		}
+	}
+
+	t.Run("returns defaults under HOME", func(t *testing.T) {
+		clearEnv(t)
+		t.Setenv("HOME", "/home/example")
+
+		p, err := Resolve("")
+		if err != nil {
+			t.Fatal("expected nil, got error")
+		}
+
+		want := Paths{
+			ConfigFile: "/home/example/.config/bugbox/config.json",
+			ConfigDir:  "/home/example/.config/bugbox",
+			DataDir:    "/home/example/.local/share/bugbox",
+			StateDir:   "/home/example/.local/state/bugbox",
+			CacheDir:   "/home/example/.cache/bugbox",
+			RuntimeDir: "/home/example/.local/state/bugbox",
+		}
+		if p != want {
+			t.Errorf("got %+v, want %+v", p, want)
+		}
+	})
+
+	t.Run("returns XDG base directories when set", func(t *testing.T) {
+		clearEnv(t)
+		t.Setenv("HOME", "/home/example")
+		t.Setenv("XDG_CONFIG_HOME", "/xdg/config")
+		t.Setenv("XDG_DATA_HOME", "/xdg/data")
+		t.Setenv("XDG_STATE_HOME", "/xdg/state")
+		t.Setenv("XDG_CACHE_HOME", "/xdg/cache")
+
+		p, err := Resolve("")
+		if err != nil {
+			t.Fatal("expected nil, got error")
+		}
+
+		if p.ConfigFile != "/xdg/config/bugbox/config.json" {
+			t.Errorf("got %q, want %q", p.ConfigFile, "/xdg/config/bugbox/config.json")
+		}
+		if p.IssuesFile() != "/xdg/data/bugbox/issues.json" {
+			t.Errorf("got %q, want %q", p.IssuesFile(), "/xdg/data/bugbox/issues.json")
+		}
+		if p.LogFile() != "/xdg/state/bugbox/bugbox.log" {
+			t.Errorf("got %q, want %q", p.LogFile(), "/xdg/state/bugbox/bugbox.log")
+		}
+		if p.CacheDir != "/xdg/cache/bugbox" {
+			t.Errorf("got %q, want %q", p.CacheDir, "/xdg/cache/bugbox")
+		}
+	})
+
+	t.Run("returns the socket under XDG_RUNTIME_DIR when set", func(t *testing.T) {
+		clearEnv(t)
+		t.Setenv("HOME", "/home/example")
+		t.Setenv("XDG_RUNTIME_DIR", "/run/user/1000")
+
+		p, err := Resolve("")
+		if err != nil {
+			t.Fatal("expected nil, got error")
+		}
+
+		if p.SocketFile() != "/run/user/1000/bugbox/bugbox.sock" {
+			t.Errorf("got %q, want %q", p.SocketFile(), "/run/user/1000/bugbox/bugbox.sock")
+		}
+	})
+
+	t.Run("ignores relative XDG base directories", func(t *testing.T) {
+		clearEnv(t)
+		t.Setenv("HOME", "/home/example")
+		t.Setenv("XDG_CONFIG_HOME", "relative/config")
+
+		p, err := Resolve("")
+		if err != nil {
+			t.Fatal("expected nil, got error")
+		}
+
+		if p.ConfigDir != "/home/example/.config/bugbox" {
+			t.Errorf("got %q, want %q", p.ConfigDir, "/home/example/.config/bugbox")
+		}
+	})
+
+	t.Run("returns BUGBOX overrides over XDG", func(t *testing.T) {
+		clearEnv(t)
+		dir := t.TempDir()
+		t.Setenv("XDG_DATA_HOME", "/xdg/data")
+		t.Setenv("BUGBOX_CONFIG_DIR", filepath.Join(dir, "config"))
+		t.Setenv("BUGBOX_DATA_DIR", filepath.Join(dir, "data"))
+
+		p, err := Resolve("")
+		if err != nil {
+			t.Fatal("expected nil, got error")
+		}
+
+		if p.ConfigFile != filepath.Join(dir, "config", "config.json") {
+			t.Errorf("got %q, want %q", p.ConfigFile, filepath.Join(dir, "config", "config.json"))
+		}
+		if p.DataDir != filepath.Join(dir, "data") {
+			t.Errorf("got %q, want %q", p.DataDir, filepath.Join(dir, "data"))
+		}
+	})
+
+	t.Run("returns config flag over BUGBOX_CONFIG", func(t *testing.T) {
+		clearEnv(t)
+		t.Setenv("HOME", "/home/example")
+		t.Setenv("BUGBOX_CONFIG", "/env/config.json")
+
+		p, err := Resolve("/flag/config.json")
+		if err != nil {
+			t.Fatal("expected nil, got error")
+		}
+
+		if p.ConfigFile != "/flag/config.json" {
+			t.Errorf("got %q, want %q", p.ConfigFile, "/flag/config.json")
+		}
+	})
+
+	t.Run("returns error when nothing can be resolved", func(t *testing.T) {
+		clearEnv(t)
+		t.Setenv("HOME", "")
+
+		if _, err := resolveDir("BUGBOX_DATA_DIR", "XDG_DATA_HOME", "", ".local", "share"); err == nil {
+			t.Fatal("expected error, got nil")
+		}
+	})
+}
+
+func TestMove(t *testing.T) {
+	t.Run("moves a legacy log to the state dir", func(t *testing.T) {
+		dir := t.TempDir()
+		p := Paths{DataDir: filepath.Join(dir, "data"), StateDir: filepath.Join(dir, "state")}
+		os.MkdirAll(p.DataDir, os.ModePerm)
+		os.WriteFile(p.LegacyLogFile(), []byte("started\n"), 0600)
+
+		if err := Move(p.LegacyLogFile(), p.LogFile()); err != nil {
+			t.Fatal("expected nil, got error")
+		}
+
+		data, err := os.ReadFile(p.LogFile())
+		if err != nil || string(data) != "started\n" {
+			t.Errorf("expected the log at the new path, got %q, %v", data, err)
+		}
+		if _, err := os.Stat(p.LegacyLogFile()); !os.IsNotExist(err) {
+			t.Errorf("expected the legacy log to be removed, got %v", err)
+		}
+	})
+
+	t.Run("returns nil when there's nothing to move", func(t *testing.T) {
+		dir := t.TempDir()
+		if err := Move(filepath.Join(dir, "legacy.log"), filepath.Join(dir, "bugbox.log")); err != nil {
+			t.Fatal("expected nil, got error")
+		}
+	})
+}
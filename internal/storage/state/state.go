@@ -0,0 +1,54 @@
+package state
+
+import (
+	"os"
+	"sync"
+
+	"github.com/shaunmolloy/bugbox/internal/grouping"
+	"github.com/shaunmolloy/bugbox/internal/sorting"
+	"github.com/shaunmolloy/bugbox/internal/storage/config"
+)
+
+// StatePath is the location of the TUI's state file, set by main at startup
+var StatePath string
+
+var mu sync.Mutex
+
+// State is how the TUI was left, restored next time it starts
+type State struct {
+	// Expanded lists the side panel nodes that are open
+	Expanded []string `json:"expanded,omitempty"`
+	// Sort is how issues are ordered, zero for the default
+	Sort sorting.Order `json:"sort"`
+	// Group is what issues are grouped by, empty for a flat list
+	Group grouping.Field `json:"group,omitempty"`
+}
+
+// Load reads the saved state, returning empty state if there's none
+func Load() (State, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	return load()
+}
+
+// Update loads, changes and saves the state while holding a lock
+func Update(update func(*State)) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	s, err := load()
+	if err != nil {
+		s = State{} // Start afresh when unreadable
+	}
+	update(&s)
+	return config.SaveToFile(StatePath, s)
+}
+
+func load() (State, error) {
+	var s State
+	err := config.LoadFromFile(StatePath, &s)
+	if os.IsNotExist(err) {
+		return State{}, nil
+	}
+	return s, err
+}
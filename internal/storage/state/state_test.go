@@ -0,0 +1,53 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestState(t *testing.T) {
+	t.Run("returns empty state for a missing file", func(t *testing.T) {
+		StatePath = filepath.Join(t.TempDir(), "tui.json")
+
+		s, err := Load()
+		if err != nil {
+			t.Fatal("expected nil, got error")
+		}
+		if len(s.Expanded) != 0 {
+			t.Errorf("expected empty state, got %+v", s)
+		}
+	})
+
+	t.Run("returns saved changes", func(t *testing.T) {
+		StatePath = filepath.Join(t.TempDir(), "tui.json")
+
+		if err := Update(func(s *State) { s.Expanded = []string{"example"} }); err != nil {
+			t.Fatal("expected nil, got error")
+		}
+
+		s, err := Load()
+		if err != nil {
+			t.Fatal("expected nil, got error")
+		}
+		if !slices.Equal(s.Expanded, []string{"example"}) {
+			t.Errorf("got %+v", s)
+		}
+	})
+
+	t.Run("replaces unreadable state on update", func(t *testing.T) {
+		StatePath = filepath.Join(t.TempDir(), "tui.json")
+		os.WriteFile(StatePath, []byte("{"), 0644)
+
+		if _, err := Load(); err == nil {
+			t.Fatal("expected error, got nil")
+		}
+		if err := Update(func(s *State) {}); err != nil {
+			t.Fatal("expected nil, got error")
+		}
+		if _, err := Load(); err != nil {
+			t.Fatal("expected nil, got error")
+		}
+	})
+}
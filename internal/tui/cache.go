@@ -0,0 +1,84 @@
+package tui
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/shaunmolloy/bugbox/internal/fulltext"
+	"github.com/shaunmolloy/bugbox/internal/fuzzy"
+	"github.com/shaunmolloy/bugbox/internal/logging"
+	"github.com/shaunmolloy/bugbox/internal/sorting"
+	"github.com/shaunmolloy/bugbox/internal/storage/config"
+	"github.com/shaunmolloy/bugbox/internal/types"
+)
+
+// issueCache keeps issues in memory between redraws, so typing a search
+// doesn't reload them on every keystroke. It's reloaded after issues change.
+type issueCache struct {
+	mu     sync.Mutex
+	loaded bool
+	issues []types.Issue
+	index  *fuzzy.Index
+	docs   *fulltext.Index
+	// docsVersion is the saved index's version when docs was loaded, as it's
+	// only reloaded once the indexer saves it again
+	docsVersion string
+	err         error
+}
+
+var cache = &issueCache{}
+
+// Invalidate reloads issues on next use
+func (c *issueCache) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.loaded = false
+}
+
+// Issues returns sorted issues, loading them from Source if needed
+func (c *issueCache) Issues() ([]types.Issue, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.load()
+	return c.issues, c.err
+}
+
+// Index returns the issues prepared for fuzzy search
+func (c *issueCache) Index() *fuzzy.Index {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.load()
+	if c.index == nil {
+		c.index = fuzzy.NewIndex(c.issues)
+	}
+	return c.index
+}
+
+// Docs returns the search index over bodies and comments, loaded on first
+// use and again after it's saved
+func (c *issueCache) Docs() *fulltext.Index {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if version := fulltext.Version(fulltext.IndexPath); c.docs == nil || version != c.docsVersion {
+		docs, err := fulltext.Load(fulltext.IndexPath)
+		if err != nil {
+			logging.Error(fmt.Sprintf("Failed to load search index: %v", err))
+		}
+		c.docs, c.docsVersion = docs, version
+	}
+	return c.docs
+}
+
+func (c *issueCache) load() {
+	if c.loaded {
+		return
+	}
+
+	issuesMap, err := Source.Issues()
+	issues := config.FlattenIssues(issuesMap)
+	sorting.Sort(issues, sortOrder)
+
+	c.issues, c.index, c.err = issues, nil, err
+	// Retry next time when the daemon is unavailable
+	c.loaded = err == nil
+}
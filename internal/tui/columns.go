@@ -0,0 +1,189 @@
+package tui
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/rivo/tview"
+	"github.com/shaunmolloy/bugbox/internal/fuzzy"
+	"github.com/shaunmolloy/bugbox/internal/logging"
+	"github.com/shaunmolloy/bugbox/internal/sorting"
+	"github.com/shaunmolloy/bugbox/internal/storage/config"
+	"github.com/shaunmolloy/bugbox/internal/utils"
+)
+
+// columnTitles are the issues table's headers
+var columnTitles = map[string]string{
+	"title":     "Title",
+	"number":    "#",
+	"labels":    "Labels",
+	"org":       "Org",
+	"repo":      "Repo",
+	"assignee":  "Assignee",
+	"author":    "Author",
+	"comments":  "Comments",
+	"reactions": "Reactions",
+	"created":   "Created",
+	"updated":   "Updated",
+	"state":     "State",
+	"milestone": "Milestone",
+}
+
+// columnSorts is what choosing each column header sorts by
+var columnSorts = map[string]sorting.Field{
+	"title":     sorting.Title,
+	"labels":    sorting.Priority,
+	"org":       sorting.Repo,
+	"repo":      sorting.Repo,
+	"comments":  sorting.Comments,
+	"reactions": sorting.Reactions,
+	"created":   sorting.Created,
+	"updated":   sorting.Updated,
+}
+
+// columnAlign right-aligns numbers
+func columnAlign(column config.Column) int {
+	switch column.Name {
+	case "number", "comments", "reactions":
+		return tview.AlignRight
+	}
+	return tview.AlignLeft
+}
+
+// panelWidth is how wide the side panel is beside the issues
+const panelWidth = 40
+
+// columnWarning is the last invalid columns config warned about
+var columnWarning string
+
+// visibleColumns returns the configured columns shown at the screen width,
+// warning once when invalid columns are replaced by the defaults
+func visibleColumns() []config.Column {
+	errs := conf.ColumnErrors()
+	if warning := errs.Error(); warning != columnWarning {
+		columnWarning = warning
+		if len(errs) > 0 {
+			logging.Warn(fmt.Sprintf("Using the default columns: %v", errs))
+		}
+	}
+
+	var columns []config.Column
+	for _, column := range conf.TableColumns() {
+		if column.Visible(currentScreenWidth) {
+			columns = append(columns, column)
+		}
+	}
+	return columns
+}
+
+// columnWidths returns how many runes of text fit in each column: its set
+// width, or its share of the space the fixed columns leave by expand ratio
+func columnWidths(columns []config.Column) []int {
+	// The issues are inside a border, beside the side panel on wide screens,
+	// with a space between columns
+	available := currentScreenWidth - 2 - (len(columns) - 1)
+	if !useVerticalLayout {
+		available -= panelWidth
+	}
+
+	expand := 0
+	for _, column := range columns {
+		if column.Width > 0 {
+			available -= column.Width
+		} else {
+			expand += column.Expansion()
+		}
+	}
+
+	widths := make([]int, len(columns))
+	for i, column := range columns {
+		widths[i] = column.Width
+		if column.Width == 0 {
+			widths[i] = max(available*column.Expansion()/max(expand, 1), 1)
+		}
+	}
+	return widths
+}
+
+// labelsAfter returns the column label chips follow when they don't have a
+// column of their own: the title, else the first expanding column, else the
+// last. It returns -1 when there's a labels column.
+func labelsAfter(columns []config.Column) int {
+	has := func(match func(config.Column) bool) int {
+		return slices.IndexFunc(columns, match)
+	}
+	switch {
+	case has(func(c config.Column) bool { return c.Name == "labels" }) >= 0:
+		return -1
+	case has(func(c config.Column) bool { return c.Name == "title" }) >= 0:
+		return has(func(c config.Column) bool { return c.Name == "title" })
+	case has(func(c config.Column) bool { return c.Width == 0 }) >= 0:
+		return has(func(c config.Column) bool { return c.Width == 0 })
+	}
+	return len(columns) - 1
+}
+
+// columnCell renders one issue's cell in a column, cutting text to width
+// runes, and following it with label chips when withLabels is set
+func columnCell(column config.Column, width int, result fuzzy.Result, withLabels bool) *tview.TableCell {
+	issue := result.Issue
+
+	// Leave room for label chips, but keep at least half the column for text
+	chips := ""
+	if withLabels && len(issue.Labels) > 0 {
+		chips = labelChips(issue.Labels)
+		width = max(width-tview.TaggedStringWidth(chips)-1, width/2)
+	}
+
+	var text string
+	switch column.Name {
+	case "title":
+		// Matched characters are highlighted in fuzzy search
+		text = highlight(issue.Title, result.Title, width)
+	case "number":
+		text = "#" + strconv.Itoa(issue.ID)
+	case "labels":
+		text = labelChips(issue.Labels)
+	case "org":
+		text = highlight(issue.Org, result.Org, width)
+	case "repo":
+		text = highlight(issue.Repo, result.Repo, width)
+	case "assignee":
+		logins := make([]string, len(issue.Assignees))
+		for i, user := range issue.Assignees {
+			logins[i] = user.Login
+		}
+		text = highlight(strings.Join(logins, ", "), nil, width)
+	case "author":
+		text = highlight(issue.Author.Login, nil, width)
+	case "comments":
+		text = strconv.Itoa(issue.Comments)
+	case "reactions":
+		text = strconv.Itoa(issue.Reactions.TotalCount)
+	case "created":
+		text = utils.RelativeTime(issue.CreatedAt)
+	case "updated":
+		if !issue.UpdatedAt.IsZero() {
+			text = utils.RelativeTime(issue.UpdatedAt)
+		}
+	case "state":
+		text = issue.State.String()
+	case "milestone":
+		if issue.Milestone != nil {
+			text = highlight(issue.Milestone.Title, nil, width)
+		}
+	}
+	if chips != "" {
+		text = strings.TrimSpace(text + " " + chips)
+	}
+
+	cell := tview.NewTableCell(text).
+		SetAlign(columnAlign(column)).
+		SetExpansion(column.Expansion())
+	if column.Width > 0 {
+		cell.SetMaxWidth(column.Width)
+	}
+	return cell
+}
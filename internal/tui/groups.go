@@ -0,0 +1,104 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shaunmolloy/bugbox/internal/fuzzy"
+	"github.com/shaunmolloy/bugbox/internal/grouping"
+	"github.com/shaunmolloy/bugbox/internal/logging"
+	"github.com/shaunmolloy/bugbox/internal/storage/state"
+	"github.com/shaunmolloy/bugbox/internal/types"
+)
+
+// Group state, restored from state at startup
+var (
+	groupBy = grouping.None
+	// collapsedGroups hides the issues of groups by name, ignoring case
+	collapsedGroups = map[string]bool{}
+)
+
+// issueRow is a row of the issues table after its header, either an issue
+// or the header of a group of issues
+type issueRow struct {
+	result *fuzzy.Result // nil for a group header
+	group  string
+	counts nodeCounts
+}
+
+// issueRows lists results under the headers of their groups, leaving out the
+// issues of collapsed groups
+func issueRows(results []fuzzy.Result) []issueRow {
+	rows := make([]issueRow, 0, len(results))
+	if groupBy == grouping.None {
+		for i := range results {
+			rows = append(rows, issueRow{result: &results[i]})
+		}
+		return rows
+	}
+
+	issues := make([]types.Issue, len(results))
+	for i, result := range results {
+		issues[i] = result.Issue
+	}
+
+	for _, group := range grouping.Groups(issues, groupBy, time.Now()) {
+		header := issueRow{group: group.Name}
+		for _, i := range group.Indexes {
+			header.counts.open++
+			if !issues[i].Read {
+				header.counts.unread++
+			}
+		}
+		rows = append(rows, header)
+
+		if groupCollapsed(group.Name) {
+			continue
+		}
+		for _, i := range group.Indexes {
+			rows = append(rows, issueRow{result: &results[i], group: group.Name})
+		}
+	}
+	return rows
+}
+
+// groupCollapsed returns true if the group's issues are hidden
+func groupCollapsed(name string) bool {
+	return collapsedGroups[strings.ToLower(name)]
+}
+
+// setCollapsed hides or shows a group's issues
+func setCollapsed(name string, collapsed bool) {
+	if collapsed {
+		collapsedGroups[strings.ToLower(name)] = true
+	} else {
+		delete(collapsedGroups, strings.ToLower(name))
+	}
+	RefreshChan <- struct{}{}
+}
+
+// setGroup groups the issues by field, saving it for next time
+func setGroup(field grouping.Field) {
+	groupBy = field
+	collapsedGroups = map[string]bool{}
+	logging.Info(fmt.Sprintf("Grouping by %q", field))
+	if err := state.Update(func(s *state.State) { s.Group = field }); err != nil {
+		logging.Error(fmt.Sprintf("Failed to save TUI state: %v", err))
+	}
+
+	selectedRow = 1
+	RefreshChan <- struct{}{}
+}
+
+// loadGroup restores what issues were grouped by
+func loadGroup() {
+	s, err := state.Load()
+	if err != nil {
+		logging.Error(fmt.Sprintf("Failed to load TUI state: %v", err))
+		return
+	}
+	if field, err := grouping.Parse(string(s.Group)); err == nil {
+		groupBy = field
+	}
+}
@@ -0,0 +1,193 @@
+package tui
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/shaunmolloy/bugbox/internal/types"
+	"github.com/shaunmolloy/bugbox/internal/utils"
+)
+
+// Label picker state
+var (
+	showLabels   = false
+	labelInclude []string // issues with any of these labels
+	labelExclude []string // issues with none of these labels
+	labelRow     = 0
+	// screenColors is how many colours the terminal supports
+	screenColors = 256
+)
+
+// labelCount is a label seen on issues, and how many issues have it
+type labelCount struct {
+	label types.Label
+	count int
+}
+
+// knownLabels lists the labels on issues by name, ignoring case, taking the
+// colour and description from any issue that has them
+func knownLabels(issues []types.Issue) []labelCount {
+	byName := make(map[string]*labelCount)
+	for _, issue := range issues {
+		for _, label := range issue.Labels {
+			key := strings.ToLower(label.Name)
+			if byName[key] == nil {
+				byName[key] = &labelCount{label: label}
+			}
+			known := &byName[key].label
+			known.Color = cmp.Or(known.Color, label.Color)
+			known.Description = cmp.Or(known.Description, label.Description)
+			byName[key].count++
+		}
+	}
+
+	labels := make([]labelCount, 0, len(byName))
+	for _, label := range byName {
+		labels = append(labels, *label)
+	}
+	slices.SortFunc(labels, func(a, b labelCount) int {
+		return strings.Compare(strings.ToLower(a.label.Name), strings.ToLower(b.label.Name))
+	})
+	return labels
+}
+
+// labelChips renders labels in their GitHub colours, or as bracketed names
+// when the terminal has no colour or NO_COLOR is set
+func labelChips(labels []types.Label) string {
+	noColor := screenColors < 8 || os.Getenv("NO_COLOR") != ""
+
+	chips := make([]string, len(labels))
+	for i, label := range labels {
+		name := tview.Escape(label.Name)
+		switch _, _, _, ok := utils.ParseHexColor(label.Color); {
+		case noColor:
+			chips[i] = tview.Escape("[" + label.Name + "]")
+		case ok:
+			chips[i] = fmt.Sprintf("[%s:#%s] %s [-:-]", utils.ContrastColor(label.Color), label.Color, name)
+		default:
+			chips[i] = fmt.Sprintf("[white:gray] %s [-:-]", name)
+		}
+	}
+	return strings.Join(chips, " ")
+}
+
+// labelMatch returns true if issue passes the label picker's filter
+func labelMatch(issue types.Issue) bool {
+	has := func(name string) bool {
+		return slices.ContainsFunc(issue.Labels, func(label types.Label) bool {
+			return strings.EqualFold(label.Name, name)
+		})
+	}
+
+	if len(labelInclude) > 0 && !slices.ContainsFunc(labelInclude, has) {
+		return false
+	}
+	return !slices.ContainsFunc(labelExclude, has)
+}
+
+// labelFilterText describes the label filter, e.g. "+bug -wontfix"
+func labelFilterText() string {
+	var parts []string
+	for _, name := range labelInclude {
+		parts = append(parts, "+"+name)
+	}
+	for _, name := range labelExclude {
+		parts = append(parts, "-"+name)
+	}
+	return strings.Join(parts, " ")
+}
+
+// cycleLabel moves a label from unfiltered, to included, to excluded, and back
+func cycleLabel(name string) {
+	switch {
+	case slices.Contains(labelInclude, name):
+		labelInclude = slices.DeleteFunc(labelInclude, func(n string) bool { return n == name })
+		labelExclude = append(labelExclude, name)
+	case slices.Contains(labelExclude, name):
+		labelExclude = slices.DeleteFunc(labelExclude, func(n string) bool { return n == name })
+	default:
+		labelInclude = append(labelInclude, name)
+	}
+}
+
+// labelsView lets labels be included or excluded to filter the issues
+func labelsView() tview.Primitive {
+	issues, _ := cache.Issues()
+	labels := knownLabels(issues)
+
+	table := tview.NewTable().SetSelectable(true, false)
+	for row, entry := range labels {
+		marker := tview.NewTableCell("  ")
+		switch {
+		case slices.Contains(labelInclude, entry.label.Name):
+			marker.SetText("+ ").SetTextColor(primaryColor)
+		case slices.Contains(labelExclude, entry.label.Name):
+			marker.SetText("- ").SetTextColor(errorColor)
+		}
+
+		table.SetCell(row, 0, marker)
+		table.SetCell(row, 1, tview.NewTableCell(labelChips([]types.Label{entry.label})))
+		table.SetCell(row, 2, tview.NewTableCell(fmt.Sprintf(" %d ", entry.count)).
+			SetTextColor(grayColor).
+			SetAlign(tview.AlignRight))
+		table.SetCell(row, 3, tview.NewTableCell(tview.Escape(entry.label.Description)).
+			SetTextColor(grayColor).
+			SetExpansion(1))
+	}
+	if len(labels) == 0 {
+		table.SetCell(0, 0, tview.NewTableCell("No labels").SetTextColor(grayColor))
+	}
+
+	if labelRow >= len(labels) {
+		labelRow = max(len(labels)-1, 0)
+	}
+	table.Select(labelRow, 0)
+	table.SetSelectionChangedFunc(func(row, column int) {
+		if row >= 0 {
+			labelRow = row
+		}
+	})
+
+	// Space or Enter cycles the label between included, excluded and neither
+	toggle := func() {
+		if labelRow < len(labels) {
+			cycleLabel(labels[labelRow].label.Name)
+			selectedRow = 1
+			RefreshChan <- struct{}{}
+		}
+	}
+	table.SetSelectedFunc(func(row, column int) {
+		toggle()
+	})
+	table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() != tcell.KeyRune {
+			return event
+		}
+		switch event.Rune() {
+		case ' ':
+			toggle()
+			return nil
+		case 'c':
+			// Clear the label filter
+			labelInclude, labelExclude = nil, nil
+			RefreshChan <- struct{}{}
+			return nil
+		}
+		return event
+	})
+
+	title := fmt.Sprintf("Labels (%d)", len(labels))
+	if text := labelFilterText(); text != "" {
+		title += " - " + tview.Escape(text)
+	}
+
+	flex := tview.NewFlex().SetDirection(tview.FlexRow)
+	flex.SetTitle(title).SetTitleColor(primaryColor).SetBorder(true)
+	flex.AddItem(table, 0, 1, true)
+	return flex
+}
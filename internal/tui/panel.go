@@ -0,0 +1,440 @@
+package tui
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/shaunmolloy/bugbox/internal/issues/github"
+	"github.com/shaunmolloy/bugbox/internal/logging"
+	"github.com/shaunmolloy/bugbox/internal/query"
+	"github.com/shaunmolloy/bugbox/internal/status"
+	"github.com/shaunmolloy/bugbox/internal/storage/config"
+	"github.com/shaunmolloy/bugbox/internal/storage/state"
+	"github.com/shaunmolloy/bugbox/internal/types"
+	"github.com/shaunmolloy/bugbox/internal/utils"
+)
+
+// Kinds of side panel row
+const (
+	nodeHeader = "header"
+	nodeSearch = "search"
+	nodeError  = "error" // why the saved search above doesn't parse
+	nodeOrg    = "org"
+	nodeRepo   = "repo"
+)
+
+// panelNode is a row of the side panel, and what selecting it filters by
+type panelNode struct {
+	Kind  string
+	Value string // search name, org, org/repo, or error
+}
+
+// nodeCounts is how many issues are under a node, and how many are unread
+type nodeCounts struct {
+	open   int
+	unread int
+}
+
+// Side panel state
+var (
+	panelFocused = false
+	panelFilter  []panelNode // issues under any of these, empty when not filtering
+	panelRows    []panelNode
+	panelRow     = 0
+	// expanded orgs show their repos, restored from state at startup
+	expanded = map[string]bool{}
+)
+
+// panelNodes lists saved searches then orgs, with the repos of expanded
+// orgs, each section under a header
+func panelNodes(issues []types.Issue) []panelNode {
+	var nodes []panelNode
+	if len(conf.Searches) > 0 {
+		nodes = append(nodes, panelNode{Kind: nodeHeader, Value: "Searches"})
+		for _, search := range conf.Searches {
+			nodes = append(nodes, panelNode{Kind: nodeSearch, Value: search.Name})
+			if _, err := query.Parse(search.Query); err != nil {
+				nodes = append(nodes, panelNode{Kind: nodeError, Value: err.Error()})
+			}
+		}
+	}
+
+	repos := make(map[string][]string)
+	for _, issue := range issues {
+		if !slices.Contains(repos[issue.Org], issue.Repo) {
+			repos[issue.Org] = append(repos[issue.Org], issue.Repo)
+		}
+	}
+
+	nodes = append(nodes, panelNode{Kind: nodeHeader, Value: "Orgs"})
+	for _, org := range conf.Orgs {
+		nodes = append(nodes, panelNode{Kind: nodeOrg, Value: org})
+		if !expanded[org] {
+			continue
+		}
+		slices.Sort(repos[org])
+		for _, repo := range repos[org] {
+			nodes = append(nodes, panelNode{Kind: nodeRepo, Value: org + "/" + repo})
+		}
+	}
+	return nodes
+}
+
+// matcher returns whether issues belong under the node
+func (n panelNode) matcher() func(issue types.Issue) bool {
+	switch n.Kind {
+	case nodeOrg:
+		return func(issue types.Issue) bool {
+			return issue.Org == n.Value
+		}
+	case nodeRepo:
+		return func(issue types.Issue) bool {
+			return issue.Org+"/"+issue.Repo == n.Value
+		}
+	case nodeSearch:
+		search, _ := conf.Search(n.Value)
+		parsed, err := query.Parse(search.Query)
+		if err != nil {
+			// Invalid saved searches are shown with their error in the panel
+			return func(issue types.Issue) bool { return false }
+		}
+		if len(parsed.Text()) > 0 {
+			parsed = parsed.WithDocuments(cache.Docs())
+		}
+		now := time.Now()
+		return func(issue types.Issue) bool {
+			return parsed.Match(issue, now)
+		}
+	}
+	return func(issue types.Issue) bool { return false }
+}
+
+// panelMatcher returns whether issues are under any filtered node, or nil
+// when not filtering
+func panelMatcher() func(issue types.Issue) bool {
+	if len(panelFilter) == 0 {
+		return nil
+	}
+
+	matchers := make([]func(types.Issue) bool, len(panelFilter))
+	for i, node := range panelFilter {
+		matchers[i] = node.matcher()
+	}
+	return func(issue types.Issue) bool {
+		for _, match := range matchers {
+			if match(issue) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// panelCounts counts the issues under each node
+func panelCounts(nodes []panelNode, issues []types.Issue) map[panelNode]nodeCounts {
+	counts := make(map[panelNode]nodeCounts)
+	add := func(node panelNode, issue types.Issue) {
+		c := counts[node]
+		c.open++
+		if !issue.Read {
+			c.unread++
+		}
+		counts[node] = c
+	}
+
+	for _, issue := range issues {
+		add(panelNode{Kind: nodeOrg, Value: issue.Org}, issue)
+		add(panelNode{Kind: nodeRepo, Value: issue.Org + "/" + issue.Repo}, issue)
+	}
+
+	for _, node := range nodes {
+		if node.Kind != nodeSearch {
+			continue
+		}
+		match := node.matcher()
+		for _, issue := range issues {
+			if match(issue) {
+				add(node, issue)
+			}
+		}
+	}
+	return counts
+}
+
+// formatCounts shows unread over open counts, highlighting unread issues
+func formatCounts(c nodeCounts) string {
+	if c.unread == 0 {
+		return fmt.Sprint(c.open)
+	}
+	return fmt.Sprintf("[green::b]%d[-::-]/%d", c.unread, c.open)
+}
+
+// staleOrgs returns the orgs that haven't synced for longer than their
+// threshold, which allows for each org's polling interval
+func staleOrgs(state status.Snapshot) []string {
+	return state.Stale(conf.Orgs, func(org string) time.Duration {
+		return conf.StaleThreshold(conf.Polling.For(github.Provider, org))
+	}, time.Now())
+}
+
+// panelView lists saved searches, and a tree of orgs and their repos, with
+// open and unread counts. Nodes are selectable to filter the issues.
+func panelView(state status.Snapshot) tview.Primitive {
+	issues, _ := cache.Issues()
+	stale := staleOrgs(state)
+
+	panelRows = panelNodes(issues)
+	counts := panelCounts(panelRows, issues)
+
+	table := tview.NewTable().SetSelectable(panelFocused, false)
+	for row, node := range panelRows {
+		cell := tview.NewTableCell(node.Value).SetExpansion(1)
+		count := tview.NewTableCell(formatCounts(counts[node])).SetAlign(tview.AlignRight).SetTextColor(grayColor)
+		schedule := tview.NewTableCell("").SetAlign(tview.AlignRight).SetTextColor(grayColor)
+		lastSync := tview.NewTableCell("").SetAlign(tview.AlignRight).SetTextColor(grayColor)
+
+		switch node.Kind {
+		case nodeHeader:
+			cell.SetText(fmt.Sprintf("[::b]%s", node.Value)).SetTextColor(primaryColor)
+			count.SetText("")
+			for _, c := range []*tview.TableCell{cell, count, schedule, lastSync} {
+				c.SetSelectable(false)
+			}
+
+		case nodeSearch:
+			cell.SetText("  " + tview.Escape(node.Value))
+			if search, _ := conf.Search(node.Value); search.Query != "" {
+				if _, err := query.Parse(search.Query); err != nil {
+					cell.SetTextColor(errorColor)
+					count.SetText("")
+				}
+			}
+
+		case nodeError:
+			cell.SetText("    " + tview.Escape(node.Value)).SetTextColor(errorColor)
+			count.SetText("")
+			for _, c := range []*tview.TableCell{cell, count, schedule, lastSync} {
+				c.SetSelectable(false)
+			}
+
+		case nodeRepo:
+			_, repo, _ := strings.Cut(node.Value, "/")
+			cell.SetText("    " + repo)
+
+		case nodeOrg:
+			marker := "▸ "
+			if expanded[node.Value] {
+				marker = "▾ "
+			}
+			cell.SetText(marker + node.Value)
+			// Show how often each org is polled
+			schedule.SetText(" " + conf.Polling.For(github.Provider, node.Value).String())
+			// Show when each org last synced, in red if the last fetch failed
+			orgStatus := state.Orgs[node.Value]
+			lastSync.SetText(" -")
+			if !orgStatus.LastSync.IsZero() {
+				lastSync.SetText(" " + utils.ShortRelativeTime(orgStatus.LastSync))
+			}
+			switch {
+			case orgStatus.LastError != "":
+				cell.SetTextColor(errorColor)
+				lastSync.SetTextColor(errorColor)
+			case slices.Contains(stale, node.Value):
+				cell.SetTextColor(warningColor)
+				lastSync.SetTextColor(warningColor)
+			}
+		}
+
+		cells := []*tview.TableCell{cell, count, schedule, lastSync}
+		if slices.Contains(panelFilter, node) {
+			cell.SetTextColor(tcell.ColorBlack)
+			for _, c := range cells {
+				c.SetBackgroundColor(tcell.ColorWhite)
+			}
+		}
+		for col, c := range cells {
+			table.SetCell(row, col, c)
+		}
+	}
+
+	// Keep the selection on a selectable row as nodes come and go
+	if panelRow < 0 || panelRow >= len(panelRows) || panelRows[panelRow].Kind == nodeHeader {
+		panelRow = slices.IndexFunc(panelRows, func(node panelNode) bool {
+			return node.Kind != nodeHeader
+		})
+	}
+	if panelRow >= 0 {
+		table.Select(panelRow, 0)
+	}
+
+	table.SetSelectionChangedFunc(func(row, column int) {
+		if row >= 0 {
+			panelRow = row
+		}
+	})
+	table.SetSelectedFunc(func(row, column int) {
+		// Filter by just this node, or clear the filter if it already is
+		node := panelRows[row]
+		if slices.Equal(panelFilter, []panelNode{node}) {
+			panelFilter = nil
+		} else {
+			panelFilter = []panelNode{node}
+		}
+		selectedRow = 1
+		RefreshChan <- struct{}{}
+	})
+	table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		node, ok := selectedPanelNode()
+		if !ok {
+			return event
+		}
+
+		switch {
+		// Right or "l" expands an org
+		case event.Key() == tcell.KeyRight || event.Key() == tcell.KeyRune && event.Rune() == 'l':
+			if node.Kind == nodeOrg && !expanded[node.Value] {
+				setExpanded(node.Value, true)
+			}
+			return nil
+
+		// Left or "h" collapses an org, or moves from a repo to its org
+		case event.Key() == tcell.KeyLeft || event.Key() == tcell.KeyRune && event.Rune() == 'h':
+			switch node.Kind {
+			case nodeOrg:
+				if expanded[node.Value] {
+					setExpanded(node.Value, false)
+				}
+			case nodeRepo:
+				org, _, _ := strings.Cut(node.Value, "/")
+				panelRow = slices.Index(panelRows, panelNode{Kind: nodeOrg, Value: org})
+				RefreshChan <- struct{}{}
+			}
+			return nil
+
+		// Space adds or removes the node from the filter, to filter by several
+		case event.Key() == tcell.KeyRune && event.Rune() == ' ':
+			if i := slices.Index(panelFilter, node); i >= 0 {
+				panelFilter = slices.Delete(panelFilter, i, i+1)
+			} else {
+				panelFilter = append(panelFilter, node)
+			}
+			selectedRow = 1
+			RefreshChan <- struct{}{}
+			return nil
+		}
+		return event
+	})
+
+	title := "Orgs"
+	if len(conf.Searches) > 0 {
+		title = "Searches & Orgs"
+	}
+
+	flex := tview.NewFlex().SetDirection(tview.FlexRow)
+	flex.SetTitle(title).SetTitleColor(primaryColor).SetBorder(true)
+	if panelFocused {
+		flex.SetBorderColor(primaryColor)
+	}
+	flex.AddItem(table, 0, 1, true)
+	return flex
+}
+
+// setExpanded opens or closes an org in the side panel, saving it for next time
+func setExpanded(org string, open bool) {
+	if open {
+		expanded[org] = true
+	} else {
+		delete(expanded, org)
+	}
+
+	orgs := slices.Sorted(maps.Keys(expanded))
+	if err := state.Update(func(s *state.State) { s.Expanded = orgs }); err != nil {
+		logging.Error(fmt.Sprintf("Failed to save TUI state: %v", err))
+	}
+	RefreshChan <- struct{}{}
+}
+
+// loadExpanded restores the orgs open in the side panel
+func loadExpanded() {
+	s, err := state.Load()
+	if err != nil {
+		logging.Error(fmt.Sprintf("Failed to load TUI state: %v", err))
+		return
+	}
+	for _, org := range s.Expanded {
+		expanded[org] = true
+	}
+}
+
+// selectedPanelNode returns the node under the panel selection
+func selectedPanelNode() (panelNode, bool) {
+	if panelRow < 0 || panelRow >= len(panelRows) {
+		return panelNode{}, false
+	}
+	return panelRows[panelRow], true
+}
+
+// saveSearchView prompts for a name to save the current search under
+func saveSearchView() tview.Primitive {
+	nameField := tview.NewInputField().
+		SetLabel("Save search as: ").
+		SetLabelColor(primaryColor).
+		SetFieldBackgroundColor(tcell.Color238).
+		SetFieldTextColor(tcell.ColorWhite)
+
+	nameField.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEnter && nameField.GetText() != "" {
+			search := config.SavedSearch{Name: nameField.GetText(), Query: searchQuery}
+			if err := saveSearch(search); err != nil {
+				logging.Error(fmt.Sprintf("Failed to save search: %v", err))
+				tuiErrors.Error("", fmt.Errorf("saving search: %w", err))
+			} else {
+				logging.Info(fmt.Sprintf("Saved search: %s", search.Name))
+			}
+		}
+		showSaveSearch = false
+		RefreshChan <- struct{}{}
+	})
+
+	return tview.NewFlex().
+		AddItem(nil, 1, 0, false). // Left padding
+		AddItem(nameField, 0, 1, true).
+		AddItem(nil, 1, 0, false) // Right padding
+}
+
+// saveSearch adds or replaces a saved search in config
+func saveSearch(search config.SavedSearch) error {
+	if _, err := query.Parse(search.Query); err != nil {
+		return err
+	}
+
+	c, err := config.LoadConfig()
+	if err != nil {
+		return err
+	}
+	c.SaveSearch(search)
+	if err := config.SaveConfig(c); err != nil {
+		return err
+	}
+	conf = c
+	return nil
+}
+
+// deleteSearch removes a saved search from config
+func deleteSearch(name string) error {
+	c, err := config.LoadConfig()
+	if err != nil {
+		return err
+	}
+	c.DeleteSearch(name)
+	if err := config.SaveConfig(c); err != nil {
+		return err
+	}
+	conf = c
+	return nil
+}
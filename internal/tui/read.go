@@ -0,0 +1,98 @@
+package tui
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/shaunmolloy/bugbox/internal/daemon"
+	"github.com/shaunmolloy/bugbox/internal/logging"
+	"github.com/shaunmolloy/bugbox/internal/types"
+)
+
+// readUndo holds the read state of the issues changed by the last bulk
+// change, from before it was made
+var readUndo []daemon.MarkReadParams
+
+// selectNext is the issue to select on the next redraw, so marking an issue
+// read moves to the one after it even if the read issue leaves the list
+var selectNext *types.Issue
+
+// setRead marks one issue read or unread
+func setRead(issue types.Issue, read bool) {
+	// Undoing a bulk change leaves issues changed by hand since
+	readUndo = slices.DeleteFunc(readUndo, func(undo daemon.MarkReadParams) bool {
+		return sameIssue(issue, undo.Org, undo.Repo, undo.ID)
+	})
+
+	if err := Source.MarkRead(issue.Org, issue.Repo, issue.ID, read); err != nil {
+		logging.Error(fmt.Sprintf("Failed to save issues: %v", err))
+		tuiErrors.Error("", fmt.Errorf("saving issues: %w", err))
+	}
+
+	cache.Invalidate()
+	RefreshChan <- struct{}{} // Trigger a refresh
+}
+
+// markAllRead marks every unread issue read in one change, which can be undone
+func markAllRead(issues []types.Issue) {
+	var changes, undo []daemon.MarkReadParams
+	for _, issue := range issues {
+		if !issue.Read {
+			changes = append(changes, daemon.MarkReadParams{Org: issue.Org, Repo: issue.Repo, ID: issue.ID, Read: true})
+			undo = append(undo, daemon.MarkReadParams{Org: issue.Org, Repo: issue.Repo, ID: issue.ID, Read: false})
+		}
+	}
+	if len(changes) == 0 {
+		return
+	}
+
+	logging.Info(fmt.Sprintf("Marking %d issues read", len(changes)))
+	if applyRead(changes) {
+		readUndo = undo
+	}
+}
+
+// undoRead reverts the last bulk change, leaving issues whose read state has
+// changed since, e.g. by opening them or from another TUI
+func undoRead(issues []types.Issue) {
+	if len(readUndo) == 0 {
+		return
+	}
+
+	var changes []daemon.MarkReadParams
+	for _, undo := range readUndo {
+		i := slices.IndexFunc(issues, func(issue types.Issue) bool {
+			return sameIssue(issue, undo.Org, undo.Repo, undo.ID)
+		})
+		if i >= 0 && issues[i].Read != undo.Read {
+			changes = append(changes, undo)
+		}
+	}
+	if len(changes) == 0 {
+		readUndo = nil
+		return
+	}
+
+	logging.Info(fmt.Sprintf("Undoing read state of %d issues", len(changes)))
+	if applyRead(changes) {
+		readUndo = nil
+	}
+}
+
+// sameIssue returns true if issue is org/repo#id
+func sameIssue(issue types.Issue, org string, repo string, id int) bool {
+	return issue.Org == org && issue.Repo == repo && issue.ID == id
+}
+
+// applyRead saves read states, returning false if they couldn't be saved
+func applyRead(changes []daemon.MarkReadParams) bool {
+	err := Source.MarkReadMany(changes)
+	if err != nil {
+		logging.Error(fmt.Sprintf("Failed to save issues: %v", err))
+		tuiErrors.Error("", fmt.Errorf("saving issues: %w", err))
+	}
+
+	cache.Invalidate()
+	RefreshChan <- struct{}{} // Trigger a refresh
+	return err == nil
+}
@@ -0,0 +1,67 @@
+package tui
+
+import (
+	"fmt"
+
+	"github.com/shaunmolloy/bugbox/internal/logging"
+	"github.com/shaunmolloy/bugbox/internal/sorting"
+	"github.com/shaunmolloy/bugbox/internal/storage/config"
+	"github.com/shaunmolloy/bugbox/internal/storage/state"
+)
+
+// Sort state, restored from state at startup
+var (
+	sortOrder = sorting.Default
+	// columnHeaders are the issues table's columns as last drawn
+	columnHeaders []config.Column
+)
+
+// sortArrow shows the direction issues are sorted in
+func sortArrow() string {
+	if sortOrder.Desc {
+		return "↓"
+	}
+	return "↑"
+}
+
+// sortByColumn sorts by the nth column header, or reverses the order when
+// already sorted by it
+func sortByColumn(n int) {
+	if n < 0 || n >= len(columnHeaders) {
+		return
+	}
+	field, ok := columnSorts[columnHeaders[n].Name]
+	if !ok {
+		return
+	}
+	if field == sortOrder.Field {
+		setSort(sortOrder.Reverse())
+	} else {
+		setSort(sorting.By(field))
+	}
+}
+
+// setSort reorders the issues, saving the order for next time
+func setSort(order sorting.Order) {
+	sortOrder = order
+	logging.Info(fmt.Sprintf("Sorting by %s", order))
+	if err := state.Update(func(s *state.State) { s.Sort = order }); err != nil {
+		logging.Error(fmt.Sprintf("Failed to save TUI state: %v", err))
+	}
+
+	selectedRow = 1
+	cache.Invalidate()
+	RefreshChan <- struct{}{}
+}
+
+// loadSort restores the order issues were sorted in
+func loadSort() {
+	s, err := state.Load()
+	if err != nil {
+		logging.Error(fmt.Sprintf("Failed to load TUI state: %v", err))
+		return
+	}
+	if s.Sort.Valid() {
+		sortOrder = s.Sort
+	}
+}
@@ -1,27 +1,46 @@
 package tui
 
 import (
+	"cmp"
+	"context"
 	"fmt"
 	"os/exec"
 	"runtime"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
+	"github.com/shaunmolloy/bugbox/internal/daemon"
+	"github.com/shaunmolloy/bugbox/internal/events"
+	"github.com/shaunmolloy/bugbox/internal/fuzzy"
+	"github.com/shaunmolloy/bugbox/internal/grouping"
 	"github.com/shaunmolloy/bugbox/internal/logging"
+	"github.com/shaunmolloy/bugbox/internal/query"
+	"github.com/shaunmolloy/bugbox/internal/sorting"
+	"github.com/shaunmolloy/bugbox/internal/status"
 	"github.com/shaunmolloy/bugbox/internal/storage/config"
+	"github.com/shaunmolloy/bugbox/internal/types"
 	"github.com/shaunmolloy/bugbox/internal/utils"
 )
 
 // RefreshChan is a channel that receives signals to refresh the TUI
 var RefreshChan = make(chan struct{}, 1)
 
+// Source is where issues come from, in-process or a running daemon, set by main
+var Source daemon.Backend
+
 // Global state for controlling UI elements
 var (
 	conf               = config.Config{}
 	showSearch         = false
+	showErrors         = false
 	searchQuery        = ""
-	orgFilter          = ""
+	searchErr          error
+	searchText         []string
+	fuzzySearch        = false
+	showSaveSearch     = false
 	useVerticalLayout  = false
 	currentScreenWidth = 0
 	selectedRow        = 1
@@ -29,13 +48,12 @@ var (
 	primaryColor   = tcell.ColorLimeGreen
 	secondaryColor = tcell.ColorDarkOliveGreen
 	grayColor      = tcell.ColorDarkGray
+	errorColor     = tcell.ColorRed
+	warningColor   = tcell.ColorYellow
 )
 
 const (
-	breakpointSmall  = 72
-	breakpointXSmall = 100
-	breakpointMedium = 130
-	breakpointLarge  = 180
+	breakpointLarge = 180
 )
 
 // Init function to set up the global styles
@@ -46,12 +64,28 @@ func init() {
 	tview.Styles.TitleColor = tcell.ColorGrey
 }
 
-// Start initializes and runs the TUI
-func Start() error {
+// Start initializes and runs the TUI until quit or ctx is cancelled
+func Start(ctx context.Context) error {
 	app := tview.NewApplication()
 	app.EnableMouse(false) // Disable mouse input
 
+	// Stop the TUI on SIGINT/SIGTERM
+	go func() {
+		<-ctx.Done()
+		app.Stop()
+	}()
+
 	handleKeyboardShortcuts(app)
+	loadExpanded()
+	loadSort()
+	loadGroup()
+
+	// Redraw as issues are fetched and sync progress changes
+	updates, err := Source.Subscribe(ctx)
+	if err != nil {
+		return fmt.Errorf("subscribing to updates: %w", err)
+	}
+	go watch(ctx, updates)
 
 	// Go routine for refresh handler and resize handler
 	go func() {
@@ -71,6 +105,7 @@ func Start() error {
 	app.SetBeforeDrawFunc(func(screen tcell.Screen) bool {
 		width, _ := screen.Size()
 		currentScreenWidth = width
+		screenColors = screen.Colors()
 
 		if width != lastWidth {
 			RefreshChan <- struct{}{} // Trigger a refresh
@@ -91,35 +126,118 @@ func Start() error {
 	return nil
 }
 
+// maxReconnectDelay caps the wait between attempts to resubscribe
+const maxReconnectDelay = 30 * time.Second
+
+// watch redraws on each update, resubscribing with backoff whenever the
+// daemon goes away, until ctx is cancelled
+func watch(ctx context.Context, updates <-chan events.Event) {
+	for {
+		for event := range updates {
+			if event.Type == events.IssuesUpdated {
+				cache.Invalidate()
+			}
+			redraw()
+		}
+		redraw() // Show the daemon is unavailable
+
+		for attempt := 0; ; attempt++ {
+			delay := min(time.Second<<attempt, maxReconnectDelay)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(delay):
+			}
+
+			var err error
+			if updates, err = Source.Subscribe(ctx); err == nil {
+				break
+			}
+			logging.Debug(fmt.Sprintf("Resubscribing failed: %v", err))
+		}
+		logging.Info("Resubscribed to updates")
+
+		// Updates may have been missed while disconnected
+		cache.Invalidate()
+		redraw()
+	}
+}
+
+// redraw requests a refresh unless one is already pending
+func redraw() {
+	select {
+	case RefreshChan <- struct{}{}:
+	default:
+	}
+}
+
 func layout() tview.Primitive {
 	rootFlex := tview.NewFlex().SetDirection(tview.FlexRow)
 
+	// Fetch sync status once for every view showing it
+	state, stateErr := Source.Status()
+	state = withTUIErrors(state)
+
 	// Use vertical layout for small screens
 	useVerticalLayout = currentScreenWidth < breakpointLarge
+	// Focus on issues or the side panel when not typing or picking labels
+	typing := showSearch || showSaveSearch || showLabels
 	if useVerticalLayout {
-		rootFlex.AddItem(issuesView(), 0, 3, !showSearch) // Issues take 3/4 of height
-		rootFlex.AddItem(orgsView(), 0, 1, false)         // Orgs take 1/4 of height
+		rootFlex.AddItem(issuesView(), 0, 3, !typing && !panelFocused)    // Issues take 3/4 of height
+		rootFlex.AddItem(panelView(state), 0, 1, !typing && panelFocused) // Side panel takes 1/4 of height
 	} else {
 		// Default horizontal layout for wider screens
 		innerFlex := tview.NewFlex().SetDirection(tview.FlexColumn).
-			AddItem(issuesView(), 0, 1, !showSearch). // Focus on issues when search is hidden
-			AddItem(orgsView(), 30, 0, false)         // Orgs take fixed 30 columns
+			AddItem(issuesView(), 0, 1, !panelFocused).
+			AddItem(panelView(state), panelWidth, 0, panelFocused) // Side panel has a fixed width
 
-		rootFlex.AddItem(innerFlex, 0, 1, !showSearch)
+		rootFlex.AddItem(innerFlex, 0, 1, !typing)
+	}
+
+	if showErrors {
+		rootFlex.AddItem(errorsView(state), 10, 0, false)
+	}
+
+	if showLabels {
+		rootFlex.AddItem(labelsView(), 12, 0, true) // Focus on the label picker when visible
 	}
 
 	if showSearch {
 		rootFlex.AddItem(searchView(), 1, 0, true) // Focus on search when visible
 	}
 
+	if showSaveSearch {
+		rootFlex.AddItem(saveSearchView(), 1, 0, true)
+	}
+
+	rootFlex.AddItem(statusView(state, stateErr), 1, 0, false)
 	rootFlex.AddItem(shortcutsView(), 1, 0, false)
 	return rootFlex
 }
 
 func handleKeyboardShortcuts(app *tview.Application) {
 	app.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
-		// If "q" is pressed, stop the application
-		if event.Key() == tcell.KeyRune && event.Rune() == 'q' {
+		// Leave keys to the name field while saving a search
+		if showSaveSearch {
+			return event
+		}
+
+		// Leave keys to the label picker, closing it on "l" or "Esc"
+		if showLabels {
+			if event.Key() == tcell.KeyEscape || event.Key() == tcell.KeyRune && event.Rune() == 'l' {
+				showLabels = false
+				RefreshChan <- struct{}{}
+				return nil // Consume the event
+			}
+			if event.Key() == tcell.KeyRune && event.Rune() == 'q' {
+				app.Stop()
+				return nil
+			}
+			return event
+		}
+
+		// If "q" is pressed, stop the application, unless it's typed in a search
+		if event.Key() == tcell.KeyRune && event.Rune() == 'q' && !showSearch {
 			app.Stop()
 			return nil
 		}
@@ -132,234 +250,370 @@ func handleKeyboardShortcuts(app *tview.Application) {
 			return nil // Consume the event
 		}
 
-		// If "Tab" is pressed, filter by next org
-		if event.Key() == tcell.KeyTab && !showSearch {
-			cycleOrgFilter()
-			logging.Info(fmt.Sprintf("Filtering by org: %s", fallback(orgFilter, "(none)")))
+		// If "Ctrl-F" is pressed, toggle fuzzy search
+		if event.Key() == tcell.KeyCtrlF {
+			fuzzySearch = !fuzzySearch
+			logging.Info(fmt.Sprintf("Fuzzy search: %t", fuzzySearch))
 			RefreshChan <- struct{}{}
 			return nil // Consume the event
 		}
 
-		// If "Esc" is pressed, clear the org filter
-		if event.Key() == tcell.KeyEscape && orgFilter != "" {
-			orgFilter = ""
-			logging.Info("Clearing org filter")
+		// If "r" is pressed, fetch issues now
+		if event.Key() == tcell.KeyRune && event.Rune() == 'r' && !showSearch {
+			logging.Info("Refresh requested from TUI")
+			if err := Source.Refresh(); err != nil {
+				logging.Error(fmt.Sprintf("Failed to refresh: %v", err))
+				tuiErrors.Error("", fmt.Errorf("refreshing: %w", err))
+				redraw()
+			}
+			return nil // Consume the event
+		}
+
+		// If "e" is pressed, toggle the error panel
+		if event.Key() == tcell.KeyRune && event.Rune() == 'e' && !showSearch {
+			showErrors = !showErrors
 			RefreshChan <- struct{}{}
 			return nil // Consume the event
 		}
 
-		return event
-	})
-}
+		// If "x" is pressed with the error panel open, dismiss the errors
+		if event.Key() == tcell.KeyRune && event.Rune() == 'x' && showErrors && !showSearch {
+			if err := Source.DismissErrors(); err != nil {
+				logging.Error(fmt.Sprintf("Failed to dismiss errors: %v", err))
+			}
+			tuiErrors.DismissErrors()
+			showErrors = false
+			logging.Info("Dismissed errors")
+			RefreshChan <- struct{}{}
+			return nil // Consume the event
+		}
 
-func cycleOrgFilter() {
-	if len(conf.Orgs) == 0 {
-		return
-	}
+		// If "l" is pressed on the issues, open the label picker
+		if event.Key() == tcell.KeyRune && event.Rune() == 'l' && !panelFocused && !showSearch {
+			showLabels = true
+			RefreshChan <- struct{}{}
+			return nil // Consume the event
+		}
 
-	// If orgFilter is empty, set it to the first org
-	if orgFilter == "" {
-		orgFilter = conf.Orgs[0]
-		return
-	}
+		// If "o" is pressed, sort by the next field, or "O" to reverse the order
+		if event.Key() == tcell.KeyRune && event.Rune() == 'o' && !showSearch {
+			setSort(sortOrder.Next())
+			return nil // Consume the event
+		}
+		if event.Key() == tcell.KeyRune && event.Rune() == 'O' && !showSearch {
+			setSort(sortOrder.Reverse())
+			return nil // Consume the event
+		}
 
-	// Find the index of the current orgFilter
-	index := indexOf(conf.Orgs, orgFilter)
+		// If "g" is pressed, group issues by the next field
+		if event.Key() == tcell.KeyRune && event.Rune() == 'g' && !showSearch {
+			setGroup(groupBy.Next())
+			return nil // Consume the event
+		}
 
-	// Can we switch to the next org?
-	if index+1 < len(conf.Orgs) {
-		orgFilter = conf.Orgs[index+1]
-		return
-	}
+		// If "1" to "9" is pressed on the issues, sort by that column
+		if event.Key() == tcell.KeyRune && event.Rune() >= '1' && event.Rune() <= '9' && !panelFocused && !showSearch {
+			sortByColumn(int(event.Rune() - '1'))
+			return nil // Consume the event
+		}
 
-	// If orgFilter was last org, reset to empty
-	orgFilter = ""
-}
+		// If "Tab" is pressed, switch between the issues and the side panel
+		if event.Key() == tcell.KeyTab && !showSearch {
+			panelFocused = !panelFocused
+			RefreshChan <- struct{}{}
+			return nil // Consume the event
+		}
 
-func orgsView() tview.Primitive {
-	table := tview.NewTable().SetFixed(1, 0)
-	for row, org := range conf.Orgs {
-		cell := tview.NewTableCell(org)
-		if org == orgFilter {
-			cell.SetBackgroundColor(tcell.ColorWhite).
-				SetTextColor(tcell.ColorBlack).
-				SetExpansion(1)
+		// If "s" is pressed, save the current search
+		if event.Key() == tcell.KeyRune && event.Rune() == 's' && !showSearch && searchQuery != "" && !fuzzySearch {
+			showSaveSearch = true
+			RefreshChan <- struct{}{}
+			return nil // Consume the event
 		}
-		table.SetCell(row+0, 0, cell)
-	}
 
-	// Set title to indicate filtering
-	title := fmt.Sprintf("Orgs (%d)", len(conf.Orgs))
+		// If "d" is pressed on a saved search in the side panel, delete it
+		if event.Key() == tcell.KeyRune && event.Rune() == 'd' && panelFocused && !showSearch {
+			if node, ok := selectedPanelNode(); ok && node.Kind == nodeSearch {
+				if err := deleteSearch(node.Value); err != nil {
+					logging.Error(fmt.Sprintf("Failed to delete search: %v", err))
+					tuiErrors.Error("", fmt.Errorf("deleting search: %w", err))
+				}
+				panelFilter = slices.DeleteFunc(panelFilter, func(filter panelNode) bool {
+					return filter == node
+				})
+				logging.Info(fmt.Sprintf("Deleted search: %s", node.Value))
+				RefreshChan <- struct{}{}
+			}
+			return nil // Consume the event
+		}
 
-	flex := tview.NewFlex().SetDirection(tview.FlexRow)
-	flex.SetTitle(title).SetTitleColor(primaryColor).SetBorder(true)
+		// If "Esc" is pressed, close the error panel, then clear the side panel filter
+		if event.Key() == tcell.KeyEscape && showErrors && !showSearch {
+			showErrors = false
+			RefreshChan <- struct{}{}
+			return nil // Consume the event
+		}
+		if event.Key() == tcell.KeyEscape && len(panelFilter) > 0 && !showSearch {
+			panelFilter = nil
+			logging.Info("Clearing filter")
+			RefreshChan <- struct{}{}
+			return nil // Consume the event
+		}
 
-	flex.AddItem(table, 0, 1, false)
-	return flex
+		return event
+	})
 }
 
 func issuesView() tview.Primitive {
-	issuesMap, _ := config.LoadIssues()
-	issues := config.FlattenIssues(issuesMap)
-	sortIssues(issues)
+	issues, _ := cache.Issues()
 
 	// Create a selectable table
 	table := tview.NewTable().
 		SetFixed(1, 0).            // Lock header row
 		SetSelectable(true, false) // Enable row selection only
 
-	// Define column widths - use expansion to control the width ratios
-	colExpansions := []int{8, 1, 1} // Title, Org, Created
+	// Columns are configurable, with breakpoints hiding some on small screens
+	columns := visibleColumns()
+	widths := columnWidths(columns)
+	chipsColumn := labelsAfter(columns)
 
-	// Header row
-	headers := []string{"Title", "Org", "Created"}
-
-	// Add "Repo" column if the screen is wide enough
-	if currentScreenWidth > breakpointMedium {
-		headers = append(headers, "")            // extend slice by 1
-		index := 2                               // insert after "Org"
-		copy(headers[index+1:], headers[index:]) // shift elements right
-		headers[index] = "Repo"                  // insert new value
-		colExpansions = []int{7, 1, 1, 1}        // Title, Repo, Org, Created
-	}
+	// Mark the column issues are sorted by
+	columnHeaders = columns
+	sortColumn := slices.IndexFunc(columns, func(column config.Column) bool {
+		field, ok := columnSorts[column.Name]
+		return ok && field == sortOrder.Field
+	})
 
-	for i, h := range headers {
-		cell := tview.NewTableCell(fmt.Sprintf("[::b]%s", h)).
+	// Header row
+	for i, column := range columns {
+		header := columnTitles[column.Name]
+		if i == sortColumn {
+			header += " " + sortArrow()
+		}
+		cell := tview.NewTableCell(fmt.Sprintf("[::b]%s", header)).
 			SetTextColor(tcell.ColorLightGrey).
-			SetExpansion(colExpansions[i])
+			SetAlign(columnAlign(column)).
+			SetExpansion(column.Expansion())
+		if column.Width > 0 {
+			// Pad the header so the column is as wide as set
+			width := -column.Width
+			if columnAlign(column) == tview.AlignRight {
+				width = column.Width
+			}
+			cell.SetText(fmt.Sprintf("[::b]%*s", width, header)).SetMaxWidth(column.Width)
+		}
 		table.SetCell(0, i, cell)
 	}
 
 	// Filter issues based on searchQuery and orgFilter
-	filteredIssues := issues
-
-	// Apply filters
-	if searchQuery != "" || orgFilter != "" {
-		filteredIssues = nil
-		query := strings.ToLower(searchQuery)
-		for _, issue := range issues {
-			// Check if issue matches all active filters
-			matchesSearch := true
-			matchesOrg := true
-
-			// Apply search filter if active
-			if searchQuery != "" {
-				matchesSearch = strings.Contains(strings.ToLower(issue.Title), query) ||
-					strings.Contains(strings.ToLower(issue.Org), query)
-			}
-
-			// Apply org filter if active
-			if orgFilter != "" {
-				matchesOrg = issue.Org == orgFilter
+	results := searchIssues(issues)
+
+	// Apply the side panel filter if active
+	if match := panelMatcher(); match != nil {
+		var inPanel []fuzzy.Result
+		for _, result := range results {
+			if match(result.Issue) {
+				inPanel = append(inPanel, result)
 			}
+		}
+		results = inPanel
+	}
 
-			// Add issue if it matches all active filters
-			if matchesSearch && matchesOrg {
-				filteredIssues = append(filteredIssues, issue)
+	// Apply the label picker's filter if active
+	if len(labelInclude) > 0 || len(labelExclude) > 0 {
+		var labelled []fuzzy.Result
+		for _, result := range results {
+			if labelMatch(result.Issue) {
+				labelled = append(labelled, result)
 			}
 		}
+		results = labelled
 	}
 
-	// Data rows
-	for row, issue := range filteredIssues {
-		// Truncate title based on screen width
-		title := issue.Title
-		switch {
-		case currentScreenWidth < breakpointSmall && len(title) > 30:
-			title = title[:30]
-			break
-		case currentScreenWidth < breakpointXSmall && len(title) > 50:
-			title = title[:50]
-			break
-		case currentScreenWidth < breakpointLarge:
-			if len(title) > 72 {
-				title = title[:72]
-			}
-			if len(issue.Org) > 15 {
-				issue.Org = issue.Org[:15]
+	filteredIssues := make([]types.Issue, len(results))
+	for i, result := range results {
+		filteredIssues[i] = result.Issue
+	}
+
+	// Group headers go in the title column, or the first
+	headerColumn := max(slices.IndexFunc(columns, func(column config.Column) bool {
+		return column.Name == "title"
+	}), 0)
+
+	// Data rows, under group headers when grouping
+	rows := issueRows(results)
+	for i, row := range rows {
+		if row.result == nil {
+			// Headers are skipped by navigation unless collapsed, so they can be expanded
+			marker := "▾"
+			if groupCollapsed(row.group) {
+				marker = "▸"
 			}
-			if len(issue.Repo) > 15 {
-				issue.Repo = issue.Repo[:15]
+			text := fmt.Sprintf("[::b]%s %s[-::-]  %s", marker, tview.Escape(cmp.Or(row.group, "-")), formatCounts(row.counts))
+			for col := range columns {
+				cell := tview.NewTableCell("").SetSelectable(groupCollapsed(row.group))
+				if col == headerColumn {
+					cell.SetText(text).SetTextColor(primaryColor)
+				}
+				table.SetCell(i+1, col, cell)
 			}
-			break
-		case len(title) > 120:
-			title = title[:120]
-			break
+			continue
 		}
 
-		cells := []*tview.TableCell{
-			tview.NewTableCell(title),
-			tview.NewTableCell(issue.Org),
+		for col, column := range columns {
+			cell := columnCell(column, widths[col], *row.result, col == chipsColumn)
+			if row.result.Issue.Read {
+				cell.SetTextColor(grayColor)
+			}
+			table.SetCell(i+1, col, cell)
 		}
+	}
 
-		if currentScreenWidth > breakpointMedium {
-			cells = append(cells, tview.NewTableCell(issue.Repo))
+	// issueAt returns the issue on a table row, if it isn't a header
+	issueAt := func(row int) (types.Issue, bool) {
+		if row < 1 || row > len(rows) || rows[row-1].result == nil {
+			return types.Issue{}, false
 		}
+		return rows[row-1].result.Issue, true
+	}
 
-		createdAt := utils.RelativeTime(issue.CreatedAt)
-		cells = append(cells, tview.NewTableCell(createdAt))
+	// selectable returns true if the row can be selected
+	selectable := func(row int) bool {
+		if row < 1 || row > len(rows) {
+			return false
+		}
+		return rows[row-1].result != nil || groupCollapsed(rows[row-1].group)
+	}
 
-		for col, cell := range cells {
-			if issue.Read {
-				cell.SetTextColor(grayColor)
+	// Handle selection - only allow selecting issues and collapsed groups,
+	// not the header
+	if len(rows) > 0 {
+		// Select the issue after one just marked read, wherever it is now
+		if selectNext != nil {
+			next := *selectNext
+			selectNext = nil
+			if i := slices.IndexFunc(rows, func(row issueRow) bool {
+				return row.result != nil && sameIssue(row.result.Issue, next.Org, next.Repo, next.ID)
+			}); i >= 0 {
+				selectedRow = i + 1
 			}
-			table.SetCell(row+1, col, cell)
 		}
+
+		// Use the remembered selected row if possible, else the first selectable
+		if !selectable(selectedRow) {
+			selectedRow = max(slices.IndexFunc(rows, func(row issueRow) bool {
+				return row.result != nil || groupCollapsed(row.group)
+			})+1, 1)
+		}
+		table.Select(selectedRow, 0)
 	}
 
-	// Handle selection - only allow selecting data rows, not the header
-	if len(filteredIssues) > 0 {
-		// Use the remembered selected row if possible
-		if selectedRow < len(filteredIssues)+1 {
-			table.Select(selectedRow, 0)
-		} else {
-			table.Select(1, 0) // Select first data row if previous selection is out of bounds
-			selectedRow = 1
+	// Show where the search matched the selected issue's body or comments
+	snippet := tview.NewTextView().SetDynamicColors(true).SetTextColor(grayColor)
+	showSnippet := func(row int) {
+		snippet.SetText("")
+		issue, ok := issueAt(row)
+		if len(searchText) == 0 || !ok {
+			return
+		}
+		if match, ok := cache.Docs().Snippet(issue, searchText); ok {
+			var positions []int
+			for i := match.Start; i < match.End; i++ {
+				positions = append(positions, i)
+			}
+			snippet.SetText(highlight(match.Text, positions, 0))
 		}
 	}
+	showSnippet(selectedRow)
 
 	// Custom selection handler to prevent selecting header and update selected row
 	table.SetSelectionChangedFunc(func(row, column int) {
-		// If header row is selected, move to first data row if available
-		if row == 0 && len(filteredIssues) > 0 {
+		// If header row is selected, move to the first data row if available
+		if row == 0 && len(rows) > 0 {
 			table.Select(1, 0)
-			selectedRow = 1
-		} else if row > 0 {
-			// Update selected row when user navigates
-			selectedRow = row
+			return
+		}
+		if row == 0 || !selectable(row) {
+			return
 		}
+		// Update selected row when user navigates
+		selectedRow = row
+		showSnippet(selectedRow)
+	})
+
+	table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyRune {
+			switch event.Rune() {
+			// "t" toggles the selected issue between read and unread
+			case 't':
+				if issue, ok := issueAt(selectedRow); ok {
+					setRead(issue, !issue.Read)
+				}
+				return nil
+
+			// "m" marks the selected issue read without opening it, moving to the next
+			case 'm':
+				if issue, ok := issueAt(selectedRow); ok {
+					if next := slices.IndexFunc(rows[selectedRow:], func(r issueRow) bool { return r.result != nil }); next >= 0 {
+						selectNext = &rows[selectedRow+next].result.Issue
+					}
+					setRead(issue, true)
+				}
+				return nil
+
+			// "M" marks every issue in the current filter read, and "u" undoes it
+			case 'M':
+				markAllRead(filteredIssues)
+				return nil
+			case 'u':
+				undoRead(issues)
+				return nil
+			}
+		}
+
+		// Left collapses the selected issue's group, Right expands a collapsed group
+		if groupBy == grouping.None || selectedRow < 1 || selectedRow > len(rows) {
+			return event
+		}
+		row := rows[selectedRow-1]
+		switch event.Key() {
+		case tcell.KeyLeft:
+			if !groupCollapsed(row.group) {
+				// Keep the collapsed group selected
+				selectedRow = slices.IndexFunc(rows, func(r issueRow) bool {
+					return r.result == nil && r.group == row.group
+				}) + 1
+				setCollapsed(row.group, true)
+			}
+			return nil
+		case tcell.KeyRight:
+			if row.result == nil {
+				setCollapsed(row.group, false)
+			}
+			return nil
+		}
+		return event
 	})
 
 	table.SetSelectedFunc(func(row, column int) {
+		// Enter on a collapsed group expands it
+		if row > 0 && row <= len(rows) && rows[row-1].result == nil {
+			setCollapsed(rows[row-1].group, false)
+			return
+		}
+
 		// Handle row selection (user pressed Enter on a row)
-		if row > 0 && row <= len(filteredIssues) {
-			issue := filteredIssues[row-1]
+		if issue, ok := issueAt(row); ok {
 			logging.Info(fmt.Sprintf("Opening issue in browser: %s", issue.Title))
 
 			// Open the issue URL in the default browser
 			if err := openBrowser(issue.URL); err != nil {
 				logging.Error(fmt.Sprintf("Failed to open browser: %v", err))
+				tuiErrors.Error("", fmt.Errorf("opening browser: %w", err))
 			}
 
-			// Mark issue as read
-			issue.Read = true
-
-			// Update the issue in the map
-			if _, ok := issuesMap[issue.Org]; ok {
-				if _, ok := issuesMap[issue.Org][issue.Repo]; ok {
-					if _, ok := issuesMap[issue.Org][issue.Repo][issue.ID]; ok {
-						issuesMap[issue.Org][issue.Repo][issue.ID] = issue
-					}
-				}
-			}
-
-			RefreshChan <- struct{}{} // Trigger a refresh
-
-			// Save the updated issues back to the config
-			if err := config.SaveIssues(issuesMap); err != nil {
-				logging.Error(fmt.Sprintf("Failed to save issues: %v", err))
-			}
-			logging.Info("Saved issues to config")
+			// Mark issue as read, merging with any concurrent fetch
+			setRead(issue, true)
 		}
 	})
 
@@ -368,16 +622,72 @@ func issuesView() tview.Primitive {
 	if len(filteredIssues) != len(issues) {
 		title = fmt.Sprintf("Issues (%d/%d)", len(filteredIssues), len(issues))
 	}
+	if len(panelFilter) > 0 {
+		names := make([]string, len(panelFilter))
+		for i, node := range panelFilter {
+			names[i] = node.Value
+		}
+		title += " - " + tview.Escape(strings.Join(names, ", "))
+	}
+	if text := labelFilterText(); text != "" {
+		title += " - " + tview.Escape(text)
+	}
+	if fuzzySearch {
+		title += " - fuzzy"
+	} else if sortOrder != sorting.Default {
+		title += fmt.Sprintf(" - by %s %s", sortOrder.Field, sortArrow())
+	}
+	if groupBy != grouping.None {
+		title += fmt.Sprintf(" - grouped by %s", groupBy)
+	}
+	if searchErr != nil {
+		title += " - invalid search"
+	}
 
 	flex := tview.NewFlex().SetDirection(tview.FlexRow)
 	flex.SetTitle(title).SetTitleColor(primaryColor).SetBorder(true)
 	flex.AddItem(table, 0, 1, true)
+	if len(searchText) > 0 {
+		flex.AddItem(snippet, 1, 0, false)
+	}
 	return flex
 }
 
+// searchIssues filters issues by searchQuery, or in fuzzy mode ranks them
+// by how well they match
+func searchIssues(issues []types.Issue) []fuzzy.Result {
+	searchErr, searchText = nil, nil
+	if fuzzySearch {
+		return cache.Index().Search(searchQuery)
+	}
+
+	// An invalid query is shown in the search bar rather than filtering
+	parsed, err := query.Parse(searchQuery)
+	searchErr = err
+	if err == nil {
+		// Words and phrases also search bodies and comments
+		searchText = parsed.Text()
+		if len(searchText) > 0 {
+			parsed = parsed.WithDocuments(cache.Docs())
+		}
+		issues = parsed.Filter(issues, time.Now())
+	}
+
+	results := make([]fuzzy.Result, len(issues))
+	for i, issue := range issues {
+		results[i] = fuzzy.Result{Issue: issue}
+	}
+	return results
+}
+
 func searchView() tview.Primitive {
+	placeholder := "Search, e.g. org:acme label:bug -label:wontfix is:unread created:<7d"
+	if fuzzySearch {
+		placeholder = "Fuzzy search titles, repos, orgs, labels and numbers, e.g. acmlgn"
+	}
+
 	searchField := tview.NewInputField().
-		SetPlaceholder("Search").
+		SetPlaceholder(placeholder).
 		SetPlaceholderTextColor(tcell.ColorWhite).
 		SetPlaceholderStyle(tcell.StyleDefault.Background(tcell.Color238)).
 		SetFieldBackgroundColor(tcell.Color238).
@@ -405,9 +715,93 @@ func searchView() tview.Primitive {
 	// Create a flex with horizontal padding
 	flex := tview.NewFlex().
 		AddItem(nil, 1, 0, false). // Left padding
-		AddItem(searchField, 0, 1, true).
-		AddItem(nil, 1, 0, false) // Right padding
+		AddItem(searchField, 0, 1, true)
+
+	// Show query errors inline, e.g. an unsupported is: value
+	if searchErr != nil {
+		message := tview.NewTextView().
+			SetText(searchErr.Error()).
+			SetTextColor(errorColor).
+			SetTextAlign(tview.AlignRight)
+		flex.AddItem(message, 0, 1, false)
+	}
 
+	flex.AddItem(nil, 1, 0, false) // Right padding
+	return flex
+}
+
+// tuiErrors records failures in the TUI itself, e.g. opening the browser,
+// which an attached daemon's status doesn't know about
+var tuiErrors = status.NewTracker()
+
+// withTUIErrors merges the TUI's own errors into state, newest first
+func withTUIErrors(state status.Snapshot) status.Snapshot {
+	local := tuiErrors.Get()
+	if len(local.Errors) == 0 {
+		return state
+	}
+
+	state.Errors = append(slices.Clone(local.Errors), state.Errors...)
+	slices.SortStableFunc(state.Errors, func(a, b status.Error) int {
+		return b.At.Compare(a.At)
+	})
+	if local.LastErrorAt.After(state.LastErrorAt) {
+		state.LastError, state.LastErrorAt = local.LastError, local.LastErrorAt
+	}
+	return state
+}
+
+func statusView(state status.Snapshot, err error) tview.Primitive {
+	text := "Not synced yet"
+	switch {
+	case err != nil:
+		text = fmt.Sprintf("[red]Daemon unavailable: %s[-]", tview.Escape(err.Error()))
+	case state.Syncing && state.Current != "":
+		text = fmt.Sprintf("Syncing %s (%d/%d)", state.Current, state.Done, state.Total)
+	case state.Syncing:
+		text = "Syncing..."
+	case !state.LastSync.IsZero():
+		text = "Last sync " + utils.RelativeTime(state.LastSync)
+	}
+
+	// Warn when data may be out of date, e.g. after a token expires
+	if stale := staleOrgs(state); len(stale) > 0 {
+		text += fmt.Sprintf("    [yellow]Stale: %s[-]", tview.Escape(strings.Join(stale, ", ")))
+	}
+
+	if state.LastError != "" {
+		text += fmt.Sprintf("    [red]Error %s: %s[-]", utils.RelativeTime(state.LastErrorAt), tview.Escape(state.LastError))
+		if count := len(state.Errors); count > 1 {
+			text += fmt.Sprintf(" [red](+%d more, E to view)[-]", count-1)
+		}
+	}
+
+	return tview.NewTextView().
+		SetDynamicColors(true).
+		SetText(text).
+		SetTextAlign(tview.AlignCenter).
+		SetTextColor(grayColor)
+}
+
+func errorsView(state status.Snapshot) tview.Primitive {
+	table := tview.NewTable()
+	for row, entry := range state.Errors {
+		table.SetCell(row, 0, tview.NewTableCell(entry.At.Local().Format("15:04:05")).
+			SetTextColor(grayColor))
+		table.SetCell(row, 1, tview.NewTableCell(tview.Escape(cmp.Or(entry.Org, "-"))).
+			SetTextColor(errorColor))
+		table.SetCell(row, 2, tview.NewTableCell(tview.Escape(entry.Message)).
+			SetExpansion(1))
+	}
+	if len(state.Errors) == 0 {
+		table.SetCell(0, 0, tview.NewTableCell("No errors").SetTextColor(grayColor))
+	}
+
+	title := fmt.Sprintf("Errors (%d)", len(state.Errors))
+
+	flex := tview.NewFlex().SetDirection(tview.FlexRow)
+	flex.SetTitle(title).SetTitleColor(errorColor).SetBorder(true)
+	flex.AddItem(table, 0, 1, false)
 	return flex
 }
 
@@ -415,16 +809,74 @@ func shortcutsView() tview.Primitive {
 	shortcuts := []string{
 		"↑↓ - Navigate",
 		"Enter - Open",
+		"M - Mark Read",
+		"T - Toggle Read",
+		"Shift-M - Mark All Read",
 		"/ - Search",
-		"Tab - Next Org",
+		"L - Labels",
+		"O - Sort",
+		"G - Group",
+		"Tab - Panel",
+		"R - Refresh",
+		"E - Errors",
 		"Q - Quit",
 	}
 
+	if searchQuery != "" && !fuzzySearch {
+		shortcuts = slices.Insert(shortcuts, slices.Index(shortcuts, "/ - Search")+1, "S - Save Search")
+	}
+
+	if groupBy != grouping.None {
+		shortcuts = slices.Insert(shortcuts, 2, "←→ - Collapse/Expand")
+	}
+
+	if len(readUndo) > 0 {
+		shortcuts = slices.Insert(shortcuts, 2, "U - Undo")
+	}
+
+	if panelFocused {
+		shortcuts = []string{
+			"↑↓ - Navigate",
+			"Enter - Filter",
+			"Space - Add to Filter",
+			"←→ - Collapse/Expand",
+			"D - Delete Search",
+			"Esc - Clear Filter",
+			"Tab - Issues",
+			"Q - Quit",
+		}
+	}
+
+	if showErrors {
+		shortcuts = []string{
+			"X - Dismiss",
+			"Esc - Close",
+			"Q - Quit",
+		}
+	}
+
+	if showLabels {
+		shortcuts = []string{
+			"↑↓ - Navigate",
+			"Space - Include/Exclude",
+			"C - Clear",
+			"Esc - Close",
+			"Q - Quit",
+		}
+	}
+
+	if showSaveSearch {
+		shortcuts = []string{
+			"Enter - Save",
+			"Esc - Cancel",
+		}
+	}
+
 	if showSearch {
 		shortcuts = []string{
 			"Enter - Search",
+			"Ctrl-F - Fuzzy",
 			"Esc - Cancel",
-			"Q - Quit",
 		}
 	}
 
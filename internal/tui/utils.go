@@ -1,36 +1,34 @@
 package tui
 
 import (
-	"sort"
+	"slices"
+	"strings"
 
-	"github.com/shaunmolloy/bugbox/internal/types"
+	"github.com/rivo/tview"
 )
 
-func indexOf(list []string, value string) int {
-	for i, v := range list {
-		if v == value {
-			return i
-		}
-	}
-	return -1 // Not found
-}
-
-func fallback(primary string, alt string) string {
-	if primary != "" {
-		return primary
+// highlight truncates text to width runes, if set, escaping it for a table
+// cell and emphasising the runes at positions
+func highlight(text string, positions []int, width int) string {
+	runes := []rune(text)
+	if width > 0 && len(runes) > width {
+		runes = runes[:width]
 	}
-	return alt
-}
 
-// sortIssues sorts by created, org, and repo
-func sortIssues(issues []types.Issue) {
-	sort.Slice(issues, func(i, j int) bool {
-		if !issues[i].CreatedAt.Equal(issues[j].CreatedAt) {
-			return issues[i].CreatedAt.After(issues[j].CreatedAt)
+	var b strings.Builder
+	start := 0
+	for i := 0; i <= len(runes); i++ {
+		matched := i < len(runes) && slices.Contains(positions, i)
+		if i < len(runes) && matched == slices.Contains(positions, start) {
+			continue
 		}
-		if issues[i].Org != issues[j].Org {
-			return issues[i].Org < issues[j].Org
+		// Write the run of matched or unmatched runes ending here
+		segment := tview.Escape(string(runes[start:i]))
+		if slices.Contains(positions, start) {
+			segment = "[yellow::b]" + segment + "[-::-]"
 		}
-		return issues[i].Repo < issues[j].Repo
-	})
+		b.WriteString(segment)
+		start = i
+	}
+	return b.String()
 }
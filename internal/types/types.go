@@ -3,17 +3,42 @@ package types
 import "time"
 
 type Issue struct {
-	ID        int       `json:"number"`
-	Org       string    `json:"org"`
-	Repo      string    `json:"repo"`
-	Title     string    `json:"title"`
-	URL       string    `json:"html_url"`
-	Labels    []Label   `json:"labels"`
-	Read      bool      `json:"read"`
-	State     State     `json:"state"`
-	CreatedAt time.Time `json:"created_at"`
+	ID        int        `json:"number"`
+	Org       string     `json:"org"`
+	Repo      string     `json:"repo"`
+	Title     string     `json:"title"`
+	URL       string     `json:"html_url"`
+	Labels    []Label    `json:"labels"`
+	Author    User       `json:"user"`
+	Assignees []User     `json:"assignees,omitempty"`
+	Milestone *Milestone `json:"milestone,omitempty"`
+	Read      bool       `json:"read"`
+	State     State      `json:"state"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	// Comments is how many comments the issue has
+	Comments int `json:"comments"`
+	// Reactions counts emoji reactions to the issue
+	Reactions Reactions `json:"reactions"`
+	// Body is passed on to the search index as issues are fetched, but not
+	// kept in the issue store
+	Body string `json:"body,omitempty"`
+}
+
+type User struct {
+	Login string `json:"login"`
+}
+
+type Milestone struct {
+	Title string `json:"title"`
+}
+
+type Reactions struct {
+	TotalCount int `json:"total_count"`
 }
 
 type Label struct {
-	Name string `json:"name"`
+	Name        string `json:"name"`
+	Color       string `json:"color,omitempty"` // hex without a leading #
+	Description string `json:"description,omitempty"`
 }
@@ -0,0 +1,34 @@
+package utils
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ParseHexColor parses a colour like GitHub label colours, e.g. "d73a4a" or "#d73a4a"
+func ParseHexColor(hex string) (r int, g int, b int, ok bool) {
+	hex = strings.TrimPrefix(hex, "#")
+	if len(hex) != 6 {
+		return 0, 0, 0, false
+	}
+
+	value, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return 0, 0, 0, false
+	}
+	return int(value >> 16 & 0xff), int(value >> 8 & 0xff), int(value & 0xff), true
+}
+
+// ContrastColor returns black or white, whichever is easier to read on hex
+func ContrastColor(hex string) string {
+	r, g, b, ok := ParseHexColor(hex)
+	if !ok {
+		return "#ffffff"
+	}
+
+	// Perceived brightness, weighting green as eyes are most sensitive to it
+	if r*299+g*587+b*114 > 128*1000 {
+		return "#000000"
+	}
+	return "#ffffff"
+}
@@ -0,0 +1,41 @@
+package utils
+
+import "testing"
+
+func TestParseHexColor(t *testing.T) {
+	t.Run("returns components with or without a hash", func(t *testing.T) {
+		for _, input := range []string{"d73a4a", "#d73a4a"} {
+			r, g, b, ok := ParseHexColor(input)
+			if !ok || r != 0xd7 || g != 0x3a || b != 0x4a {
+				t.Errorf("got %d, %d, %d, %t for %q", r, g, b, ok, input)
+			}
+		}
+	})
+
+	t.Run("returns false for invalid colours", func(t *testing.T) {
+		for _, input := range []string{"", "fff", "zzzzzz", "#1234567"} {
+			if _, _, _, ok := ParseHexColor(input); ok {
+				t.Errorf("expected false for %q", input)
+			}
+		}
+	})
+}
+
+func TestContrastColor(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"ffffff", "#000000"},
+		{"fbca04", "#000000"},
+		{"d73a4a", "#ffffff"},
+		{"000000", "#ffffff"},
+		{"invalid", "#ffffff"},
+	}
+
+	for _, test := range tests {
+		if result := ContrastColor(test.input); result != test.expected {
+			t.Errorf("expected %q for %q, got %q", test.expected, test.input, result)
+		}
+	}
+}
@@ -27,6 +27,55 @@ func TestRelativeTime(t *testing.T) {
 	}
 }
 
+func TestShortRelativeTime(t *testing.T) {
+	tests := []struct {
+		input    int
+		expected string
+	}{
+		{0, "now"},
+		{10, "10m"},
+		{60, "1h"},
+		{1440, "1d"},
+		{43200, "30d"},
+	}
+
+	for _, test := range tests {
+		now := time.Now().Add(-time.Duration(test.input) * time.Minute)
+		result := ShortRelativeTime(now)
+		if result != test.expected {
+			t.Errorf("expected %q, got %q", test.expected, result)
+		}
+	}
+}
+
+func TestParseAge(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected time.Duration
+	}{
+		{"7d", 7 * 24 * time.Hour},
+		{"2w", 14 * 24 * time.Hour},
+		{"12h", 12 * time.Hour},
+		{"90m", 90 * time.Minute},
+	}
+
+	for _, test := range tests {
+		result, err := ParseAge(test.input)
+		if err != nil {
+			t.Fatalf("expected nil, got error: %v", err)
+		}
+		if result != test.expected {
+			t.Errorf("expected %v, got %v", test.expected, result)
+		}
+	}
+
+	for _, input := range []string{"", "d", "-1d", "soon", "-5h"} {
+		if _, err := ParseAge(input); err == nil {
+			t.Errorf("expected error for %q, got nil", input)
+		}
+	}
+}
+
 func TestPlural(t *testing.T) {
 	tests := []struct {
 		input    int